@@ -0,0 +1,223 @@
+package Compiler
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// TriviaKind Kind of a piece of the text between tokens.
+type TriviaKind int
+
+const (
+	TriviaWhitespace   TriviaKind = iota // spaces and tabs
+	TriviaNewline                        // "\n" or "\r\n"
+	TriviaBlockComment                   // from /# to #/
+	TriviaLineComment                    // from % to the end of the line, the newline excluded
+	TriviaSkipped                        // characters the lexer does not recognize
+)
+
+var triviaKinds = [...]string{"whitespace", "newline", "block-comment", "line-comment", "skipped"}
+
+func (k TriviaKind) String() string {
+	return triviaKinds[k]
+}
+
+// Trivia Text of the source the grammar ignores, kept so that the source can be reproduced.
+type Trivia struct {
+	Kind TriviaKind
+	Text string
+}
+
+// SyntaxElement Either a *SyntaxNode or a *SyntaxToken.
+type SyntaxElement interface {
+	// Text Returns the source text the element spans, trivia included.
+	Text() string
+	writeText(b *strings.Builder)
+}
+
+// SyntaxToken A token of the concrete syntax tree with the trivia around it. The trailing trivia
+// runs up to the end of the line of the token, the newline included, the rest leads the next token.
+type SyntaxToken struct {
+	Token
+	Leading  []Trivia
+	Trailing []Trivia
+}
+
+// SyntaxNode A node of the concrete syntax tree: the application of a grammar rule, named as in
+// GramaticaUnica.txt, with the tokens and nodes it spans in source order. Rules that span no
+// tokens leave no node.
+type SyntaxNode struct {
+	Rule     string
+	Children []SyntaxElement
+}
+
+func (t *SyntaxToken) Text() string {
+	var b strings.Builder
+	t.writeText(&b)
+	return b.String()
+}
+
+func (t *SyntaxToken) writeText(b *strings.Builder) {
+	for _, tr := range t.Leading {
+		b.WriteString(tr.Text)
+	}
+	b.WriteString(t.Value)
+	for _, tr := range t.Trailing {
+		b.WriteString(tr.Text)
+	}
+}
+
+// Text Returns the source text the node spans. For the tree returned by Parser.Tree it is the
+// whole source, byte for byte.
+func (n *SyntaxNode) Text() string {
+	var b strings.Builder
+	n.writeText(&b)
+	return b.String()
+}
+
+func (n *SyntaxNode) writeText(b *strings.Builder) {
+	for _, c := range n.Children {
+		c.writeText(b)
+	}
+}
+
+// Tokens Returns the tokens of the node in source order.
+func (n *SyntaxNode) Tokens() []*SyntaxToken {
+	var list []*SyntaxToken
+	var walk func(n *SyntaxNode)
+	walk = func(n *SyntaxNode) {
+		for _, c := range n.Children {
+			switch c := c.(type) {
+			case *SyntaxToken:
+				list = append(list, c)
+			case *SyntaxNode:
+				walk(c)
+			}
+		}
+	}
+	walk(n)
+	return list
+}
+
+// node Opens a node for a grammar rule, taking the tokens consumed until the returned function
+// closes it. Rule functions call it as "defer p.node(rule)()".
+func (p *Parser) node(rule string) func() {
+	n := &SyntaxNode{Rule: rule}
+	p.nodes = append(p.nodes, n)
+	return func() {
+		p.nodes = p.nodes[:len(p.nodes)-1]
+		if len(n.Children) > 0 {
+			parent := p.nodes[len(p.nodes)-1]
+			parent.Children = append(parent.Children, n)
+		}
+	}
+}
+
+// syntaxTokens Attaches the trivia of the source to the tokens the parser sees, which are all but
+// the comments. Every byte of src ends up in exactly one token or trivia.
+func syntaxTokens(src string, all []token) []*SyntaxToken {
+	var list []*SyntaxToken
+	var pending []Trivia // after the last token
+	end := 0             // of the last token or comment
+	for _, t := range all {
+		if t.offset > end {
+			pending = append(pending, gapTrivia(src[end:t.offset])...)
+		}
+		end = t.offset + len(t.val)
+		switch t.typ {
+		case tokenBlockComment:
+			pending = append(pending, Trivia{TriviaBlockComment, t.val})
+			continue
+		case tokenLineComment:
+			pending = append(pending, Trivia{TriviaLineComment, t.val})
+			continue
+		}
+		if n := len(list); n > 0 {
+			list[n-1].Trailing, pending = splitTrailing(pending)
+		}
+		list = append(list, &SyntaxToken{Token: exported(t), Leading: pending})
+		pending = nil
+	}
+	pending = append(pending, gapTrivia(src[end:])...)
+	if n := len(list); n > 0 && len(pending) > 0 {
+		list[n-1].Trailing = append(list[n-1].Trailing, pending...)
+	}
+	return list
+}
+
+// splitTrailing Splits the trivia after a token at the end of its line.
+func splitTrailing(list []Trivia) (trailing, leading []Trivia) {
+	for i, tr := range list {
+		if tr.Kind == TriviaNewline {
+			return list[:i+1], list[i+1:]
+		}
+	}
+	return list, nil
+}
+
+// gapTrivia Splits text the lexer skipped between two tokens into runs of whitespace, newlines and
+// unrecognized characters.
+func gapTrivia(text string) []Trivia {
+	var list []Trivia
+	for len(text) > 0 {
+		kind, n := TriviaSkipped, 0
+		switch {
+		case strings.HasPrefix(text, "\r\n"):
+			kind, n = TriviaNewline, 2
+		case text[0] == '\n':
+			kind, n = TriviaNewline, 1
+		case isBlank(text[0]):
+			kind = TriviaWhitespace
+			for n < len(text) && isBlank(text[n]) && !strings.HasPrefix(text[n:], "\r\n") {
+				n++
+			}
+		default:
+			for n < len(text) && text[n] != '\n' && !isBlank(text[n]) && !strings.HasPrefix(text[n:], "\r\n") {
+				n++
+			}
+		}
+		list = append(list, Trivia{kind, text[:n]})
+		text = text[n:]
+	}
+	return list
+}
+
+func isBlank(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\v' || c == '\f'
+}
+
+// WriteSyntaxTree Writes the tree one node or token per line, indented by depth, with the trivia of
+// each token.
+func WriteSyntaxTree(w io.Writer, n *SyntaxNode) error {
+	b := bufio.NewWriter(w)
+	var write func(n *SyntaxNode, depth int)
+	write = func(n *SyntaxNode, depth int) {
+		indent := strings.Repeat("  ", depth)
+		b.WriteString(indent + n.Rule + "\n")
+		for _, c := range n.Children {
+			switch c := c.(type) {
+			case *SyntaxNode:
+				write(c, depth+1)
+			case *SyntaxToken:
+				fmt.Fprintf(b, "%s  %s %q", indent, c.Type, c.Value)
+				writeTrivia(b, "leading", c.Leading)
+				writeTrivia(b, "trailing", c.Trailing)
+				b.WriteByte('\n')
+			}
+		}
+	}
+	write(n, 0)
+	return b.Flush()
+}
+
+func writeTrivia(b *bufio.Writer, name string, list []Trivia) {
+	if len(list) == 0 {
+		return
+	}
+	fmt.Fprintf(b, " %s:", name)
+	for _, tr := range list {
+		fmt.Fprintf(b, " %s %q", tr.Kind, tr.Text)
+	}
+}
@@ -0,0 +1,91 @@
+package Compiler
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// checkRoundTrip Checks that the tree the parser builds holds every byte of src.
+func checkRoundTrip(t *testing.T, name, src string) {
+	t.Helper()
+	parser := Syntax(Lex(name, src))
+	parser.Parse()
+	if text := parser.Tree().Text(); text != src {
+		t.Errorf("%s: the tree reads %q, want %q", name, text, src)
+	}
+}
+
+// samples The programs of files/, as written.
+func samples(t *testing.T) map[string]string {
+	t.Helper()
+	files, err := filepath.Glob("../files/*.txt")
+	if err != nil || len(files) == 0 {
+		t.Fatalf("no sample programs: %v", err)
+	}
+	list := make(map[string]string)
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list[filepath.Base(file)] = string(src)
+	}
+	return list
+}
+
+func TestTreeText(t *testing.T) {
+	for name, src := range samples(t) {
+		checkRoundTrip(t, name, src)
+		checkRoundTrip(t, name+" with CRLF", strings.ReplaceAll(src, "\n", "\r\n"))
+	}
+}
+
+// TestTreeTextPrefixes Checks the programs as typed so far, most of them broken somewhere.
+func TestTreeTextPrefixes(t *testing.T) {
+	for name, src := range samples(t) {
+		for i := range src {
+			checkRoundTrip(t, name, src[:i])
+		}
+	}
+}
+
+func TestTreeTextBroken(t *testing.T) {
+	tests := []string{
+		"",
+		"   \n\t",
+		"% only a comment",
+		"/# an unterminated block",
+		"program",
+		"program P; var { integer x } main { x = ; }",
+		"program P;\r\nvar { }\r\nconst { }\r\nmain {\r\n\tvar { }\r\n\tx = 1 +;\r\n}\r\n",
+		"program P; @ $ ? ~ main { print(\"unterminated); }",
+		"program P; var { } const { } main { var { } print('ab', '\\q', \"\\u00e9\"); } trailing tokens ;;",
+		"}}}{{{ ;;; program program",
+		"program P; /# a #/ /# b #/ % c\n% d\r\nvar /#x#/ { /#y#/ } /#z#/ const { } main { var { } } % end",
+		"\ufeffprogram P; var { } const { } main { var { } }",
+	}
+	for _, src := range tests {
+		checkRoundTrip(t, "test", src)
+	}
+}
+
+func TestWriteSyntaxTree(t *testing.T) {
+	parser := Syntax(Lex("test", "program P; % name\n"))
+	parser.Parse()
+	var b bytes.Buffer
+	if err := WriteSyntaxTree(&b, parser.Tree()); err != nil {
+		t.Fatal(err)
+	}
+	want := `Start
+  tokenProgramKeyword "program" trailing: whitespace " "
+  tokenIdentifier "P"
+  tokenSemicolon ";" trailing: whitespace " " line-comment "% name" newline "\n"
+  tokenEOF ""
+`
+	if b.String() != want {
+		t.Errorf("got the tree\n%s\nwant\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,266 @@
+package Compiler
+
+import (
+	"bytes"
+	"compiladores/Compiler/ast"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+)
+
+// Severity How serious the problem reported by a diagnostic is.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityInfo
+)
+
+var severities = [...]string{"error", "warning", "info"}
+
+func (s Severity) String() string {
+	return severities[s]
+}
+
+// MarshalText Encodes the severity by its name, as in JSON output.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Codes Identify the kind of problem a diagnostic reports, independently of its message.
+const (
+	// Lexical
+	CodeMalformedNumber       = "malformed-number"
+	CodeMalformedComment      = "malformed-comment"
+	CodeMalformedString       = "malformed-string"
+	CodeMalformedChar         = "malformed-char"
+	CodeInvalidEscape         = "invalid-escape"
+	CodeInvalidChar           = "invalid-character"
+	CodeNumberRange           = "number-out-of-range"
+	CodeInexactReal           = "inexact-real"
+	CodeMalformedLogicalOp    = "malformed-logical-operator"
+	CodeMalformedArithmeticOp = "malformed-arithmetic-operator"
+	CodeMalformedRelationalOp = "malformed-relational-operator"
+
+	// Syntax
+	CodeUnexpectedToken = "unexpected-token"
+
+	// Semantic
+	CodeRedeclaration    = "redeclaration"
+	CodeUndeclared       = "undeclared"
+	CodeUsedBeforeDecl   = "used-before-declaration"
+	CodeInvalidType      = "invalid-type"
+	CodeNotConstant      = "not-constant"
+	CodeNotValue         = "not-value"
+	CodeUnknownField     = "unknown-field"
+	CodeInvalidAssign    = "invalid-assignment"
+	CodeWrongSymbolKind  = "wrong-symbol-kind"
+	CodeTypeMismatch     = "type-mismatch"
+	CodeInvalidOperation = "invalid-operation"
+	CodeInvalidCondition = "invalid-condition"
+	CodeArgumentCount    = "argument-count"
+)
+
+// Rule Describes a kind of problem, for tools that list the codes apart from the diagnostics.
+type Rule struct {
+	Code        string
+	Severity    Severity // of the diagnostics of the kind
+	Description string   // what the problem is, in a few words
+	Help        string   // how to fix it
+}
+
+// Rules Every kind of problem, in the order of the phases reporting them.
+var Rules = []Rule{
+	{CodeMalformedNumber, SeverityError, "Malformed number",
+		"A number is a sequence of digits, optionally followed by a point and more digits and by an exponent, as in 12, 1.5 or 2.5e-3."},
+	{CodeNumberRange, SeverityError, "Number out of range",
+		"Integers range from 0 to 9223372036854775807, as a minus before a number is an operator, and reals up to about 1.8e308."},
+	{CodeInexactReal, SeverityWarning, "Inexact real",
+		"The real has more significant digits than a real holds, about 16; write the rounded value instead."},
+	{CodeMalformedComment, SeverityError, "Unterminated comment",
+		"Close the comment opened by /# with #/."},
+	{CodeMalformedString, SeverityError, "Unterminated string",
+		"Close the string with a double quote on the same line."},
+	{CodeMalformedChar, SeverityError, "Malformed character",
+		"A character literal holds a single character or escape sequence between single quotes, as in 'a' or '\\n'."},
+	{CodeInvalidEscape, SeverityError, "Invalid escape sequence",
+		"The escape sequences are \\n, \\t, \\\\, \\', \\\" and \\u or \\U followed by the 4 or 8 hexadecimal digits of a code point."},
+	{CodeInvalidChar, SeverityError, "Invalid character",
+		"The character starts no token of the language; outside strings, characters and comments only letters, digits, operators and delimiters may appear."},
+	{CodeMalformedLogicalOp, SeverityError, "Malformed logical operator",
+		"The logical operators are &&, || and !."},
+	{CodeMalformedArithmeticOp, SeverityError, "Malformed arithmetic operator",
+		"The arithmetic operators are +, -, *, /, ++ and --."},
+	{CodeMalformedRelationalOp, SeverityError, "Malformed relational operator",
+		"The relational operators are ==, !=, <, <=, > and >=."},
+	{CodeUnexpectedToken, SeverityError, "Unexpected token",
+		"The program does not follow the grammar of the language here; the message tells what was expected."},
+	{CodeRedeclaration, SeverityError, "Redeclaration",
+		"Every name must be declared once in its block; rename one of the declarations."},
+	{CodeUndeclared, SeverityError, "Undeclared name",
+		"Declare the name in the var or const section, or fix its spelling."},
+	{CodeUsedBeforeDecl, SeverityError, "Use before declaration",
+		"Move the declaration before its first use."},
+	{CodeInvalidType, SeverityError, "Invalid type",
+		"Use integer, real, boolean, char, string or the name of a register declared in the program."},
+	{CodeNotConstant, SeverityError, "Not a constant",
+		"The values of constants must be literals or other constants."},
+	{CodeNotValue, SeverityError, "Not a value",
+		"Procedures, functions and types cannot be used as values, and only register variables have fields."},
+	{CodeUnknownField, SeverityError, "Unknown field",
+		"Use one of the fields declared in the register."},
+	{CodeInvalidAssign, SeverityError, "Invalid assignment",
+		"Only variables, parameters and register fields can be assigned to."},
+	{CodeWrongSymbolKind, SeverityError, "Wrong kind of name",
+		"Call procedures as commands and functions inside expressions."},
+	{CodeTypeMismatch, SeverityError, "Type mismatch",
+		"Both sides of an operation, assignments and arguments must have compatible types: the same type, or an integer where a real is expected."},
+	{CodeInvalidOperation, SeverityError, "Invalid operation",
+		"The operator is not defined on the type of its operand."},
+	{CodeInvalidCondition, SeverityError, "Invalid condition",
+		"The conditions of if and while must be boolean expressions."},
+	{CodeArgumentCount, SeverityError, "Wrong number of arguments",
+		"Pass one argument per parameter of the procedure or function."},
+}
+
+// Span Range of bytes of the source, from Start up to but not including End.
+type Span struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// Diagnostic A problem found in a source by any phase of the analysis.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"` // kind of problem, one of the Code constants
+	Message  string   `json:"message"`
+	File     string   `json:"file,omitempty"` // name of the source, may be empty
+	Line     int      `json:"line"`           // starting at 1
+	Column   int      `json:"column"`         // in characters, starting at 1
+	Span     Span     `json:"span"`           // bytes of the source the problem refers to
+}
+
+func (d Diagnostic) Error() string {
+	position := fmt.Sprintf("%d:%d", d.Line, d.Column)
+	if d.File != "" {
+		position = d.File + ":" + position
+	}
+	return fmt.Sprintf("%s: %s[%s]: %s", position, d.Severity, d.Code, d.Message)
+}
+
+// Render Writes the diagnostic followed by the line of src it refers to, with the span underlined by carets.
+// src must be the source the diagnostic was produced from.
+func (d Diagnostic) Render(w io.Writer, src string) error {
+	var b bytes.Buffer
+	b.WriteString(d.Error())
+	b.WriteByte('\n')
+
+	if d.Line > 0 && d.Span.Start <= len(src) {
+		start := strings.LastIndexByte(src[:d.Span.Start], '\n') + 1
+		end := strings.IndexByte(src[start:], '\n')
+		if end < 0 {
+			end = len(src)
+		} else {
+			end += start
+		}
+		if end > start && src[end-1] == '\r' {
+			end--
+		}
+		from, to := d.Span.Start, d.Span.End
+		if from > end {
+			from = end
+		}
+		if to > end {
+			to = end
+		}
+		// Tabs are kept so that the carets line up with the text above them.
+		var margin strings.Builder
+		for _, r := range src[start:from] {
+			if r == '\t' {
+				margin.WriteRune('\t')
+			} else {
+				margin.WriteRune(' ')
+			}
+		}
+		width := 1
+		if to > from {
+			width = utf8.RuneCountInString(src[from:to])
+		}
+
+		gutter := fmt.Sprint(d.Line)
+		fmt.Fprintf(&b, " %s | %s\n", gutter, src[start:end])
+		fmt.Fprintf(&b, " %s | %s%s\n", strings.Repeat(" ", len(gutter)), margin.String(), strings.Repeat("^", width))
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// errorAt A diagnostic of severity error covering the given span.
+func errorAt(file string, pos ast.Pos, end int, code, format string, args ...interface{}) Diagnostic {
+	return Diagnostic{
+		Severity: SeverityError,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		File:     file,
+		Line:     pos.Line,
+		Column:   pos.Column,
+		Span:     Span{pos.Offset, end},
+	}
+}
+
+// warningAt A diagnostic of severity warning covering the given span.
+func warningAt(file string, pos ast.Pos, end int, code, format string, args ...interface{}) Diagnostic {
+	d := errorAt(file, pos, end, code, format, args...)
+	d.Severity = SeverityWarning
+	return d
+}
+
+// HasErrors Reports whether any of the diagnostics is an error, rather than a warning or a note.
+func HasErrors(list []Diagnostic) bool {
+	for _, d := range list {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// nodeEnd Offset of the first byte after a node, as far as the tree records it.
+func nodeEnd(n ast.Node) int {
+	switch n := n.(type) {
+	case *ast.Ident:
+		return n.NamePos.Offset + len(n.Name)
+	case *ast.TypeRef:
+		return n.NamePos.Offset + len(n.Name)
+	case *ast.BasicLit:
+		return n.ValuePos.Offset + len(n.Value)
+	case *ast.FieldExpr:
+		return nodeEnd(n.Field)
+	case *ast.CallExpr:
+		if n.Rparen.IsValid() {
+			return n.Rparen.Offset + 1
+		}
+		return nodeEnd(n.Fun)
+	case *ast.BinaryExpr:
+		if n.Y != nil {
+			return nodeEnd(n.Y)
+		}
+		return n.OpPos.Offset + len(n.Op)
+	case *ast.UnaryExpr:
+		if n.X != nil {
+			return nodeEnd(n.X)
+		}
+		return n.OpPos.Offset + len(n.Op)
+	case *ast.IncDecExpr:
+		return n.OpPos.Offset + len(n.Op)
+	case *ast.IncDecStmt:
+		return n.TokPos.Offset + len(n.Op)
+	case *ast.IfStmt:
+		return n.If.Offset + len(ifKeyword)
+	case *ast.WhileStmt:
+		return n.While.Offset + len(whileKeyword)
+	}
+	return n.Pos().Offset + 1
+}
@@ -0,0 +1,53 @@
+package Compiler
+
+// TokenClass How editors highlight a token. The classes are shared by the semantic tokens of the
+// language server and the grammars generated for editors, so that both follow the lexer.
+type TokenClass int
+
+const (
+	ClassNone     TokenClass = iota // identifiers, delimiters and malformed tokens
+	ClassKeyword                    // keywords other than types and booleans
+	ClassType                       // primitive type names
+	ClassBoolean                    // true and false
+	ClassNumber                     // integer and real numbers
+	ClassString                     // string literals
+	ClassChar                       // character literals
+	ClassComment                    // line and block comments
+	ClassOperator                   // arithmetic, relational and logical operators
+)
+
+var tokenClasses = [...]string{"none", "keyword", "type", "boolean", "number", "string", "char", "comment", "operator"}
+
+func (c TokenClass) String() string {
+	return tokenClasses[c]
+}
+
+// KeywordClass Class of one of the Keywords.
+func KeywordClass(word string) TokenClass {
+	switch {
+	case isPrimitiveType(word):
+		return ClassType
+	case word == trueKeyword || word == falseKeyword:
+		return ClassBoolean
+	}
+	return ClassKeyword
+}
+
+// Class Returns the highlighting class of the token.
+func (t Token) Class() TokenClass {
+	switch typ := tokenTypes[t.Type]; {
+	case typ.isKeyword():
+		return KeywordClass(t.Value)
+	case typ.isOperator(): // the assignment too
+		return ClassOperator
+	case typ == tokenNumber:
+		return ClassNumber
+	case typ == tokenString:
+		return ClassString
+	case typ == tokenChar:
+		return ClassChar
+	case typ == tokenBlockComment, typ == tokenLineComment:
+		return ClassComment
+	}
+	return ClassNone
+}
@@ -1,8 +1,12 @@
 package Compiler
 
 import (
+	"bufio"
+	"compiladores/Compiler/ast"
+	"errors"
 	"fmt"
-	"os"
+	"io"
+	"sort"
 	"strconv"
 	"strings"
 	"unicode"
@@ -11,36 +15,78 @@ import (
 
 const (
 	tokenError      tokenType = iota // whether an error has ocurred.
-	tokenKeyword                     // No.1
-	tokenLetter                      // letter
 	tokenIdentifier                  // identifier
-	tokenDigit                       // single digit
 	tokenNumber                      // number
-	tokenArithmeticOp
 	tokenBlockComment
 	tokenEOF
-	tokenRelationalOp
-	tokenLogicalOp
-	tokenDelimiter
 	tokenChar
 	tokenMalformedChar
-
-	tokenIf              // if keyword
-	tokenLeftMeta        // left meta-string
-	tokenPipe            // pipe symbol
-	tokenRange           // range keyword
-	tokenRawString       // raw quoted string (includes quotes)
-	tokenRightMeta       // right meta-string
 	tokenString          // quoted string (includes quotes)
-	tokenText            // plain text
 	tokenMalformedNumber // Error when lexing number
 	tokenMalformedComment
 	tokenMalformedString
 	tokenMalformedLogicalOp
 	tokenMalformedArithmeticOp
 	tokenMalformedRelationalOp
+	tokenLineComment
+	tokenInvalidChar // a character that starts no token
 
 	// Keywords
+	tokenProgramKeyword
+	tokenVarKeyword
+	tokenConstKeyword
+	tokenRegisterKeyword
+	tokenFunctionKeyword
+	tokenProcedureKeyword
+	tokenReturnKeyword
+	tokenMainKeyword
+	tokenIfKeyword
+	tokenElseKeyword
+	tokenWhileKeyword
+	tokenReadKeyword
+	tokenWriteKeyword
+	tokenPrintKeyword
+	tokenIntegerKeyword
+	tokenRealKeyword
+	tokenBooleanKeyword
+	tokenCharKeyword
+	tokenStringKeyword
+	tokenTrueKeyword
+	tokenFalseKeyword
+
+	// Operators
+	tokenAssign       // =
+	tokenEqual        // ==
+	tokenNotEqual     // !=
+	tokenLess         // <
+	tokenLessEqual    // <=
+	tokenGreater      // >
+	tokenGreaterEqual // >=
+	tokenPlus         // +
+	tokenMinus        // -
+	tokenStar         // *
+	tokenSlash        // /
+	tokenIncrement    // ++
+	tokenDecrement    // --
+	tokenAnd          // &&
+	tokenOr           // ||
+	tokenNot          // !
+
+	// Delimiters
+	tokenSemicolon    // ;
+	tokenComma        // ,
+	tokenLeftParen    // (
+	tokenRightParen   // )
+	tokenLeftBrace    // {
+	tokenRightBrace   // }
+	tokenLeftBracket  // [
+	tokenRightBracket // ]
+	tokenDot          // .
+	tokenColon        // :
+)
+
+// Spelling of the keywords.
+const (
 	programKeyword   = "program"
 	varKeyword       = "var"
 	constKeyword     = "const"
@@ -54,6 +100,7 @@ const (
 	whileKeyword     = "while"
 	readKeyword      = "read"
 	writeKeyword     = "write"
+	printKeyword     = "print"
 	integerKeyword   = "integer"
 	realKeyword      = "real"
 	booleanKeyword   = "boolean"
@@ -63,184 +110,421 @@ const (
 	falseKeyword     = "false"
 )
 
-var types = [...]string{"tokenError",
-	"tokenKeyword",
-	"tokenLetter",
-	"tokenIdentifier",
-	"tokenDigit",
-	"tokenNumber",
-	"tokenArithmeticOp",
-	"tokenBlockComment",
-	"tokenEOF",
-	"tokenRelationalOp",
-	"tokenLogicalOp",
-	"tokenDelimiter",
-	"tokenChar",
-	"tokenMalformedChar",
-
-	"tokenIf",
-	"tokenLeftMeta",
-	"tokenPipe",
-	"tokenRange",
-	"tokenRawString",
-	"tokenRightMeta",
-	"tokenString",
-	"tokenText",
-	"tokenMalformedNumber",
-	"tokenMalformedComment",
-	"tokenMalformedString",
-	"tokenMalformedLogicalOp",
-	"tokenMalformedArithmeticOp",
-
-	"programKeyword",
-	"varKeyword",
-	"constKeyword",
-	"registerKeyword",
-	"functionKeyword",
-	"procedureKeyword",
-	"returnKeyword",
-	"mainKeyword",
-	"ifKeyword",
-	"elseKeyword",
-	"whileKeyword",
-	"readKeyword",
-	"writeKeyword",
-	"integerKeyword",
-	"realKeyword",
-	"booleanKeyword",
-	"charKeyword",
-	"stringKeyword",
-	"trueKeyword",
-	"falseKeyword"}
+// tokenTable Name of each token type, as tools show it, and the text of the tokens of the types
+// spelled in a single way: the keywords, operators and delimiters. It is the only list of the token
+// types, the lexer recognizes keywords and the parser describes what it expects from it.
+var tokenTable = [...]struct {
+	name string
+	text string
+}{
+	tokenError:                 {"tokenError", ""},
+	tokenIdentifier:            {"tokenIdentifier", ""},
+	tokenNumber:                {"tokenNumber", ""},
+	tokenBlockComment:          {"tokenBlockComment", ""},
+	tokenEOF:                   {"tokenEOF", ""},
+	tokenChar:                  {"tokenChar", ""},
+	tokenMalformedChar:         {"tokenMalformedChar", ""},
+	tokenString:                {"tokenString", ""},
+	tokenMalformedNumber:       {"tokenMalformedNumber", ""},
+	tokenMalformedComment:      {"tokenMalformedComment", ""},
+	tokenMalformedString:       {"tokenMalformedString", ""},
+	tokenMalformedLogicalOp:    {"tokenMalformedLogicalOp", ""},
+	tokenMalformedArithmeticOp: {"tokenMalformedArithmeticOp", ""},
+	tokenMalformedRelationalOp: {"tokenMalformedRelationalOp", ""},
+	tokenLineComment:           {"tokenLineComment", ""},
+	tokenInvalidChar:           {"tokenInvalidChar", ""},
+
+	tokenProgramKeyword:   {"tokenProgramKeyword", programKeyword},
+	tokenVarKeyword:       {"tokenVarKeyword", varKeyword},
+	tokenConstKeyword:     {"tokenConstKeyword", constKeyword},
+	tokenRegisterKeyword:  {"tokenRegisterKeyword", registerKeyword},
+	tokenFunctionKeyword:  {"tokenFunctionKeyword", functionKeyword},
+	tokenProcedureKeyword: {"tokenProcedureKeyword", procedureKeyword},
+	tokenReturnKeyword:    {"tokenReturnKeyword", returnKeyword},
+	tokenMainKeyword:      {"tokenMainKeyword", mainKeyword},
+	tokenIfKeyword:        {"tokenIfKeyword", ifKeyword},
+	tokenElseKeyword:      {"tokenElseKeyword", elseKeyword},
+	tokenWhileKeyword:     {"tokenWhileKeyword", whileKeyword},
+	tokenReadKeyword:      {"tokenReadKeyword", readKeyword},
+	tokenWriteKeyword:     {"tokenWriteKeyword", writeKeyword},
+	tokenPrintKeyword:     {"tokenPrintKeyword", printKeyword},
+	tokenIntegerKeyword:   {"tokenIntegerKeyword", integerKeyword},
+	tokenRealKeyword:      {"tokenRealKeyword", realKeyword},
+	tokenBooleanKeyword:   {"tokenBooleanKeyword", booleanKeyword},
+	tokenCharKeyword:      {"tokenCharKeyword", charKeyword},
+	tokenStringKeyword:    {"tokenStringKeyword", stringKeyword},
+	tokenTrueKeyword:      {"tokenTrueKeyword", trueKeyword},
+	tokenFalseKeyword:     {"tokenFalseKeyword", falseKeyword},
+
+	tokenAssign:       {"tokenAssign", "="},
+	tokenEqual:        {"tokenEqual", "=="},
+	tokenNotEqual:     {"tokenNotEqual", "!="},
+	tokenLess:         {"tokenLess", "<"},
+	tokenLessEqual:    {"tokenLessEqual", "<="},
+	tokenGreater:      {"tokenGreater", ">"},
+	tokenGreaterEqual: {"tokenGreaterEqual", ">="},
+	tokenPlus:         {"tokenPlus", "+"},
+	tokenMinus:        {"tokenMinus", "-"},
+	tokenStar:         {"tokenStar", "*"},
+	tokenSlash:        {"tokenSlash", "/"},
+	tokenIncrement:    {"tokenIncrement", "++"},
+	tokenDecrement:    {"tokenDecrement", "--"},
+	tokenAnd:          {"tokenAnd", "&&"},
+	tokenOr:           {"tokenOr", "||"},
+	tokenNot:          {"tokenNot", "!"},
+
+	tokenSemicolon:    {"tokenSemicolon", ";"},
+	tokenComma:        {"tokenComma", ","},
+	tokenLeftParen:    {"tokenLeftParen", "("},
+	tokenRightParen:   {"tokenRightParen", ")"},
+	tokenLeftBrace:    {"tokenLeftBrace", "{"},
+	tokenRightBrace:   {"tokenRightBrace", "}"},
+	tokenLeftBracket:  {"tokenLeftBracket", "["},
+	tokenRightBracket: {"tokenRightBracket", "]"},
+	tokenDot:          {"tokenDot", "."},
+	tokenColon:        {"tokenColon", ":"},
+}
+
+// Lookups built from tokenTable.
+var (
+	tokenTypes = make(map[string]tokenType) // every type, by name
+	keywords   = make(map[string]tokenType) // keywords, by text
+	symbols    = make(map[string]tokenType) // operators and delimiters, by text
+
+	// Keywords The reserved words of the language, in the order of tokenTable.
+	Keywords []string
+	// Operators The arithmetic, relational and logical operators and the assignment, in the order
+	// of tokenTable.
+	Operators []string
+)
+
+func init() {
+	for i, entry := range tokenTable {
+		t := tokenType(i)
+		tokenTypes[entry.name] = t
+		switch {
+		case t.isKeyword():
+			keywords[entry.text] = t
+			Keywords = append(Keywords, entry.text)
+		case t.isOperator():
+			symbols[entry.text] = t
+			Operators = append(Operators, entry.text)
+		case t.isDelimiter():
+			symbols[entry.text] = t
+		}
+	}
+}
+
+func (t tokenType) String() string {
+	return tokenTable[t].name
+}
+
+func (t tokenType) isKeyword() bool {
+	return t >= tokenProgramKeyword && t <= tokenFalseKeyword
+}
+
+func (t tokenType) isOperator() bool {
+	return t >= tokenAssign && t <= tokenNot
+}
+
+func (t tokenType) isDelimiter() bool {
+	return t >= tokenSemicolon && t <= tokenColon
+}
 
 // token Defines a Token (token) structure.
 type token struct {
-	typ  tokenType
-	val  string
-	line int
+	typ    tokenType
+	val    string
+	line   int         // starting at 1
+	col    int         // in characters, starting at 1
+	offset int         // of the first byte
+	lit    interface{} // value of literals, see Token.Literal
 }
 
 type tokenType int
 
-var outputFileName string
-
 // lexer Holds the state of the scanner.
 // start is where the next token sent out begins.
 // pos is where we are in the scanning.
 type lexer struct {
-	name   string     // used for error reports
-	input  string     // string being scanned
-	start  int        // start position of this token
-	pos    int        // current position in the input
-	width  int        // width of last rune read
-	line   int        // line counter
-	tokens chan token // channel of the scanned items
+	name      string       // used for error reports
+	input     string       // string being scanned
+	start     int          // start position of this token
+	pos       int          // current position in the input
+	width     int          // width of last rune read
+	line      int          // line of start, starting at 1
+	lineStart int          // offset of the first byte of that line
+	counted   int          // newlines before this offset were counted in line
+	state     stateFn      // next state of the scanner, nil once the end of file was emitted
+	tokens    []token      // tokens scanned so far
+	read      int          // index in tokens of the next token to deliver
+	lit       interface{}  // value of the literal being scanned, for emit
+	errors    []Diagnostic // problems found inside the tokens scanned so far
 }
 
+// eof Returned by next at the end of the input.
+const eof rune = -1
+
 // stateFn Represents the state of the scanner
 // as a function that returns the next state.
 type stateFn func(*lexer) stateFn
 
-// Lex a constructor.
-func Lex(name, fileNameOutput, input string) *lexer {
-	outputFileName = fileNameOutput
-	l := &lexer{
-		name:   name,
-		input:  input,
-		tokens: make(chan token),
+// Lex a constructor. The input is scanned on demand, as its tokens are asked for.
+func Lex(name, input string) *lexer {
+	return &lexer{
+		name:  name,
+		input: input,
+		line:  1,
+		state: lexText,
+	}
+}
+
+// NextToken Delivers the next token of the input. Past the end of the input it keeps delivering the
+// end of file token.
+func (l *lexer) NextToken() Token {
+	return exported(l.nextToken())
+}
+
+// Peek Returns the token k positions ahead without delivering it, 1 being the next one.
+func (l *lexer) Peek(k int) Token {
+	return exported(l.peekToken(k))
+}
+
+// Mark Returns the position of the lexer in its token stream, for Rewind.
+func (l *lexer) Mark() int {
+	return l.read
+}
+
+// Rewind Goes back to a position given by Mark, so that the tokens after it are delivered again.
+// Rewind(0) starts over from the first token.
+func (l *lexer) Rewind(mark int) {
+	if mark >= 0 && mark <= l.read {
+		l.read = mark
+	}
+}
+
+func (l *lexer) nextToken() token {
+	t := l.peekToken(1)
+	if l.read < len(l.tokens) {
+		l.read++
+	}
+	return t
+}
+
+// peekToken Scans the input up to the token k positions ahead, if it was not yet, and returns it.
+func (l *lexer) peekToken(k int) token {
+	if k < 1 {
+		k = 1
 	}
-	go l.run() // Concurrently runs the state machine.
-	_, open := <-l.tokens
+	i := l.read + k - 1
+	for len(l.tokens) <= i && l.state != nil {
+		l.state = l.state(l)
+	}
+	if i >= len(l.tokens) {
+		return l.tokens[len(l.tokens)-1] // the end of file
+	}
+	return l.tokens[i]
+}
+
+// rest Delivers every token left, up to the end of file included.
+func (l *lexer) rest() []token {
+	for l.state != nil {
+		l.state = l.state(l)
+	}
+	tokens := l.tokens[l.read:len(l.tokens):len(l.tokens)]
+	l.read = len(l.tokens)
+	return tokens
+}
+
+// Token A token as seen by tools: the name of its type, its text and where it starts.
+type Token struct {
+	Type   string `json:"type"`
+	Value  string `json:"value"`
+	Line   int    `json:"line"`   // starting at 1
+	Column int    `json:"column"` // in characters, starting at 1
+	Offset int    `json:"offset"` // of the first byte
+
+	// Literal Value of literals: a string for strings and characters, escape sequences decoded, an
+	// int64 for integers and a float64 for reals. Nil for other tokens and for numbers out of range.
+	Literal interface{} `json:"literal,omitempty"`
+}
+
+// IsComment Reports whether the token is a comment, block or line, which the parser ignores.
+func (t Token) IsComment() bool {
+	return t.Type == tokenBlockComment.String() || t.Type == tokenLineComment.String()
+}
+
+// IsKeyword Reports whether the token is a keyword.
+func (t Token) IsKeyword() bool {
+	return tokenTypes[t.Type].isKeyword()
+}
+
+// IsOperator Reports whether the token is an operator, the assignment included.
+func (t Token) IsOperator() bool {
+	return tokenTypes[t.Type].isOperator()
+}
+
+// IsDelimiter Reports whether the token is a delimiter: a parenthesis, brace, bracket or punctuation.
+func (t Token) IsDelimiter() bool {
+	return tokenTypes[t.Type].isDelimiter()
+}
 
-	for !open { // Waits until channel is not closed...
+// IsEOF Reports whether the token marks the end of the input.
+func (t Token) IsEOF() bool {
+	return tokenTypes[t.Type] == tokenEOF
+}
+
+// Is Reports whether the token is of the type of the keyword, operator or delimiter spelled text,
+// as in t.Is(";"), per tokenTable.
+func (t Token) Is(text string) bool {
+	typ, ok := keywords[text]
+	if !ok {
+		typ, ok = symbols[text]
+	}
+	return ok && tokenTypes[t.Type] == typ
+}
 
+// Tokens Reads every token left in the lexer, comments included, and reports the malformed ones.
+func Tokens(l *lexer) ([]Token, []Diagnostic) {
+	tokens := l.rest()
+	list := make([]Token, len(tokens))
+	for i, t := range tokens {
+		list[i] = exported(t)
 	}
-	//outputTokensInFile(l)
-	Syntax(l)
+	return list, l.lexicalErrors(tokens)
+}
+
+func exported(t token) Token {
+	return Token{t.typ.String(), t.val, t.line, t.col, t.offset, t.lit}
+}
 
-	return l
+// malformed Code and message of the diagnostic of each type of malformed token, the token types
+// of lexical errors. The lexer reports every token of these types as it emits it, see emit.
+var malformed = map[tokenType]struct{ code, message string }{
+	tokenMalformedNumber:       {CodeMalformedNumber, "malformed number %s"},
+	tokenMalformedComment:      {CodeMalformedComment, "comment %s is not terminated"},
+	tokenMalformedString:       {CodeMalformedString, "string %s is not terminated"},
+	tokenMalformedChar:         {CodeMalformedChar, "malformed character %s"},
+	tokenMalformedLogicalOp:    {CodeMalformedLogicalOp, "malformed logical operator %s"},
+	tokenMalformedArithmeticOp: {CodeMalformedArithmeticOp, "malformed arithmetic operator %s"},
+	tokenMalformedRelationalOp: {CodeMalformedRelationalOp, "malformed relational operator %s"},
+	tokenInvalidChar:           {CodeInvalidChar, "invalid character %s"},
 }
 
-func check(e error) {
-	if e != nil {
-		panic(e)
+// lexicalErrors Returns the problems the lexer found in tokens, a run of the tokens it scanned, in
+// the order of the source.
+func (l *lexer) lexicalErrors(tokens []token) []Diagnostic {
+	var list []Diagnostic
+	if len(tokens) > 0 {
+		for _, e := range l.errors {
+			if e.Span.Start >= tokens[0].offset {
+				list = append(list, e)
+			}
+		}
 	}
+	sort.SliceStable(list, func(i, j int) bool { // the escapes of a string come before the string
+		return list[i].Span.Start < list[j].Span.Start
+	})
+	return list
 }
 
-func outputTokensInFile(l *lexer) {
+// WriteTokenTable Writes the tokens as a table followed by the list of errors.
+func WriteTokenTable(w io.Writer, tokens []Token, errorList []Diagnostic) error {
 	delim := "-----------------------------------------------------------------------------------------------------------------\n"
 	header := "|\t\t" + "Valor" + "\t\t|" + "\t\t" + "Tipo" + "\t\t|\t\t\t" + "Linha" + "\t\t\t|\n"
-	f, err := os.OpenFile(outputFileName, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
-	check(err)
-	_, err = f.Write([]byte(header))
-	check(err)
-	_, err = f.Write([]byte(delim))
-	check(err)
-
-	tokens := make([]token, 0)
-	for t := range l.tokens {
-		tokens = append(tokens, t)
-		fmtStr := "|\t\t" + t.val + "\t\t|" + "\t\t" + parseTokenType(t) + "\t\t|\t\t" + strconv.Itoa(t.line) + "\t\t|\n"
-		_, err = f.Write([]byte(fmtStr))
-		check(err)
-		_, err = f.Write([]byte(delim))
-		check(err)
-	}
-
-	errorList := checkErrors(tokens)
+	b := bufio.NewWriter(w)
+	b.WriteString(header)
+	b.WriteString(delim)
+
+	for _, t := range tokens {
+		fmtStr := "|\t\t" + t.Value + "\t\t|" + "\t\t" + t.Type + "\t\t|\t\t" + strconv.Itoa(t.Line) + "\t\t|\n"
+		b.WriteString(fmtStr)
+		b.WriteString(delim)
+	}
+
 	if len(errorList) > 0 {
 		delim = "\n\n----------------------------------------------------------------\n"
-		_, err = f.Write([]byte(delim))
+		b.WriteString(delim)
 		fmtStr := "|\t\t\tLista de Erro(s)\t\t\t|\n"
 		delim = "----------------------------------------------------------------\n"
-		_, err = f.Write([]byte(fmtStr))
-		check(err)
-		_, err = f.Write([]byte(delim))
-		check(err)
+		b.WriteString(fmtStr)
+		b.WriteString(delim)
 		header = "|\t\t\tTIPO\t\t\t|\tLINHA\t|\n"
-		_, err = f.Write([]byte(header))
-		check(err)
-		_, err = f.Write([]byte(delim))
-		check(err)
-
-		for i := range errorList {
-			t := errorList[i]
-			fmtStr = "|\t\t" + parseTokenType(t) + "\t\t|\t" + strconv.Itoa(t.line) + "\t|\n"
-			_, err = f.Write([]byte(fmtStr))
-			check(err)
-			_, err = f.Write([]byte(delim))
-			check(err)
+		b.WriteString(header)
+		b.WriteString(delim)
+
+		for _, e := range errorList {
+			fmtStr = "|\t\t" + e.Code + "\t\t|\t" + strconv.Itoa(e.Line) + "\t|\n"
+			b.WriteString(fmtStr)
+			b.WriteString(delim)
 		}
 	} else {
 		fmtStr := "|\tNENHUM ERRO ENCONTRADO\t|\n"
-		_, err = f.Write([]byte(fmtStr))
-		check(err)
+		b.WriteString(fmtStr)
 	}
-}
-
-// run Lexes the input by executing state functions
-// until the state is nil.
-func (l *lexer) run() {
-	for state := lexText; state != nil; {
-		state = state(l)
-	}
-	close(l.tokens) // No more tokens will be delivered.
+	return b.Flush()
 }
 
 // next returns the next rune in the input.
 func (l *lexer) next() (r rune) {
 	if l.isEOF() {
 		l.width = 0
-		return rune(tokenEOF)
+		return eof
 	}
 	r, l.width = utf8.DecodeRuneInString(l.input[l.pos:])
 	l.pos += l.width
 	return r
 }
 
+// emit Delivers the token just scanned. A malformed one is reported with the message of its type,
+// see emitMalformed for a more precise one.
 func (l *lexer) emit(t tokenType) {
-	l.tokens <- token{t, l.input[l.start:l.pos], l.line}
+	if m, ok := malformed[t]; ok {
+		l.emitMalformed(t, m.message)
+		return
+	}
+	l.push(t)
+}
+
+// emitMalformed Emits the malformed token just scanned and reports it. The message refers to the
+// token as %s.
+func (l *lexer) emitMalformed(t tokenType, message string) {
+	l.errorf(l.start, l.pos, malformed[t].code, message, token{typ: t, val: l.input[l.start:l.pos]})
+	l.push(t)
+}
+
+func (l *lexer) push(t tokenType) {
+	l.position()
+	col := utf8.RuneCountInString(l.input[l.lineStart:l.start]) + 1
+	l.tokens = append(l.tokens, token{t, l.input[l.start:l.pos], l.line, col, l.start, l.lit})
 	l.start = l.pos
+	l.lit = nil
+}
+
+// errorf Reports a problem with the bytes of the input from start up to end. start must lie on the
+// first line of the token being scanned.
+func (l *lexer) errorf(start, end int, code, format string, args ...interface{}) {
+	l.errors = append(l.errors, errorAt(l.name, l.posAt(start), end, code, format, args...))
+}
+
+// warnf Like errorf, for a problem that does not prevent the compilation.
+func (l *lexer) warnf(start, end int, code, format string, args ...interface{}) {
+	l.errors = append(l.errors, warningAt(l.name, l.posAt(start), end, code, format, args...))
+}
+
+func (l *lexer) posAt(offset int) ast.Pos {
+	l.position()
+	return ast.Pos{Line: l.line, Column: utf8.RuneCountInString(l.input[l.lineStart:offset]) + 1, Offset: offset}
+}
+
+// position Brings line and lineStart up to date with start, counting the newlines skipped since the last call.
+func (l *lexer) position() {
+	for ; l.counted < l.start; l.counted++ {
+		if l.input[l.counted] == '\n' {
+			l.line++
+			l.lineStart = l.counted + 1
+		}
+	}
 }
 
 // lexText A partir de um estado inicial procura por lexemas tratando-os individualmente.
@@ -250,17 +534,10 @@ func lexText(l *lexer) stateFn {
 		case unicode.IsLetter(r):
 			return lexLetter
 		case unicode.IsSpace(r):
-			if strings.IndexRune("\n", r) >= 0 {
-				l.line += 1
-			}
 			l.ignore()
 		case unicode.IsNumber(r):
 			return lexNumber
 		case strings.IndexRune("&|!", r) >= 0:
-			if strings.IndexRune("!", r) >= 0 && strings.IndexRune("=", r) >= 0 {
-				l.emit(tokenRelationalOp)
-				return lexText
-			}
 			l.backup()
 			return lexLogicalOperator
 		case strings.IndexRune("=!><", r) >= 0:
@@ -270,34 +547,30 @@ func lexText(l *lexer) stateFn {
 			l.backup()
 			return lexDelimiter
 		case strings.IndexRune("'", r) >= 0:
-			if unicode.IsLetter(l.peek()) || unicode.IsDigit(l.peek()) {
-				if strings.IndexRune("'", l.next()) >= 0 {
-					return lexChar
-				}
-			} else {
-				l.emit(tokenMalformedChar)
-				return lexText
-			}
 			return lexChar
 		case strings.IndexRune("\"", r) >= 0:
 			return lexString
+		case strings.IndexRune("%", r) >= 0:
+			return lexLineComment
 		case strings.IndexRune("/", r) >= 0 || strings.IndexRune("*", r) >= 0:
 			if strings.IndexRune("/", r) >= 0 { // If (r == / or *) check whether it could possibly be a comment block.
 				if !(strings.IndexRune("#", l.peek()) >= 0) { // If not, emit an arithmetic operator (/)
-					l.emit(tokenArithmeticOp)
+					l.emitSymbol()
 				} else { // Otherwise, lex a comment block.
 					return lexCommentBlock
 				}
 			}
 			if strings.IndexRune("*", r) >= 0 { // Verification not necessary but left intentionally for legibility.
-				l.emit(tokenArithmeticOp)
+				l.emitSymbol()
 			}
 		case strings.IndexRune("+-*", r) >= 0:
 			l.backup()
 			return lexArithmeticOperator
-		case r == rune(tokenEOF):
+		case r == eof:
 			l.emit(tokenEOF)
 			return nil
+		default:
+			l.emit(tokenInvalidChar)
 		}
 	}
 }
@@ -306,7 +579,7 @@ func lexText(l *lexer) stateFn {
 // Otherwise, it could be a keyword or identifier, then handle it as so.
 func lexLetter(l *lexer) stateFn {
 	switch r := l.next(); {
-	case r == rune(tokenEOF):
+	case r == eof:
 		l.emit(tokenIdentifier)
 		l.emit(tokenEOF)
 		return nil
@@ -338,41 +611,107 @@ func lexIdentifier(l *lexer) stateFn {
 	return lexText
 }
 
-// lexNumber lexes a number (digit or multiple digits) including floating point.
+// lexNumber Lexes an integer, as in 12, or a real, as in 1.5, 1e-3 or 2.5E+10. Leading zeros mean
+// nothing, 012 is 12, and a number never holds a sign: in -4 the minus is an operator.
 func lexNumber(l *lexer) stateFn {
-	digits := "0123456789"
 	l.acceptRun(digits)
-
-	if strings.IndexRune(".", l.next()) >= 0 {
-		switch r := l.peek(); {
-		case unicode.IsNumber(r):
-			l.acceptRun(digits)
-			l.emit(tokenNumber)
+	real := false
+	if l.accept(".") {
+		if !l.accept(digits) { // A point not followed by digits is part of the malformed number.
+			l.emit(tokenMalformedNumber)
 			return lexText
-		default:
-			l.backup()
+		}
+		l.acceptRun(digits)
+		real = true
+	}
+	if l.exponent() {
+		real = true
+	}
+	l.emitNumber(real)
+	return lexText
+}
+
+const digits = "0123456789"
+
+// exponent Accepts the exponent of a real, as in e10, E-3 or e+5. An e not followed by digits, with
+// an optional sign in between, is left for an identifier.
+func (l *lexer) exponent() bool {
+	rest := l.input[l.pos:]
+	if rest == "" || rest[0] != 'e' && rest[0] != 'E' {
+		return false
+	}
+	i := 1
+	if i < len(rest) && (rest[i] == '+' || rest[i] == '-') {
+		i++
+	}
+	if i == len(rest) || strings.IndexByte(digits, rest[i]) < 0 {
+		return false
+	}
+	l.pos += i
+	l.acceptRun(digits)
+	return true
+}
+
+// emitNumber Emits the number just scanned with its value, reporting an integer out of the range of
+// integer, 64 bits, or a real out of the range of real, a 64-bit float. A real whose digits a real
+// cannot hold is rounded, with a warning.
+func (l *lexer) emitNumber(real bool) {
+	text := l.input[l.start:l.pos]
+	if !real {
+		v, err := strconv.ParseInt(text, 10, 64)
+		switch {
+		case errors.Is(err, strconv.ErrRange):
+			l.errorf(l.start, l.pos, CodeNumberRange, "integer %s overflows integer", text)
+		case err != nil: // digits of other scripts, which lexText lets in
 			l.emit(tokenMalformedNumber)
+			return
+		default:
+			l.lit = v
 		}
+		l.emit(tokenNumber)
+		return
+	}
+
+	v, err := strconv.ParseFloat(text, 64)
+	switch {
+	case errors.Is(err, strconv.ErrRange):
+		l.errorf(l.start, l.pos, CodeNumberRange, "real %s overflows real", text)
+	case err != nil:
+		l.emit(tokenMalformedNumber)
+		return
+	default:
+		if rounded := strconv.FormatFloat(v, 'g', -1, 64); significand(rounded) != significand(text) {
+			l.warnf(l.start, l.pos, CodeInexactReal, "real %s is rounded to %s", text, rounded)
+		}
+		l.lit = v
 	}
-	l.backup()
 	l.emit(tokenNumber)
-	return lexText
+}
+
+// significand Significant digits of a number, without leading and trailing zeros: "1.50e3" and
+// "0.015" both give "15".
+func significand(number string) string {
+	if i := strings.IndexAny(number, "eE"); i >= 0 {
+		number = number[:i]
+	}
+	number = strings.Replace(number, ".", "", 1)
+	return strings.TrimRight(strings.TrimLeft(number, "0"), "0")
 }
 
 func lexArithmeticOperator(l *lexer) stateFn {
 	switch r := l.next(); {
 	case strings.IndexRune("+", r) >= 0 && strings.IndexRune("+", l.peek()) >= 0:
 		l.next()
-		l.emit(tokenArithmeticOp)
+		l.emitSymbol()
 	case strings.IndexRune("-", r) >= 0 && strings.IndexRune("-", l.peek()) >= 0:
 		l.next()
-		l.emit(tokenArithmeticOp)
+		l.emitSymbol()
 	case strings.IndexRune("-", r) >= 0:
-		l.emit(tokenArithmeticOp)
+		l.emitSymbol()
 	case strings.IndexRune("+", r) >= 0:
-		l.emit(tokenArithmeticOp)
+		l.emitSymbol()
 	case strings.IndexRune("*", r) >= 0:
-		l.emit(tokenArithmeticOp)
+		l.emitSymbol()
 	default:
 		l.emit(tokenMalformedArithmeticOp)
 	}
@@ -389,7 +728,7 @@ func lexCommentBlock(l *lexer) stateFn {
 				l.emit(tokenBlockComment)
 				return lexText
 			}
-		case r == rune(tokenEOF):
+		case r == eof:
 			l.emit(tokenMalformedComment)
 			l.emit(tokenEOF)
 			return nil
@@ -397,30 +736,48 @@ func lexCommentBlock(l *lexer) stateFn {
 	}
 }
 
+// lexLineComment Lexes a comment from '%' up to the end of the line, the line end ("\n" or "\r\n",
+// or a "\r" ending the file) excluded. Inside it '/#' and '#/' have no meaning, just as '%' has
+// none inside a comment block.
+func lexLineComment(l *lexer) stateFn {
+	for {
+		switch r := l.next(); {
+		case r == eof:
+			l.emit(tokenLineComment)
+			l.emit(tokenEOF)
+			return nil
+		case r == '\r' && l.isEOF() || l.isLineEnd(r): // before isLineEnd, whose peek at the end loses the \r
+			l.backup()
+			l.emit(tokenLineComment)
+			return lexText
+		}
+	}
+}
+
 func lexRelationalOperator(l *lexer) stateFn {
 	switch r := l.next(); {
 	case strings.IndexRune("=", r) >= 0:
 		if strings.IndexRune("=", l.peek()) >= 0 {
 			l.next()
-			l.emit(tokenArithmeticOp)
+			l.emitSymbol()
 			return lexText
 		}
-		l.emit(tokenArithmeticOp)
+		l.emitSymbol()
 	case strings.IndexRune(">", r) >= 0:
 		if strings.IndexRune("=", l.peek()) >= 0 {
 			l.next()
-			l.emit(tokenRelationalOp)
+			l.emitSymbol()
 			return lexText
 		}
 
-		l.emit(tokenRelationalOp)
+		l.emitSymbol()
 	case strings.IndexRune("<", r) >= 0:
 		if strings.IndexRune("=", l.peek()) >= 0 {
 			l.next()
-			l.emit(tokenRelationalOp)
+			l.emitSymbol()
 			return lexText
 		}
-		l.emit(tokenRelationalOp)
+		l.emitSymbol()
 	default:
 		l.emit(tokenMalformedRelationalOp)
 	}
@@ -429,41 +786,167 @@ func lexRelationalOperator(l *lexer) stateFn {
 
 func lexDelimiter(l *lexer) stateFn {
 	l.next()
-	l.emit(tokenDelimiter)
+	l.emitSymbol()
 	return lexText
 }
 
+// lexChar Lexes a character literal: a single character or escape sequence between single quotes.
+// Like a string, it cannot span lines.
 func lexChar(l *lexer) stateFn {
-	l.next()
-	l.emit(tokenChar)
-	return lexText
+	count := 0 // characters between the quotes
+	for {
+		switch r := l.next(); {
+		case r == '\'':
+			l.lit = unescape(l.input[l.start+1 : l.pos-1])
+			switch {
+			case count == 0:
+				l.emitMalformed(tokenMalformedChar, "character %s is empty")
+			case count > 1:
+				l.emitMalformed(tokenMalformedChar, "character %s holds more than one character")
+			default:
+				l.emit(tokenChar)
+			}
+			return lexText
+		case l.isLineEnd(r):
+			l.backup()
+			l.lit = unescape(l.input[l.start+1 : l.pos])
+			l.emitMalformed(tokenMalformedChar, "character %s is not terminated")
+			return lexText
+		case r == '\\':
+			l.escape()
+			count++
+		default:
+			count++
+		}
+	}
 }
 
+// lexString Lexes a string up to its closing double quote. A string cannot span lines: one reaching
+// the end of its line is not terminated.
 func lexString(l *lexer) stateFn {
 	for {
 		switch r := l.next(); {
-		case strings.IndexRune("\"", r) >= 0:
-			//l.next()
+		case r == '"':
+			l.lit = unescape(l.input[l.start+1 : l.pos-1])
 			l.emit(tokenString)
 			return lexText
-		case r == rune(tokenEOF):
+		case l.isLineEnd(r):
+			l.backup()
+			l.lit = unescape(l.input[l.start+1 : l.pos])
 			l.emit(tokenMalformedString)
-			l.emit(tokenEOF)
-			return nil
+			return lexText
+		case r == '\\':
+			l.escape()
+		}
+	}
+}
+
+// isLineEnd Reports whether the rune just read ends the line, or the input.
+func (l *lexer) isLineEnd(r rune) bool {
+	return r == '\n' || r == eof || r == '\r' && l.peek() == '\n'
+}
+
+// escape Skips the escape sequence after the backslash just read, reporting it when it is not valid.
+func (l *lexer) escape() {
+	start := l.pos - 1
+	if _, size := escapeSequence(l.input[l.pos:]); size > 0 {
+		l.pos += size
+		return
+	}
+	message := "unknown escape sequence %q"
+	if r := l.next(); r == 'u' || r == 'U' {
+		message = "invalid unicode escape %q"
+		l.acceptRun(hexDigits)
+	} else if l.isLineEnd(r) {
+		l.backup()
+	}
+	l.errorf(start, l.pos, CodeInvalidEscape, message, l.input[start:l.pos])
+}
+
+const hexDigits = "0123456789abcdefABCDEF"
+
+// escapeSequence Decodes the escape sequence at the start of s, which follows a backslash: \n, \t, \\,
+// \', \" or a unicode escape, \u followed by 4 hexadecimal digits or \U followed by 8. It returns the
+// rune and the length of the sequence, 0 when it is not a valid one.
+func escapeSequence(s string) (rune, int) {
+	if s == "" {
+		return 0, 0
+	}
+	switch s[0] {
+	case 'n':
+		return '\n', 1
+	case 't':
+		return '\t', 1
+	case '\\', '\'', '"':
+		return rune(s[0]), 1
+	case 'u', 'U':
+		hex := 4
+		if s[0] == 'U' {
+			hex = 8
+		}
+		if len(s) <= hex {
+			return 0, 0
+		}
+		v, err := strconv.ParseUint(s[1:1+hex], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(v)) {
+			return 0, 0
 		}
+		return rune(v), 1 + hex
 	}
+	return 0, 0
+}
+
+// unescape Decodes the escape sequences of the text between the quotes of a literal. An invalid one
+// stands for the replacement character.
+func unescape(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		i += size
+		if r == '\\' {
+			if r, size = escapeSequence(s[i:]); size == 0 {
+				r, size = utf8.RuneError, 0
+				if i < len(s) {
+					_, size = utf8.DecodeRuneInString(s[i:])
+				}
+				if size == 1 && (s[i] == 'u' || s[i] == 'U') { // its digits too, as lexer.escape reports them
+					for i+size < len(s) && strings.IndexByte(hexDigits, s[i+size]) >= 0 {
+						size++
+					}
+				}
+			}
+			i += size
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// Unquote Value of a string or character literal, given as written in the source, with its escape
+// sequences decoded as by the lexer.
+func Unquote(lit string) string {
+	if len(lit) >= 2 {
+		lit = lit[1 : len(lit)-1]
+	}
+	return unescape(lit)
 }
 
 func lexLogicalOperator(l *lexer) stateFn {
 	switch r := l.next(); {
 	case strings.IndexRune("&", r) >= 0 && strings.IndexRune("&", l.peek()) >= 0:
 		l.next()
-		l.emit(tokenLogicalOp)
+		l.emitSymbol()
 	case strings.IndexRune("|", r) >= 0 && strings.IndexRune("|", l.peek()) >= 0:
 		l.next()
-		l.emit(tokenLogicalOp)
+		l.emitSymbol()
+	case strings.IndexRune("!", r) >= 0 && strings.IndexRune("=", l.peek()) >= 0:
+		l.next()
+		l.emitSymbol()
 	case strings.IndexRune("!", r) >= 0:
-		l.emit(tokenLogicalOp)
+		l.emitSymbol()
 	default:
 		l.emit(tokenMalformedLogicalOp)
 	}
@@ -475,6 +958,16 @@ func (l *lexer) isIdentifier(r rune) bool {
 	return unicode.IsLetter(r) || unicode.IsNumber(r) || strings.IndexRune("_", r) >= 0
 }
 
+// pos Position of the first character of the token.
+func (i token) pos() ast.Pos {
+	return ast.Pos{Line: i.line, Column: i.col, Offset: i.offset}
+}
+
+// end Offset of the first byte after the token.
+func (i token) end() int {
+	return i.offset + len(i.val)
+}
+
 func (i token) String() string {
 	switch i.typ {
 	case tokenEOF:
@@ -488,86 +981,31 @@ func (i token) String() string {
 	return fmt.Sprintf("%q", i.val)
 }
 
-/* emitIfKeyword Identify whether is a keyword if so, emit.
-We could have worked with an array instead of a switch case,
-although, intentionally using a switch may be useful for distinction
-(if needed) in the future.
-*/
+// emitIfKeyword Emits the word just scanned with the type of its keyword, if it is one.
 func (l *lexer) emitIfKeyword() bool {
-	switch word := l.input[l.start:l.pos]; {
-	case word == programKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == varKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == constKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == registerKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == functionKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == procedureKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == returnKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == mainKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == ifKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == elseKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == whileKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == readKeyword:
-		l.emit(tokenKeyword)
+	if t, ok := keywords[l.input[l.start:l.pos]]; ok {
+		l.emit(t)
 		return true
-	case word == writeKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == integerKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == realKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == booleanKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == charKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == stringKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == trueKeyword:
-		l.emit(tokenKeyword)
-		return true
-	case word == falseKeyword:
-		l.emit(tokenKeyword)
-		return true
-	default:
-		return false
 	}
+	return false
+}
+
+// emitSymbol Emits the operator or delimiter just scanned with its own type.
+func (l *lexer) emitSymbol() {
+	l.emit(symbols[l.input[l.start:l.pos]])
 }
 
 /*
-	Helper Functions Below:
-		ignore Skips over the pending input before this point.
-		backup Steps back one rune.
-		peek   Returns but not consume the next rune in the input.
-	Aceptors:
-		accept Consumes the next rune if it is from the valid set.
-		acceptRun Consumes a run of runes from the valid set.
+Helper Functions Below:
+
+	ignore Skips over the pending input before this point.
+	backup Steps back one rune.
+	peek   Returns but not consume the next rune in the input.
+
+Aceptors:
+
+	accept Consumes the next rune if it is from the valid set.
+	acceptRun Consumes a run of runes from the valid set.
 */
 func (l *lexer) ignore() {
 	l.start = l.pos
@@ -604,49 +1042,3 @@ func (l *lexer) isEOF() bool {
 	}
 	return false
 }
-
-// Debug Display tokens.
-func (l *lexer) Debug() {
-	fmt.Println("Lexer state info: ")
-	fmt.Println("Start index: ")
-	fmt.Println(l.start)
-	fmt.Println("Current index: ")
-	fmt.Println(l.pos)
-	fmt.Println("Last token width: ")
-	fmt.Println(l.width)
-	fmt.Println("token list: ")
-	for token := range l.tokens {
-		fmt.Println("value: ", token.val)
-		fmt.Println("type index: ", token.typ)
-		fmt.Println("type name: ", parseTokenType(token))
-		fmt.Println("length: ", len(token.val))
-		fmt.Println("--------------------------------")
-	}
-}
-
-func checkErrors(tokens []token) []token {
-	errorList := [5]tokenType{tokenMalformedNumber, tokenMalformedComment, tokenMalformedString, tokenMalformedLogicalOp, tokenMalformedArithmeticOp}
-	errors := make([]token, 0)
-
-	for t := range tokens {
-		for e := range errorList {
-			if errorList[e] == tokens[t].typ {
-				errors = append(errors, tokens[t])
-			}
-		}
-	}
-	return errors
-}
-
-func parseTokenType(token token) string {
-	return types[token.typ]
-}
-
-func parseTokenTypeByString(tp string) tokenType {
-	for i := 0; i < len(types); i++ {
-		if types[i] == tp {
-			return tokenType(i)
-		}
-	}
-	return -1
-}
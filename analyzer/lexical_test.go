@@ -0,0 +1,350 @@
+package Compiler
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+)
+
+// lexed Type and text of a token, as the tests expect them.
+type lexed struct {
+	typ tokenType
+	val string
+}
+
+func (x lexed) String() string {
+	return fmt.Sprintf("%s %q", x.typ, x.val)
+}
+
+// lexAll Lexes the whole input, returning its tokens but the end of file and its lexical errors.
+func lexAll(input string) ([]lexed, []Diagnostic) {
+	tokens, errs := Tokens(Lex("test", input))
+	var list []lexed
+	for _, t := range tokens {
+		if typ := tokenTypes[t.Type]; typ != tokenEOF {
+			list = append(list, lexed{typ, t.Value})
+		}
+	}
+	return list, errs
+}
+
+func checkTokens(t *testing.T, input string, want []lexed) {
+	t.Helper()
+	got, _ := lexAll(input)
+	if len(got) != len(want) {
+		t.Errorf("%q: got %d tokens %v, want %d %v", input, len(got), got, len(want), want)
+		return
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("%q: token %d is %v, want %v", input, i, got[i], want[i])
+		}
+	}
+}
+
+func TestComments(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []lexed
+	}{
+		{"% a line\nx", []lexed{{tokenLineComment, "% a line"}, {tokenIdentifier, "x"}}},
+		{"% a line\r\nx", []lexed{{tokenLineComment, "% a line"}, {tokenIdentifier, "x"}}},
+		{"% no newline", []lexed{{tokenLineComment, "% no newline"}}},
+		{"% a line\r", []lexed{{tokenLineComment, "% a line"}}},
+		{"% a \r in a line\n", []lexed{{tokenLineComment, "% a \r in a line"}}},
+		{"%", []lexed{{tokenLineComment, "%"}}},
+		{"x % /# opens nothing\ny", []lexed{{tokenIdentifier, "x"}, {tokenLineComment, "% /# opens nothing"}, {tokenIdentifier, "y"}}},
+		{"% #/ closes nothing\ny", []lexed{{tokenLineComment, "% #/ closes nothing"}, {tokenIdentifier, "y"}}},
+		{"/# % is nothing here #/ x", []lexed{{tokenBlockComment, "/# % is nothing here #/"}, {tokenIdentifier, "x"}}},
+		{"/# a\n% b\r\nc #/x", []lexed{{tokenBlockComment, "/# a\n% b\r\nc #/"}, {tokenIdentifier, "x"}}},
+		{"/# a /# b #/ c #/", []lexed{{tokenBlockComment, "/# a /# b #/"}, {tokenIdentifier, "c"}, {tokenInvalidChar, "#"}, {tokenSlash, "/"}}},
+		{"/##/x", []lexed{{tokenBlockComment, "/##/"}, {tokenIdentifier, "x"}}},
+		{"x/#c#/y", []lexed{{tokenIdentifier, "x"}, {tokenBlockComment, "/#c#/"}, {tokenIdentifier, "y"}}},
+		{"x / y", []lexed{{tokenIdentifier, "x"}, {tokenSlash, "/"}, {tokenIdentifier, "y"}}},
+		{"/# never closed\n% x", []lexed{{tokenMalformedComment, "/# never closed\n% x"}}},
+	}
+	for _, test := range tests {
+		checkTokens(t, test.input, test.want)
+	}
+}
+
+func TestUnterminatedComment(t *testing.T) {
+	_, errs := lexAll("x /# open")
+	if len(errs) != 1 || errs[0].Code != CodeMalformedComment {
+		t.Fatalf("got %v, want a single %s error", errs, CodeMalformedComment)
+	}
+	if errs[0].Line != 1 || errs[0].Column != 3 {
+		t.Errorf("error at %d:%d, want 1:3", errs[0].Line, errs[0].Column)
+	}
+}
+
+func TestPeek(t *testing.T) {
+	l := Lex("test", "x = 1;")
+	if p := l.Peek(3); p.Value != "1" {
+		t.Errorf("Peek(3) = %q, want \"1\"", p.Value)
+	}
+	if p := l.Peek(0); p.Value != "x" {
+		t.Errorf("Peek(0) = %q, want the next token \"x\"", p.Value)
+	}
+	if p := l.Peek(10); !p.IsEOF() {
+		t.Errorf("Peek(10) = %s %q, want the end of file", p.Type, p.Value)
+	}
+	for _, want := range []string{"x", "=", "1", ";"} {
+		if p, n := l.Peek(1), l.NextToken(); p != n || n.Value != want {
+			t.Errorf("Peek(1) = %q and NextToken() = %q, want %q", p.Value, n.Value, want)
+		}
+	}
+}
+
+func TestMarkRewind(t *testing.T) {
+	l := Lex("test", "a b c")
+	l.NextToken()
+	mark := l.Mark()
+	first := []Token{l.NextToken(), l.NextToken(), l.NextToken()}
+	l.Rewind(mark)
+	for i, want := range first {
+		if got := l.NextToken(); got != want {
+			t.Errorf("token %d after Rewind is %q, want %q", i, got.Value, want.Value)
+		}
+	}
+	l.Rewind(l.Mark() + 1) // ahead of the tokens read: ignored
+	if got := l.NextToken(); !got.IsEOF() {
+		t.Errorf("Rewind ahead moved the lexer to %q", got.Value)
+	}
+	l.Rewind(0)
+	if got := l.NextToken(); got.Value != "a" {
+		t.Errorf("after Rewind(0) got %q, want \"a\"", got.Value)
+	}
+}
+
+func TestNextTokenAfterEOF(t *testing.T) {
+	l := Lex("test", "x")
+	if got := l.NextToken(); got.Value != "x" {
+		t.Fatalf("got %q, want \"x\"", got.Value)
+	}
+	eof := l.NextToken()
+	if !eof.IsEOF() {
+		t.Fatalf("got %s %q, want the end of file", eof.Type, eof.Value)
+	}
+	for i := 0; i < 3; i++ {
+		if got := l.NextToken(); got != eof {
+			t.Errorf("call %d past the end got %s %q, want the end of file again", i+1, got.Type, got.Value)
+		}
+	}
+}
+
+// largeInput files/sample.txt repeated up to some 400 KB, for the benchmarks.
+func largeInput(b *testing.B) string {
+	sample, err := os.ReadFile("../files/sample.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	var input strings.Builder
+	for input.Len() < 400<<10 {
+		input.Write(sample)
+	}
+	return input.String()
+}
+
+func BenchmarkLex(b *testing.B) {
+	input := largeInput(b)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		l := Lex("large", input)
+		for !l.NextToken().IsEOF() {
+		}
+	}
+}
+
+// BenchmarkLexChannel Measures the lexer delivering its tokens from a goroutine over a channel, as
+// it did before scanning on demand, for comparison with BenchmarkLex.
+func BenchmarkLexChannel(b *testing.B) {
+	input := largeInput(b)
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tokens := make(chan Token)
+		go func() {
+			l := Lex("large", input)
+			for {
+				t := l.NextToken()
+				tokens <- t
+				if t.IsEOF() {
+					close(tokens)
+					return
+				}
+			}
+		}()
+		for range tokens {
+		}
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		input   string
+		literal interface{} // of the number
+		code    string      // of the diagnostic, if any
+	}{
+		{"0", int64(0), ""},
+		{"007", int64(7), ""},
+		{"9223372036854775807", int64(9223372036854775807), ""},
+		{"9223372036854775808", nil, CodeNumberRange},
+		{"0.5", 0.5, ""},
+		{"00.50", 0.5, ""},
+		{"1e3", 1000.0, ""},
+		{"2.5E-3", 0.0025, ""},
+		{"1.5e+2", 150.0, ""},
+		{"1e400", nil, CodeNumberRange},
+		{"3.14159265358979323846", 3.141592653589793, CodeInexactReal},
+		{"1.50e3", 1500.0, ""}, // trailing zeros are not digits a real loses
+	}
+	for _, test := range tests {
+		tokens, errs := Tokens(Lex("test", test.input))
+		if len(tokens) != 2 || tokenTypes[tokens[0].Type] != tokenNumber || tokens[0].Value != test.input {
+			t.Errorf("%q: got tokens %v, want the number alone", test.input, tokens)
+			continue
+		}
+		if tokens[0].Literal != test.literal {
+			t.Errorf("%q: literal %#v, want %#v", test.input, tokens[0].Literal, test.literal)
+		}
+		switch {
+		case test.code == "" && len(errs) > 0:
+			t.Errorf("%q: unexpected %s", test.input, errs[0].Message)
+		case test.code != "" && (len(errs) != 1 || errs[0].Code != test.code):
+			t.Errorf("%q: got %v, want a single %s diagnostic", test.input, errs, test.code)
+		}
+	}
+}
+
+func TestNumberSeverity(t *testing.T) {
+	_, errs := lexAll("9223372036854775808 3.14159265358979323846")
+	if len(errs) != 2 {
+		t.Fatalf("got %v, want two diagnostics", errs)
+	}
+	if errs[0].Severity != SeverityError || !HasErrors(errs[:1]) {
+		t.Errorf("an integer out of range is %v, want an error", errs[0].Severity)
+	}
+	if errs[1].Severity != SeverityWarning || HasErrors(errs[1:]) {
+		t.Errorf("an inexact real is %v, want a warning", errs[1].Severity)
+	}
+}
+
+func TestNegativeNumber(t *testing.T) {
+	checkTokens(t, "-44", []lexed{{tokenMinus, "-"}, {tokenNumber, "44"}})
+	checkTokens(t, "0 - 4.5", []lexed{{tokenNumber, "0"}, {tokenMinus, "-"}, {tokenNumber, "4.5"}})
+	tokens, _ := Tokens(Lex("test", "-44"))
+	if tokens[1].Literal != int64(44) {
+		t.Errorf("literal %#v, want 44: the minus is not part of the number", tokens[1].Literal)
+	}
+}
+
+// span Code and bytes of a diagnostic, as the tests expect them.
+type span struct {
+	code       string
+	start, end int
+}
+
+// checkDiagnostics Compares the code and the span of the diagnostics, in order.
+func checkDiagnostics(t *testing.T, input string, errs []Diagnostic, want []span) {
+	t.Helper()
+	var got []span
+	for _, d := range errs {
+		got = append(got, span{d.Code, d.Span.Start, d.Span.End})
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("%q: got diagnostics %v, want %v", input, errs, want)
+	}
+}
+
+func TestEscapes(t *testing.T) {
+	tests := []struct {
+		input   string
+		typ     tokenType
+		literal string
+		errs    []span
+	}{
+		{`"a\nb\t\\\"\'"`, tokenString, "a\nb\t\\\"'", nil},
+		{`'\n'`, tokenChar, "\n", nil},
+		{`'\''`, tokenChar, "'", nil},
+		{`"\u00e9\U0001F600"`, tokenString, "é😀", nil},
+		{`'\u00E9'`, tokenChar, "é", nil},
+		{`'\U0001F600'`, tokenChar, "😀", nil},
+		{`"\u00e"`, tokenString, "\uFFFD", []span{{CodeInvalidEscape, 1, 6}}},
+		{`"\u12 x"`, tokenString, "\uFFFD x", []span{{CodeInvalidEscape, 1, 5}}},
+		{`"\uzzzz"`, tokenString, "\uFFFDzzzz", []span{{CodeInvalidEscape, 1, 3}}},
+		{`"\U00110000"`, tokenString, "\uFFFD", []span{{CodeInvalidEscape, 1, 11}}}, // past the last code point
+		{`"\uD800"`, tokenString, "\uFFFD", []span{{CodeInvalidEscape, 1, 7}}},      // a surrogate
+		{`"a\qb"`, tokenString, "a\uFFFDb", []span{{CodeInvalidEscape, 2, 4}}},
+		{`'\q'`, tokenChar, "\uFFFD", []span{{CodeInvalidEscape, 1, 3}}},
+		{`"\u00e9\x"`, tokenString, "é\uFFFD", []span{{CodeInvalidEscape, 7, 9}}},
+	}
+	for _, test := range tests {
+		tokens, errs := Tokens(Lex("test", test.input))
+		if len(tokens) != 2 || tokenTypes[tokens[0].Type] != test.typ || tokens[0].Value != test.input {
+			t.Errorf("%q: got tokens %v, want a single %s", test.input, tokens, test.typ)
+			continue
+		}
+		if tokens[0].Literal != test.literal {
+			t.Errorf("%q: literal %q, want %q", test.input, tokens[0].Literal, test.literal)
+		}
+		checkDiagnostics(t, test.input, errs, test.errs)
+	}
+}
+
+// TestUnterminated Strings and characters end with their line: the rest of the input is lexed
+// again.
+func TestUnterminated(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []lexed
+		errs  []span
+	}{
+		{`"abc`, []lexed{{tokenMalformedString, `"abc`}}, []span{{CodeMalformedString, 0, 4}}},
+		{"\"abc\nx", []lexed{{tokenMalformedString, `"abc`}, {tokenIdentifier, "x"}}, []span{{CodeMalformedString, 0, 4}}},
+		{"\"abc\r\nx", []lexed{{tokenMalformedString, `"abc`}, {tokenIdentifier, "x"}}, []span{{CodeMalformedString, 0, 4}}},
+		{"\"a\\\n", []lexed{{tokenMalformedString, `"a\`}}, []span{{CodeMalformedString, 0, 3}, {CodeInvalidEscape, 2, 3}}},
+		{`'a`, []lexed{{tokenMalformedChar, `'a`}}, []span{{CodeMalformedChar, 0, 2}}},
+		{`'`, []lexed{{tokenMalformedChar, `'`}}, []span{{CodeMalformedChar, 0, 1}}},
+		{"'\\n\n;", []lexed{{tokenMalformedChar, `'\n`}, {tokenSemicolon, ";"}}, []span{{CodeMalformedChar, 0, 3}}},
+		{`''`, []lexed{{tokenMalformedChar, `''`}}, []span{{CodeMalformedChar, 0, 2}}},
+		{`'ab'`, []lexed{{tokenMalformedChar, `'ab'`}}, []span{{CodeMalformedChar, 0, 4}}},
+	}
+	for _, test := range tests {
+		checkTokens(t, test.input, test.want)
+		_, errs := lexAll(test.input)
+		checkDiagnostics(t, test.input, errs, test.errs)
+	}
+}
+
+// TestMalformed A character no token starts with, or a lone & or |, is a token of its own with a
+// single diagnostic; lexing goes on after it.
+func TestMalformed(t *testing.T) {
+	tests := []struct {
+		input  string
+		want   lexed
+		column int
+		err    span
+	}{
+		{"a @ b", lexed{tokenInvalidChar, "@"}, 3, span{CodeInvalidChar, 2, 3}},
+		{"a $ b", lexed{tokenInvalidChar, "$"}, 3, span{CodeInvalidChar, 2, 3}},
+		{"a ? b", lexed{tokenInvalidChar, "?"}, 3, span{CodeInvalidChar, 2, 3}},
+		{"a ~ b", lexed{tokenInvalidChar, "~"}, 3, span{CodeInvalidChar, 2, 3}},
+		{"a # b", lexed{tokenInvalidChar, "#"}, 3, span{CodeInvalidChar, 2, 3}},
+		{"é € b", lexed{tokenInvalidChar, "€"}, 3, span{CodeInvalidChar, 3, 6}}, // columns count characters, spans bytes
+		{"é 😀 b", lexed{tokenInvalidChar, "😀"}, 3, span{CodeInvalidChar, 3, 7}},
+		{"a & b", lexed{tokenMalformedLogicalOp, "&"}, 3, span{CodeMalformedLogicalOp, 2, 3}},
+		{"a | b", lexed{tokenMalformedLogicalOp, "|"}, 3, span{CodeMalformedLogicalOp, 2, 3}},
+	}
+	for _, test := range tests {
+		first := strings.Fields(test.input)[0]
+		checkTokens(t, test.input, []lexed{{tokenIdentifier, first}, test.want, {tokenIdentifier, "b"}})
+		_, errs := lexAll(test.input)
+		checkDiagnostics(t, test.input, errs, []span{test.err})
+		if len(errs) == 1 && (errs[0].Line != 1 || errs[0].Column != test.column) {
+			t.Errorf("%q: diagnostic at %d:%d, want 1:%d", test.input, errs[0].Line, errs[0].Column, test.column)
+		}
+	}
+}
@@ -0,0 +1,470 @@
+package Compiler
+
+import (
+	"compiladores/Compiler/ast"
+	"sort"
+)
+
+// ====================================== SYMBOLS ======================================
+
+// SymbolKind Kind of a declared name.
+type SymbolKind int
+
+const (
+	VarSymbol SymbolKind = iota
+	ConstSymbol
+	ParamSymbol
+	FieldSymbol
+	RegisterSymbol
+	ProcedureSymbol
+	FunctionSymbol
+)
+
+var symbolKinds = [...]string{"variable", "constant", "parameter", "field", "register", "procedure", "function"}
+
+func (k SymbolKind) String() string {
+	return symbolKinds[k]
+}
+
+// Symbol A declared name.
+type Symbol struct {
+	Kind    SymbolKind
+	Name    string
+	Ident   *ast.Ident   // identifier of the declaration
+	Type    *ast.TypeRef // declared type, result type of functions, nil for registers and procedures
+	Decl    ast.Node     // *ast.ConstSpec, *ast.RegisterDecl, *ast.Procedure or *ast.Function, nil otherwise
+	Scope   *Scope       // scope the symbol is declared in
+	Members *Scope       // fields of a register, parameters of a procedure or function
+}
+
+// ====================================== SCOPES ======================================
+
+// ScopeKind Kind of a scope.
+type ScopeKind int
+
+const (
+	GlobalScope ScopeKind = iota
+	RegisterScope
+	ProcedureScope
+	FunctionScope
+	LocalScope
+)
+
+var scopeKinds = [...]string{"global", "register", "procedure", "function", "local"}
+
+func (k ScopeKind) String() string {
+	return scopeKinds[k]
+}
+
+// Scope Symbol table of a block. Lookups that fail are retried on the parent scope.
+type Scope struct {
+	Kind    ScopeKind
+	Name    string // program, register, procedure or function name ("main" for the local scope of main)
+	Parent  *Scope
+	Symbols []*Symbol // in declaration order
+	index   map[string]*Symbol
+}
+
+func newScope(kind ScopeKind, name string, parent *Scope) *Scope {
+	return &Scope{Kind: kind, Name: name, Parent: parent, index: make(map[string]*Symbol)}
+}
+
+// Lookup Returns the symbol declared with name in this scope only.
+func (s *Scope) Lookup(name string) *Symbol {
+	return s.index[name]
+}
+
+// LookupParent Returns the innermost symbol declared with name, starting at this scope.
+func (s *Scope) LookupParent(name string) *Symbol {
+	for ; s != nil; s = s.Parent {
+		if sym := s.index[name]; sym != nil {
+			return sym
+		}
+	}
+	return nil
+}
+
+// insert Declares sym, unless the name is already taken in which case the previous symbol is returned.
+func (s *Scope) insert(sym *Symbol) *Symbol {
+	if prev := s.index[sym.Name]; prev != nil {
+		return prev
+	}
+	sym.Scope = s
+	s.index[sym.Name] = sym
+	s.Symbols = append(s.Symbols, sym)
+	return nil
+}
+
+// ====================================== ANALYZER ======================================
+
+// Info Result of the semantic analysis.
+type Info struct {
+	Global    *Scope
+	Defs      map[*ast.Ident]*Symbol   // identifiers that declare a symbol
+	Uses      map[*ast.Ident]*Symbol   // identifiers that refer to a symbol
+	Registers map[*ast.TypeRef]*Symbol // types that refer to a register
+	Scopes    map[ast.Node]*Scope      // scopes opened by Program, RegisterDecl, Procedure, Function and Block
+
+	// Filled by TypeCheck.
+	Types    map[ast.Expr]*Type     // type of every checked expression
+	TypeRefs map[*ast.TypeRef]*Type // type denoted by every type name
+}
+
+// ObjectOf Returns the symbol an identifier declares or refers to, nil if unknown.
+func (info *Info) ObjectOf(id *ast.Ident) *Symbol {
+	if sym := info.Defs[id]; sym != nil {
+		return sym
+	}
+	return info.Uses[id]
+}
+
+// Analyzer Holds the state of the semantic analysis of a single program.
+type Analyzer struct {
+	program *ast.Program
+	info    *Info
+	pending map[*Symbol]bool // constants whose initializer was not analyzed yet
+	errors  []Diagnostic
+}
+
+// Semantic Constructor, prepares the semantic analysis of a parsed program.
+func Semantic(program *ast.Program) *Analyzer {
+	return &Analyzer{
+		program: program,
+		info: &Info{
+			Defs:      make(map[*ast.Ident]*Symbol),
+			Uses:      make(map[*ast.Ident]*Symbol),
+			Registers: make(map[*ast.TypeRef]*Symbol),
+			Scopes:    make(map[ast.Node]*Scope),
+		},
+		pending: make(map[*Symbol]bool),
+	}
+}
+
+// Errors Returns the errors found by Analyze.
+func (a *Analyzer) Errors() []Diagnostic {
+	return a.errors
+}
+
+// errorf Reports a problem of the given kind about node n.
+func (a *Analyzer) errorf(code string, n ast.Node, format string, args ...interface{}) {
+	a.errors = append(a.errors, errorAt(a.program.File, n.Pos(), nodeEnd(n), code, format, args...))
+}
+
+// Analyze Builds the scopes of the program and resolves every identifier.
+// Procedures, functions and register types may be referred to before their declaration,
+// every other name must be declared first.
+func (a *Analyzer) Analyze() *Info {
+	program := a.program
+	global := newScope(GlobalScope, program.Name.Name, nil)
+	a.info.Global = global
+	a.info.Scopes[program] = global
+
+	for _, r := range program.Registers {
+		a.declare(global, &Symbol{Kind: RegisterSymbol, Name: r.Name.Name, Ident: r.Name, Decl: r,
+			Members: newScope(RegisterScope, r.Name.Name, global)})
+	}
+	for _, r := range program.Registers {
+		a.registerFields(r)
+	}
+
+	a.varDecls(global, program.Vars, nil)
+
+	var consts []*ast.ConstSpec
+	for _, decl := range program.Consts {
+		a.constType(decl.Type)
+		for _, spec := range decl.Specs {
+			sym := &Symbol{Kind: ConstSymbol, Name: spec.Name.Name, Ident: spec.Name, Type: decl.Type, Decl: spec}
+			if a.declare(global, sym) {
+				a.pending[sym] = true
+			}
+			consts = append(consts, spec)
+		}
+	}
+	for _, spec := range consts {
+		a.constValue(global, spec.Value)
+		delete(a.pending, a.info.Defs[spec.Name])
+	}
+
+	for _, proc := range program.Procedures {
+		params := a.params(ProcedureScope, proc.Name.Name, proc.Params)
+		a.info.Scopes[proc] = params
+		a.declare(global, &Symbol{Kind: ProcedureSymbol, Name: proc.Name.Name, Ident: proc.Name, Decl: proc, Members: params})
+	}
+	for _, fn := range program.Functions {
+		params := a.params(FunctionScope, fn.Name.Name, fn.Params)
+		a.info.Scopes[fn] = params
+		a.resolveType(fn.Result)
+		a.declare(global, &Symbol{Kind: FunctionSymbol, Name: fn.Name.Name, Ident: fn.Name, Type: fn.Result, Decl: fn, Members: params})
+	}
+
+	for _, proc := range program.Procedures {
+		a.block(a.info.Scopes[proc], proc.Name.Name, proc.Body)
+	}
+	for _, fn := range program.Functions {
+		local := a.block(a.info.Scopes[fn], fn.Name.Name, fn.Body)
+		a.value(local, fn.Return.Value)
+	}
+	if program.Main != nil {
+		a.block(global, mainKeyword, program.Main.Body)
+	}
+
+	sort.SliceStable(a.errors, func(i, j int) bool {
+		return a.errors[i].Span.Start < a.errors[j].Span.Start
+	})
+	return a.info
+}
+
+// declare Inserts sym in scope, reporting a redeclaration if the name is taken.
+func (a *Analyzer) declare(scope *Scope, sym *Symbol) bool {
+	if sym.Name == "_" { // placeholder left by a syntax error
+		return false
+	}
+	if prev := scope.insert(sym); prev != nil {
+		a.errorf(CodeRedeclaration, sym.Ident, "%s redeclared in this block (previous declaration as %s at line %s)", sym.Name, prev.Kind, prev.Ident.Pos())
+		return false
+	}
+	a.info.Defs[sym.Ident] = sym
+	return true
+}
+
+// ====================================== DECLARATIONS ======================================
+
+func isPrimitiveType(name string) bool {
+	switch name {
+	case integerKeyword, realKeyword, stringKeyword, booleanKeyword, charKeyword:
+		return true
+	}
+	return false
+}
+
+// resolveType <VarType>: a primitive type or the name of a register.
+func (a *Analyzer) resolveType(t *ast.TypeRef) {
+	if t == nil || t.Name == "_" || isPrimitiveType(t.Name) {
+		return
+	}
+	sym := a.info.Global.Lookup(t.Name)
+	switch {
+	case sym == nil:
+		a.errorf(CodeInvalidType, t, "unknown type %s", t.Name)
+	case sym.Kind != RegisterSymbol:
+		a.errorf(CodeInvalidType, t, "%s is not a register type", t.Name)
+	default:
+		a.info.Registers[t] = sym
+	}
+}
+
+// constType <ConstType>: constants and register fields only take primitive types.
+func (a *Analyzer) constType(t *ast.TypeRef) {
+	if t.Name != "_" && !isPrimitiveType(t.Name) {
+		a.errorf(CodeInvalidType, t, "%s is not a primitive type", t.Name)
+	}
+}
+
+func (a *Analyzer) registerFields(r *ast.RegisterDecl) {
+	sym := a.info.Defs[r.Name]
+	if sym == nil || sym.Decl != r { // redeclared register, its fields were not given a scope
+		return
+	}
+	a.info.Scopes[r] = sym.Members
+	for _, field := range r.Fields {
+		a.constType(field.Type)
+		for _, name := range field.Names {
+			a.declare(sym.Members, &Symbol{Kind: FieldSymbol, Name: name.Name, Ident: name, Type: field.Type})
+		}
+	}
+}
+
+// varDecls Declares variables in scope. Names already declared in outer (e.g. parameters) are reported too.
+func (a *Analyzer) varDecls(scope *Scope, decls []*ast.VarDecl, outer *Scope) {
+	for _, decl := range decls {
+		a.resolveType(decl.Type)
+		for _, name := range decl.Names {
+			if outer != nil {
+				if prev := outer.Lookup(name.Name); prev != nil {
+					a.errorf(CodeRedeclaration, name, "%s redeclared in this block (previous declaration as %s at line %s)", name.Name, prev.Kind, prev.Ident.Pos())
+					continue
+				}
+			}
+			a.declare(scope, &Symbol{Kind: VarSymbol, Name: name.Name, Ident: name, Type: decl.Type})
+		}
+	}
+}
+
+func (a *Analyzer) params(kind ScopeKind, name string, params []*ast.Param) *Scope {
+	scope := newScope(kind, name, a.info.Global)
+	for _, param := range params {
+		a.resolveType(param.Type)
+		a.declare(scope, &Symbol{Kind: ParamSymbol, Name: param.Name.Name, Ident: param.Name, Type: param.Type})
+	}
+	return scope
+}
+
+// constValue A constant is initialized by a literal or by a constant declared before it.
+func (a *Analyzer) constValue(scope *Scope, x ast.Expr) {
+	switch x := x.(type) {
+	case *ast.Ident:
+		if sym := a.use(scope, x); sym != nil && sym.Kind != ConstSymbol {
+			a.errorf(CodeNotConstant, x, "%s is not a constant", x.Name)
+		}
+	case *ast.FieldExpr:
+		if sym := a.value(scope, x); sym != nil {
+			a.errorf(CodeNotConstant, x, "%s.%s is not a constant", x.X.Name, x.Field.Name)
+		}
+	}
+}
+
+// block Opens the local scope of a procedure, function or main and resolves its commands.
+func (a *Analyzer) block(parent *Scope, name string, block *ast.Block) *Scope {
+	local := newScope(LocalScope, name, parent)
+	a.info.Scopes[block] = local
+	var outer *Scope
+	if parent.Kind != GlobalScope {
+		outer = parent
+	}
+	a.varDecls(local, block.Vars, outer)
+	a.stmts(local, block.Stmts)
+	return local
+}
+
+// ====================================== COMMANDS ======================================
+
+func (a *Analyzer) stmts(scope *Scope, list []ast.Stmt) {
+	for _, s := range list {
+		a.stmt(scope, s)
+	}
+}
+
+func (a *Analyzer) stmt(scope *Scope, s ast.Stmt) {
+	switch s := s.(type) {
+	case *ast.AssignStmt:
+		a.target(scope, s.Target)
+		a.value(scope, s.Value)
+	case *ast.IncDecStmt:
+		a.target(scope, s.X)
+	case *ast.CallStmt:
+		a.call(scope, s.Call, ProcedureSymbol)
+	case *ast.IfStmt:
+		if s.Cond != nil {
+			a.value(scope, s.Cond)
+		}
+		a.stmts(scope, s.Then.List)
+		if s.Else != nil {
+			a.stmts(scope, s.Else.List)
+		}
+	case *ast.WhileStmt:
+		if s.Cond != nil {
+			a.value(scope, s.Cond)
+		}
+		a.stmts(scope, s.Body.List)
+	case *ast.PrintStmt:
+		for _, x := range s.Args {
+			a.value(scope, x)
+		}
+	case *ast.ReadStmt:
+		for _, x := range s.Args {
+			a.target(scope, x)
+		}
+	case *ast.BlockStmt:
+		a.stmts(scope, s.List)
+	}
+}
+
+// ====================================== EXPRESSIONS ======================================
+
+// use Resolves an identifier, reporting undeclared names and constants used before their declaration.
+func (a *Analyzer) use(scope *Scope, id *ast.Ident) *Symbol {
+	if id.Name == "_" {
+		return nil
+	}
+	sym := scope.LookupParent(id.Name)
+	if sym == nil {
+		a.errorf(CodeUndeclared, id, "undeclared name: %s", id.Name)
+		return nil
+	}
+	a.info.Uses[id] = sym
+	if a.pending[sym] {
+		a.errorf(CodeUsedBeforeDecl, id, "%s used before its declaration at line %s", id.Name, sym.Ident.Pos())
+	}
+	return sym
+}
+
+// value Resolves an expression. For identifiers and fields the resolved symbol is returned.
+func (a *Analyzer) value(scope *Scope, x ast.Expr) *Symbol {
+	switch x := x.(type) {
+	case *ast.Ident:
+		sym := a.use(scope, x)
+		if sym != nil && sym.Kind != VarSymbol && sym.Kind != ConstSymbol && sym.Kind != ParamSymbol {
+			a.errorf(CodeNotValue, x, "%s %s is not a value", sym.Kind, x.Name)
+			return nil
+		}
+		return sym
+	case *ast.FieldExpr:
+		return a.field(scope, x)
+	case *ast.BinaryExpr:
+		a.value(scope, x.X)
+		a.value(scope, x.Y)
+	case *ast.UnaryExpr:
+		a.value(scope, x.X)
+	case *ast.IncDecExpr:
+		a.target(scope, x.X)
+	case *ast.CallExpr:
+		a.call(scope, x, FunctionSymbol)
+	}
+	return nil
+}
+
+// field Resolves "x.f": x must be a variable (or parameter) of a register type declaring f.
+func (a *Analyzer) field(scope *Scope, x *ast.FieldExpr) *Symbol {
+	base := a.value(scope, x.X)
+	if base == nil || base.Type == nil {
+		return nil
+	}
+	reg := a.info.Registers[base.Type]
+	if reg == nil {
+		if isPrimitiveType(base.Type.Name) {
+			a.errorf(CodeNotValue, x.X, "%s is not a register (type %s)", x.X.Name, base.Type.Name)
+		}
+		return nil
+	}
+	sym := reg.Members.Lookup(x.Field.Name)
+	if sym == nil {
+		a.errorf(CodeUnknownField, x.Field, "register %s has no field %s", reg.Name, x.Field.Name)
+		return nil
+	}
+	a.info.Uses[x.Field] = sym
+	return sym
+}
+
+// target Resolves the left side of an assignment, an increment or a read argument.
+func (a *Analyzer) target(scope *Scope, x ast.Expr) {
+	switch x := x.(type) {
+	case *ast.Ident:
+		sym := a.use(scope, x)
+		if sym == nil {
+			return
+		}
+		switch sym.Kind {
+		case ConstSymbol:
+			a.errorf(CodeInvalidAssign, x, "cannot assign to constant %s", x.Name)
+		case VarSymbol, ParamSymbol:
+		default:
+			a.errorf(CodeInvalidAssign, x, "cannot assign to %s %s", sym.Kind, x.Name)
+		}
+	case *ast.FieldExpr:
+		a.field(scope, x)
+	case *ast.BadExpr: // a syntax error, already reported
+	default:
+		a.value(scope, x)
+		a.errorf(CodeInvalidAssign, x, "cannot assign to expression")
+	}
+}
+
+// call Resolves a call to a procedure (kind ProcedureSymbol) or a function (kind FunctionSymbol).
+func (a *Analyzer) call(scope *Scope, call *ast.CallExpr, kind SymbolKind) {
+	if sym := a.use(scope, call.Fun); sym != nil && sym.Kind != kind {
+		a.errorf(CodeWrongSymbolKind, call.Fun, "%s %s is not a %s", sym.Kind, call.Fun.Name, kind)
+	}
+	for _, arg := range call.Args {
+		a.value(scope, arg)
+	}
+}
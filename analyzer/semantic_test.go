@@ -0,0 +1,108 @@
+package Compiler
+
+import (
+	"compiladores/Compiler/ast"
+	"testing"
+)
+
+const sample = `program Fat;
+var { integer n; }
+const { integer LIM = 10; real PI = 3.14159; }
+register Ponto { integer x; real y; string nome; }
+procedure Mostra (Ponto p) {
+	var { }
+	print("ponto ", p.nome, " x=", p.x, " y=", p.y);
+}
+function Fatorial (integer k) : integer {
+	var { integer r, t; }
+	r = 1;
+	if (k > 1) {
+		t = k - 1;
+		r = Fatorial(t);
+		r = r * k;
+	}
+	return r;
+}
+main {
+	var { integer i, f; Ponto a; boolean ok; }
+	i = 0;
+	while (i <= LIM) {
+		f = Fatorial(i);
+		print(i, "! = ", f);
+		i++;
+	}
+	a.x = 3; a.nome = "A";
+	Mostra(a);
+	ok = !ok;
+	read(n, a.y);
+}
+`
+
+// analyzeAll Runs the semantic analysis and the type checking on whatever tree the parser built.
+func analyzeAll(src string) (syntax, semantic []Diagnostic) {
+	parser := Syntax(Lex("test", src))
+	program := parser.Parse()
+	analyzer := Semantic(program)
+	info := analyzer.Analyze()
+	checker := TypeCheck(program, info)
+	checker.Check()
+	return append(parser.LexicalErrors(), parser.Errors()...), append(analyzer.Errors(), checker.Errors()...)
+}
+
+func TestAnalyzeSample(t *testing.T) {
+	syntax, semantic := analyzeAll(sample)
+	if len(syntax) > 0 || len(semantic) > 0 {
+		t.Errorf("got %v and %v, want no errors", syntax, semantic)
+	}
+}
+
+// TestAnalyzePrefixes Analyzes the program as typed so far, as the language server does on every
+// edit: the trees the parser recovers from syntax errors must not stop the analysis.
+func TestAnalyzePrefixes(t *testing.T) {
+	for i := range sample {
+		analyzeAll(sample[:i])
+	}
+}
+
+func TestAnalyzeBadExpr(t *testing.T) {
+	tests := []string{
+		"program P;\nvar { integer x; }\nconst { }\nmain {\n\tvar { }\n\tx = ;\n}\n",
+		"program P;\nvar { boolean b; }\nconst { }\nmain {\n\tvar { }\n\tif (b == ) { }\n\tprint(;);\n}\n",
+		"program P;\nvar { }\nconst { integer A = ; }\nmain {\n\tvar { }\n}\n",
+	}
+	for _, src := range tests {
+		syntax, semantic := analyzeAll(src)
+		if len(syntax) == 0 {
+			t.Errorf("%q: no syntax error", src)
+		}
+		if len(semantic) > 0 {
+			t.Errorf("%q: %v, want no semantic errors where the syntax is wrong", src, semantic)
+		}
+	}
+}
+
+// TestAnalyzeBadExprTree Analyzes a tree with the placeholder of syntax errors wherever an
+// expression may stand, and without the conditions the parser may leave out.
+func TestAnalyzeBadExprTree(t *testing.T) {
+	bad := &ast.BadExpr{}
+	program := &ast.Program{
+		Name: &ast.Ident{Name: "P"},
+		Main: &ast.Main{Body: &ast.Block{Stmts: []ast.Stmt{
+			&ast.AssignStmt{Target: bad, Value: bad},
+			&ast.IncDecStmt{X: bad, Op: "++"},
+			&ast.ReadStmt{Args: []ast.Expr{bad}},
+			&ast.PrintStmt{Args: []ast.Expr{bad, &ast.UnaryExpr{Op: "!", X: bad}}},
+			&ast.IfStmt{Cond: &ast.BinaryExpr{X: bad, Op: "+", Y: bad}, Then: &ast.BlockStmt{}},
+			&ast.IfStmt{Then: &ast.BlockStmt{}},
+			&ast.WhileStmt{Cond: bad, Body: &ast.BlockStmt{}},
+		}}},
+	}
+	analyzer := Semantic(program)
+	info := analyzer.Analyze()
+	checker := TypeCheck(program, info)
+	checker.Check()
+	errs := append(analyzer.Errors(), checker.Errors()...)
+	if len(errs) != 1 || errs[0].Code != CodeInvalidCondition { // the missing condition only
+		t.Errorf("got %v, want a single %s error", errs, CodeInvalidCondition)
+	}
+}
@@ -1,288 +1,1109 @@
 package Compiler
 
 import (
-	"fmt"
-	"os"
+	"compiladores/Compiler/ast"
+	"strconv"
+	"strings"
+	"unicode/utf8"
 )
 
-var tokens = make([]token, 0)
-var tokenIndex = -1
-
 // https://www.youtube.com/watch?v=tfIQzjUMKXA - 25:46
 
-// Constructor
-func Syntax(l *lexer) {
-	// todo: remove it later
-	tempProgramToken := token{27, "\"program\"", 0}
-	tokens = append(tokens, tempProgramToken)
-	// ================================================
+// Parser Holds the state of the syntax analysis of a single input.
+type Parser struct {
+	file       string       // name of the source, for diagnostics
+	tokens     []token      // every token delivered by the lexer
+	tokenIndex int          // index of the last consumed token
+	errors     []Diagnostic // errors found so far
+	lexErrors  []Diagnostic // malformed tokens
+	panicking  bool         // an error was reported and the parser did not resynchronize yet
+
+	syntax []*SyntaxToken // tokens with their trivia, parallel to tokens
+	nodes  []*SyntaxNode  // nodes of the concrete tree being built, innermost last
+}
+
+// Syntax Constructor, reads every token left in the lexer.
+func Syntax(l *lexer) *Parser {
+	p := &Parser{file: l.name, tokens: make([]token, 0), tokenIndex: -1}
+
+	all := l.rest()
+	for _, t := range all {
+		if t.typ == tokenBlockComment || t.typ == tokenLineComment { // Comments are not part of the grammar.
+			continue
+		}
+		p.tokens = append(p.tokens, t)
+	}
+	p.lexErrors = l.lexicalErrors(all)
+	p.syntax = syntaxTokens(l.input, all)
+	return p
+}
+
+// LexicalErrors Returns the malformed tokens delivered by the lexer.
+func (p *Parser) LexicalErrors() []Diagnostic {
+	return p.lexErrors
+}
+
+// Parse Parses the tokens into a syntax tree. Errors are collected rather than stopping the analysis.
+func (p *Parser) Parse() *ast.Program {
+	p.nodes = []*SyntaxNode{{Rule: "Start"}}
+	program := p.start()
+	for i := p.tokenIndex + 1; i < len(p.syntax); i++ { // the tokens left after an error, and the end of file
+		p.nodes[0].Children = append(p.nodes[0].Children, p.syntax[i])
+	}
+	return program
+}
+
+// Tree Returns the concrete syntax tree built by Parse, which holds every token and every byte of
+// trivia of the source: its text is the source.
+func (p *Parser) Tree() *SyntaxNode {
+	return p.nodes[0]
+}
+
+// Errors Returns the syntax errors found by Parse.
+func (p *Parser) Errors() []Diagnostic {
+	return p.errors
+}
 
-	for t := range l.tokens {
-		tokens = append(tokens, t)
+func (p *Parser) nextToken() {
+	if p.tokenIndex < len(p.tokens) {
+		p.tokenIndex++
 	}
+	if p.tokenIndex < len(p.syntax) {
+		node := p.nodes[len(p.nodes)-1]
+		node.Children = append(node.Children, p.syntax[p.tokenIndex])
+	}
+}
 
-	fmt.Println(tokens)
-	fmt.Println("")
+// lookAhead Token index positions after the last consumed one. There is always one, the lexer ends
+// every input with the end of file.
+func (p *Parser) lookAhead(index int) token {
+	if p.tokenIndex+index >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	} else if p.tokenIndex+index < 0 {
+		return p.tokens[0]
+	}
+	return p.tokens[p.tokenIndex+index]
+}
 
-	start()
+// verify Reports whether the next token is of the given kind, consuming it if next is set. Nothing
+// matches in panic mode.
+func (p *Parser) verify(kind tokenType, next bool) bool {
+	if p.panicking || p.lookAhead(1).typ != kind {
+		return false
+	}
+	if next {
+		p.nextToken()
+	}
+	return true
+}
+
+func (p *Parser) isEOF() bool {
+	return p.lookAhead(1).typ == tokenEOF
 }
 
-func nextToken() {
-	if tokenIndex < len(tokens) {
-		tokenIndex++
+// match Consumes the next token if it is of the given kind and returns it.
+// On error nothing is consumed and the returned token is of type tokenError, but still carries the
+// position of the offending token.
+func (p *Parser) match(kind tokenType) (token, bool) {
+	if p.verify(kind, true) {
+		return p.lookAhead(0), true
 	}
+	t := errorToken(p.lookAhead(1), "")
+	p.syntaxError(expected(kind))
+	return t, false
 }
 
-func lookAhead(index int) token {
-	if len(tokens) == 0 {
-		tempProgramToken := token{8, "EOF", 0}
-		return tempProgramToken
-	} else if tokenIndex+index >= len(tokens) {
-		return tokens[len(tokens)-1]
-	} else if tokenIndex+index < 0 {
-		return tokens[0]
+// syntaxError Reports that the next token is not the expected one, described as in "expected identifier",
+// and enters panic mode: nothing is matched nor reported until the parser resynchronizes on a token that
+// may follow the construct being parsed, see sync. Only the first error at a given token is reported, as
+// the enclosing constructs may fail on the token the parser resynchronized on as well.
+func (p *Parser) syntaxError(what string) {
+	found := p.lookAhead(1)
+	if p.panicking || len(p.errors) > 0 && p.errors[len(p.errors)-1].Span.Start == found.offset {
+		p.panicking = true
+		return
 	}
-	return tokens[tokenIndex+index]
+	p.errors = append(p.errors, errorAt(p.file, found.pos(), found.end(), CodeUnexpectedToken, "expected %s, found %s", what, describe(found)))
+	p.panicking = true
 }
 
-func compareType(t token, tp2 string) bool {
-	return t.typ == parseTokenTypeByString(tp2)
+// ====================================== RECOVERY ======================================
+
+// tokenSet A set of token kinds.
+type tokenSet map[tokenType]bool
+
+func newTokenSet(kinds ...tokenType) tokenSet {
+	s := make(tokenSet)
+	for _, kind := range kinds {
+		s[kind] = true
+	}
+	return s
 }
 
-func verifyToken(tokenType string, tokenValue string, next bool) bool {
-	if compareType(lookAhead(1), tokenType) && (tokenValue == "{any token value here}" || lookAhead(1).val == tokenValue || lookAhead(1).typ == 8) {
-		if next {
-			nextToken()
+// union Returns a new set with the tokens of s and of every other set.
+func (s tokenSet) union(others ...tokenSet) tokenSet {
+	u := make(tokenSet)
+	for _, set := range append([]tokenSet{s}, others...) {
+		for t := range set {
+			u[t] = true
 		}
-		return true
 	}
-	return false
+	return u
 }
 
-func verifyDelimiter(tokenValue string, next bool) bool {
-	return verifyToken("tokenDelimiter", tokenValue, next)
+func (s tokenSet) has(t token) bool {
+	return s[t.typ]
 }
 
-func match(tokenTpy string, lookAheadSync int) bool {
-	if compareType(lookAhead(1), tokenTpy) {
-		fmt.Println("Match", lookAhead(1))
-		nextToken()
+// FIRST sets of <VarType>, of the global sections and of the commands.
+var (
+	firstVarType = newTokenSet(tokenIntegerKeyword, tokenStringKeyword, tokenRealKeyword, tokenBooleanKeyword, tokenCharKeyword, tokenIdentifier)
+	firstSection = newTokenSet(tokenVarKeyword, tokenConstKeyword, tokenRegisterKeyword, tokenProcedureKeyword, tokenFunctionKeyword, tokenMainKeyword)
+	firstCommand = newTokenSet(tokenIfKeyword, tokenPrintKeyword, tokenReadKeyword, tokenWhileKeyword, tokenIdentifier)
+)
+
+// Synchronization sets: what may follow a nonterminal in the grammar (its FOLLOW set), and the starts of the
+// sections around it so that a missing '}' does not swallow the rest of the program. Identifiers are left
+// out, as an identifier is as likely to be the rest of the broken construct as the start of the next one.
+var (
+	// <VarDeclaration>, <ConstDeclaration> and <RegisterDeclaration>, the commands after the variables of a block.
+	syncDeclaration = newTokenSet(tokenIntegerKeyword, tokenStringKeyword, tokenRealKeyword, tokenBooleanKeyword, tokenCharKeyword, tokenRightBrace,
+		tokenIfKeyword, tokenPrintKeyword, tokenReadKeyword, tokenWhileKeyword).union(firstSection)
+	// The section keywords and the '{' that follows them.
+	syncSection = newTokenSet(tokenLeftBrace).union(firstSection)
+	// The commands of <LocalCommands>.
+	syncCommand = newTokenSet(tokenIfKeyword, tokenPrintKeyword, tokenReadKeyword, tokenWhileKeyword, tokenReturnKeyword, tokenRightBrace).union(firstSection)
+	// <AssignExpr> in if and while commands.
+	syncCondition = newTokenSet(tokenRightParen, tokenLeftBrace).union(syncCommand)
+	// The '{' opening the commands of if, else and while.
+	syncBlock = newTokenSet(tokenLeftBrace).union(syncCommand)
+)
+
+// sync Ends panic mode: skips tokens until one of follow or the end of file. Blocks are skipped as a
+// whole, as the tokens inside them, braces included, do not belong to the construct being recovered.
+func (p *Parser) sync(follow tokenSet) {
+	if !p.panicking {
+		return
+	}
+	for depth := 0; !p.isEOF() && (depth > 0 || !follow.has(p.lookAhead(1))); p.nextToken() {
+		if t := p.lookAhead(1); t.typ == tokenLeftBrace {
+			depth++
+		} else if t.typ == tokenRightBrace && depth > 0 {
+			depth--
+		}
+	}
+	p.panicking = false
+}
+
+// recover Like sync at the end of a construct ended by terminator: it also stops at the terminator,
+// which is consumed.
+func (p *Parser) recover(terminator tokenType, follow tokenSet) {
+	if !p.panicking {
+		return
+	}
+	p.sync(follow.union(newTokenSet(terminator)))
+	p.verify(terminator, true)
+}
+
+// expect Reports whether the next token starts an item of a list, i.e. is in first. Otherwise it reports
+// the error and synchronizes on follow, reporting whether the list goes on.
+func (p *Parser) expect(what string, first, follow tokenSet) bool {
+	if !p.panicking && first.has(p.lookAhead(1)) {
 		return true
-	} else {
-		syntaxError(tokenTpy, lookAheadSync)
-		return false
 	}
+	p.syntaxError(what)
+	p.sync(follow)
+	return first.has(p.lookAhead(1))
+}
+
+// ====================================== NODES ======================================
+
+func position(t token) ast.Pos {
+	return t.pos()
+}
+
+// errorToken A token of type tokenError, with the given value, at the position of t.
+func errorToken(t token, val string) token {
+	return token{tokenError, val, t.line, t.col, t.offset, nil}
 }
 
-func syntaxError(tokenTpy string, lookAheadSync int) {
-	// todo: implement a better way or not
-	fmt.Println("Error na linha", lookAhead(0).line+1, "Esperando", lookAhead(0).val, "(", lookAhead(0).typ, ")", "porém foi recebido", "\""+lookAhead(1).val+"\"", "(", lookAhead(1).typ, ")")
-	for i := 0; i < lookAheadSync; i++ {
-		nextToken()
+// expected Describes a token kind in syntax errors: the quoted text of the kinds spelled in a single
+// way, as in `expected ";"`, or what the token stands for, as in "expected identifier".
+func expected(kind tokenType) string {
+	if text := tokenTable[kind].text; text != "" {
+		return strconv.Quote(text)
+	}
+	switch kind {
+	case tokenIdentifier:
+		return "identifier"
+	case tokenNumber:
+		return "number"
+	case tokenString:
+		return "string"
+	case tokenChar:
+		return "character"
+	case tokenEOF:
+		return "end of file"
 	}
+	return kind.String()
 }
 
-func debug(die bool, message ...string) {
-	fmt.Println()
-	fmt.Println("======================= [[ DEBUG ]] =======================")
-	fmt.Println()
-	if message != nil {
-		fmt.Println("Message", message)
+// describe Describes the token found where another was expected.
+func describe(t token) string {
+	if t.typ == tokenEOF {
+		return "end of file"
 	}
-	fmt.Println("Previus token is  ", "\""+lookAhead(-1).val+"\"", "=", types[lookAhead(1).typ], "(", lookAhead(1).typ, ")")
-	fmt.Println("Current token is  ", "\""+tokens[tokenIndex].val+"\"", "=", types[tokens[tokenIndex].typ], "(", tokens[tokenIndex].typ, ")")
-	fmt.Println("Next token is     ", "\""+lookAhead(1).val+"\"", "=", types[lookAhead(1).typ], "(", lookAhead(1).typ, ")")
-	fmt.Println()
-	fmt.Println("===================== [[ END DEBUG ]] =====================")
-	fmt.Println()
-	if die {
-		os.Exit(0)
+	return strconv.Quote(t.val)
+}
+
+// identifier Matches an Identifier. On error the returned identifier is named "_".
+func (p *Parser) identifier() *ast.Ident {
+	t, ok := p.match(tokenIdentifier)
+	if !ok {
+		return &ast.Ident{NamePos: position(t), Name: "_"}
 	}
+	return &ast.Ident{NamePos: position(t), Name: t.val}
+}
+
+// literal Builds a literal from an already matched Decimal, RealNumber, StringLiteral, Char or Boolean.
+func literal(t token) *ast.BasicLit {
+	lit := &ast.BasicLit{ValuePos: position(t), Value: t.val, Literal: t.lit}
+	switch t.typ {
+	case tokenNumber:
+		lit.Kind = ast.IntLit
+		if strings.ContainsAny(t.val, ".eE") {
+			lit.Kind = ast.RealLit
+		}
+	case tokenString:
+		lit.Kind = ast.StringLit
+	case tokenChar:
+		lit.Kind = ast.CharLit
+		lit.Literal = nil // unless the lexer decoded the character
+		if s, ok := t.lit.(string); ok {
+			lit.Literal, _ = utf8.DecodeRuneInString(s)
+		}
+	default:
+		lit.Kind = ast.BoolLit
+		lit.Literal = t.val == "true"
+	}
+	return lit
 }
 
 // <Start> ::= 'program' Identifier ';' <GlobalStatement>
-func start() {
-	match("programKeyword", 3)
-	match("tokenIdentifier", 2)
-	match("tokenDelimiter", 1)
-	globalStatement()
+func (p *Parser) start() *ast.Program {
+	t, _ := p.match(tokenProgramKeyword)
+	program := &ast.Program{File: p.file, Program: position(t)}
+	program.Name = p.identifier()
+	p.match(tokenSemicolon)
+	p.sync(firstSection)
+	p.globalStatement(program)
+	if !p.isEOF() {
+		p.syntaxError("end of file")
+	}
+	return program
 }
 
 // <GlobalStatement> ::= <VarStatement> <ConstStatement> <RegisterStatement><ProcedureStatement><FunctionStatement> <Main>
-func globalStatement() {
-	varStatement()
-	constStatement()
-	registerStatement()
-	// procedureStatement()
-	// functionStatement()
-	// theMain()
+func (p *Parser) globalStatement(program *ast.Program) {
+	defer p.node("GlobalStatement")()
+	program.Vars = p.varStatement()
+	program.Consts = p.constStatement()
+	program.Registers = p.registerStatement()
+	program.Procedures = p.procedureStatement()
+	program.Functions = p.functionStatement()
+	program.Main = p.theMain()
 }
 
 // ====================================== VAR ======================================
 
 // <VarStatement>::= 'var' '{' <VarList>
-func varStatement() {
-	match("tokenKeyword", 2)
-	match("tokenDelimiter", 1)
-	varList()
+func (p *Parser) varStatement() []*ast.VarDecl {
+	defer p.node("VarStatement")()
+	if !p.section(tokenVarKeyword) {
+		return nil
+	}
+	return p.varList()
+}
+
+// section Matches the keyword and the '{' starting a section, reporting whether its declarations follow.
+// A missing section is reported once and skipped.
+func (p *Parser) section(keyword tokenType) bool {
+	if _, ok := p.match(keyword); ok {
+		p.match(tokenLeftBrace)
+		return true
+	}
+	p.sync(syncSection)
+	if p.verify(keyword, true) { // the error was before the section
+		p.match(tokenLeftBrace)
+		return true
+	}
+	return p.verify(tokenLeftBrace, true)
 }
 
 // <VarList>::= <VarDeclaration> <VarList> | '}'
-func varList() {
-	if verifyDelimiter("}", true) { // }
-		return
+func (p *Parser) varList() []*ast.VarDecl {
+	defer p.node("VarList")()
+	if p.verify(tokenRightBrace, true) || p.isEOF() { // }
+		return nil
+	} else if p.isCommand() { // the '}' closing the variables of a block is missing
+		p.syntaxError(`"}"`)
+		p.sync(firstCommand)
+		return nil
+	} else if !p.expect(`type or "}"`, firstVarType, syncDeclaration) {
+		p.verify(tokenRightBrace, true)
+		return nil
 	} else {
-		varDeclaration()
-		varList()
+		decl := p.varDeclaration()
+		p.recover(tokenSemicolon, syncDeclaration)
+		return append([]*ast.VarDecl{decl}, p.varList()...)
 	}
 }
 
 // <VarDeclaration>::= <VarType> Identifier <VarDeclaration1>
-func varDeclaration() {
-	varType()
-	match("tokenIdentifier", 1)
-	varDeclaration1()
+func (p *Parser) varDeclaration() *ast.VarDecl {
+	defer p.node("VarDeclaration")()
+	decl := &ast.VarDecl{Type: p.varType()}
+	decl.Names = append(decl.Names, p.identifier())
+	decl.Names = append(decl.Names, p.varDeclaration1()...)
+	return decl
 }
 
 // <VarDeclaration1>::= ',' Identifier <VarDeclaration1> | ';'
-func varDeclaration1() {
-	if verifyDelimiter(";", true) { // ;
-		return
+func (p *Parser) varDeclaration1() []*ast.Ident {
+	defer p.node("VarDeclaration1")()
+	if p.verify(tokenSemicolon, true) || p.isEOF() { // ;
+		return nil
+	} else if !p.verify(tokenComma, true) {
+		p.syntaxError(`"," or ";"`)
+		return nil
 	} else {
-		match("tokenDelimiter", 2)
-		match("tokenIdentifier", 1)
-		varDeclaration1()
+		name := p.identifier()
+		return append([]*ast.Ident{name}, p.varDeclaration1()...)
 	}
 }
 
+// isCommand Reports whether the next tokens start an assignment or a call rather than a <VarDeclaration>,
+// both may start with an identifier.
+func (p *Parser) isCommand() bool {
+	if p.panicking || p.lookAhead(1).typ != tokenIdentifier {
+		return false
+	}
+	switch p.lookAhead(2).typ {
+	case tokenAssign, tokenLeftParen, tokenDot, tokenIncrement, tokenDecrement:
+		return true
+	}
+	return false
+}
+
 // <VarType>::= 'integer' | 'string' | 'real' | 'boolean' | 'char' | Identifier
-func varType() {
-	if compareType(lookAhead(1), "tokenKeyword") {
-		match("tokenKeyword", 1)
-	} else if compareType(lookAhead(1), "tokenIdentifier") {
-		match("tokenIdentifier", 1)
+func (p *Parser) varType() *ast.TypeRef {
+	defer p.node("VarType")()
+	var t token
+	if !p.panicking && firstVarType.has(p.lookAhead(1)) {
+		t = p.lookAhead(1)
+		p.nextToken()
+	} else {
+		t = errorToken(p.lookAhead(1), "_")
+		p.syntaxError("type")
 	}
+	return &ast.TypeRef{NamePos: position(t), Name: t.val}
 }
 
 // ====================================== CONST ======================================
 
 // <ConstStatement> ::= 'const' '{' <ConstList>
-func constStatement() {
-	match("tokenKeyword", 2)
-	match("tokenDelimiter", 1)
-	constList()
+func (p *Parser) constStatement() []*ast.ConstDecl {
+	defer p.node("ConstStatement")()
+	if !p.section(tokenConstKeyword) {
+		return nil
+	}
+	return p.constList()
 }
 
 // <ConstList>::= <ConstDeclaration> <ConstList> | '}'
-func constList() {
-	if verifyDelimiter("}", true) { // }
-		return
+func (p *Parser) constList() []*ast.ConstDecl {
+	defer p.node("ConstList")()
+	if p.verify(tokenRightBrace, true) || p.isEOF() { // }
+		return nil
+	} else if !p.expect(`type or "}"`, firstVarType, syncDeclaration) {
+		p.verify(tokenRightBrace, true)
+		return nil
 	} else {
-		constDeclaration()
-		constList()
+		decl := p.constDeclaration()
+		p.recover(tokenSemicolon, syncDeclaration)
+		return append([]*ast.ConstDecl{decl}, p.constList()...)
 	}
 }
 
 // <ConstDeclaration> ::= <ConstType> Identifier '=' <Value> <ConstDeclaration1>
-func constDeclaration() {
-	varType()
-	match("tokenIdentifier", 1)
-	match("tokenArithmeticOp", 1)
-
-	//match("tokenNumber", 1) // value
-	value()
-
-	constDeclaration1()
+func (p *Parser) constDeclaration() *ast.ConstDecl {
+	defer p.node("ConstDeclaration")()
+	decl := &ast.ConstDecl{Type: p.varType()}
+	spec := &ast.ConstSpec{Name: p.identifier()}
+	p.match(tokenAssign)
+	spec.Value = p.value()
+	decl.Specs = append(decl.Specs, spec)
+	decl.Specs = append(decl.Specs, p.constDeclaration1()...)
+	return decl
 }
 
 // <ConstDeclaration1> ::= ',' Identifier  '=' <Value> <ConstDeclaration1> | ';'
-func constDeclaration1() {
-	if verifyDelimiter(";", true) { // ;
-		return
+func (p *Parser) constDeclaration1() []*ast.ConstSpec {
+	defer p.node("ConstDeclaration1")()
+	if p.verify(tokenSemicolon, true) || p.isEOF() { // ;
+		return nil
+	} else if !p.verify(tokenComma, true) {
+		p.syntaxError(`"," or ";"`)
+		return nil
 	} else {
-		match("tokenDelimiter", 2)
-		match("tokenIdentifier", 1)
-		constDeclaration1()
+		spec := &ast.ConstSpec{Name: p.identifier()}
+		p.match(tokenAssign)
+		spec.Value = p.value()
+		return append([]*ast.ConstSpec{spec}, p.constDeclaration1()...)
 	}
 }
 
 // <Value>  ::= Decimal | RealNumber | StringLiteral | Identifier <ValueRegister> | Char | Boolean
-func value() {
-	if verifyToken("tokenNumber", "{any token value here}", true) {
-		return
-	} else if verifyToken("tokenString", "{any token value here}", true) {
-		return
-	} else if verifyToken("tokenIdentifier", "{any token value here}", true) {
-		valueRegister()
-	} else if verifyToken("tokenChar", "{any token value here}", true) {
-		return
-	} else if verifyToken("tokenKeyword", "{any token value here}", true) {
-		return
+func (p *Parser) value() ast.Expr {
+	defer p.node("Value")()
+	t := p.lookAhead(1)
+	if p.verify(tokenNumber, true) {
+		return literal(t)
+	} else if p.verify(tokenString, true) {
+		return literal(t)
+	} else if p.verify(tokenIdentifier, true) {
+		return p.valueRegister(&ast.Ident{NamePos: position(t), Name: t.val})
+	} else if p.verify(tokenChar, true) {
+		return literal(t)
+	} else if p.verify(tokenTrueKeyword, true) || p.verify(tokenFalseKeyword, true) {
+		return literal(t)
+	} else {
+		p.syntaxError("value")
+		return &ast.BadExpr{From: position(t)}
 	}
 }
 
 // <ValueRegister> ::= '.' Identifier |
-func valueRegister() {
-	if verifyDelimiter(".", false) {
-		match("tokenDelimiter", 2)
-		match("tokenIdentifier", 1)
+func (p *Parser) valueRegister(x *ast.Ident) ast.Expr {
+	defer p.node("ValueRegister")()
+	if p.verify(tokenDot, true) {
+		return &ast.FieldExpr{X: x, Field: p.identifier()}
 	}
+	return x
 }
 
 // ====================================== REGISTER ======================================
 
 // <RegisterStatementMultiple> ::= <RegisterStatement> |
-func registerStatementMultiple() {
-	if lookAhead(1).val == "register" {
-		registerStatement()
+func (p *Parser) registerStatementMultiple() []*ast.RegisterDecl {
+	defer p.node("RegisterStatementMultiple")()
+	if p.verify(tokenRegisterKeyword, false) {
+		return p.registerStatement()
 	}
+	return nil
 }
 
 // <RegisterStatement> ::= 'register' Identifier '{' <RegisterList>
-func registerStatement() {
-	if lookAhead(1).val == "register" && match("tokenKeyword", 1) {
-		match("tokenIdentifier", 2)
-		match("tokenDelimiter", 1)
-		registerList()
+func (p *Parser) registerStatement() []*ast.RegisterDecl {
+	defer p.node("RegisterStatement")()
+	if p.verify(tokenRegisterKeyword, false) {
+		t, _ := p.match(tokenRegisterKeyword)
+		decl := &ast.RegisterDecl{Register: position(t)}
+		decl.Name = p.identifier()
+		p.sync(syncSection)
+		p.match(tokenLeftBrace)
+		return append([]*ast.RegisterDecl{decl}, p.registerList(decl)...)
 	}
+	return nil
 }
 
 // <RegisterList> ::= <RegisterDeclaration> <RegisterList1>  | '}'
-func registerList() {
-	if compareType(lookAhead(1), "tokenDelimiter") && lookAhead(1).val == "}" { // }
-		nextToken()
-	} else {
-		registerDeclaration()
-		registerList1()
-	}
+// The fields are added to decl, the registers declared after it are returned.
+func (p *Parser) registerList(decl *ast.RegisterDecl) []*ast.RegisterDecl {
+	defer p.node("RegisterList")()
+	return p.registerList1(decl)
 }
 
 // <RegisterList1> ::= <RegisterDeclaration> <RegisterList1> | '}' <RegisterStatementMultiple>
-func registerList1() {
-	if verifyDelimiter("}", false) { // ;
-		registerStatementMultiple()
+func (p *Parser) registerList1(decl *ast.RegisterDecl) []*ast.RegisterDecl {
+	defer p.node("RegisterList1")()
+	if p.verify(tokenRightBrace, true) { // }
+		decl.Rbrace = position(p.lookAhead(0))
+		return p.registerStatementMultiple()
+	} else if p.isEOF() {
+		return p.registerStatementMultiple()
+	} else if !p.expect(`type or "}"`, firstVarType, syncDeclaration) {
+		if p.verify(tokenRightBrace, true) {
+			decl.Rbrace = position(p.lookAhead(0))
+		}
+		return p.registerStatementMultiple()
 	} else {
-		registerDeclaration()
-		registerList1()
+		decl.Fields = append(decl.Fields, p.registerDeclaration())
+		p.recover(tokenSemicolon, syncDeclaration)
+		return p.registerList1(decl)
 	}
 }
 
 // <RegisterDeclaration> ::= <ConstType> Identifier <RegisterDeclaration1>
-func registerDeclaration() {
-	varType()
-	match("tokenIdentifier", 1)
-	registerDeclaration1()
+func (p *Parser) registerDeclaration() *ast.VarDecl {
+	defer p.node("RegisterDeclaration")()
+	field := &ast.VarDecl{Type: p.varType()}
+	field.Names = append(field.Names, p.identifier())
+	field.Names = append(field.Names, p.registerDeclaration1()...)
+	return field
 }
 
 // <RegisterDeclaration1> ::= ',' Identifier <RegisterDeclaration1> | ';'
-func registerDeclaration1() {
-	if verifyDelimiter(";", true) { // ;
-		return
+func (p *Parser) registerDeclaration1() []*ast.Ident {
+	defer p.node("RegisterDeclaration1")()
+	if p.verify(tokenSemicolon, true) || p.isEOF() { // ;
+		return nil
+	} else if !p.verify(tokenComma, true) {
+		p.syntaxError(`"," or ";"`)
+		return nil
 	} else {
-		match("tokenDelimiter", 2)
-		match("tokenIdentifier", 1)
-		registerDeclaration1()
+		name := p.identifier()
+		return append([]*ast.Ident{name}, p.registerDeclaration1()...)
 	}
 }
 
 // ====================================== PROCEDURE ======================================
+
+// <ProcedureStatement> ::= 'procedure' Identifier '(' <ParameterProcedure> '{' <LocalStatement> <ProcedureStatement1> |
+func (p *Parser) procedureStatement() []*ast.Procedure {
+	defer p.node("ProcedureStatement")()
+	if p.verify(tokenProcedureKeyword, false) {
+		t, _ := p.match(tokenProcedureKeyword)
+		proc := &ast.Procedure{Procedure: position(t)}
+		proc.Name = p.identifier()
+		p.match(tokenLeftParen)
+		proc.Params = p.parameterProcedure()
+		p.sync(syncSection)
+		lbrace, _ := p.match(tokenLeftBrace)
+		proc.Body = p.localStatement(lbrace)
+		return append([]*ast.Procedure{proc}, p.procedureStatement1(proc.Body)...)
+	}
+	return nil
+}
+
+// <ProcedureStatement1> ::= '}' | '}' 'procedure' Identifier '(' <ParameterProcedure> '{' <LocalStatement>  <ProcedureStatement1>
+func (p *Parser) procedureStatement1(body *ast.Block) []*ast.Procedure {
+	defer p.node("ProcedureStatement1")()
+	rbrace, _ := p.match(tokenRightBrace)
+	body.Rbrace = position(rbrace)
+	return p.procedureStatement()
+}
+
+// <ParameterProcedure> ::= <VarType> Identifier <ParameterListProcedure> | ')'
+func (p *Parser) parameterProcedure() []*ast.Param {
+	defer p.node("ParameterProcedure")()
+	if p.verify(tokenRightParen, true) || p.isEOF() { // )
+		return nil
+	}
+	param := &ast.Param{Type: p.varType()}
+	param.Name = p.identifier()
+	return append([]*ast.Param{param}, p.parameterListProcedure()...)
+}
+
+// <ParameterListProcedure> ::=   ',' <ParameterProcedure> |  ')'
+func (p *Parser) parameterListProcedure() []*ast.Param {
+	defer p.node("ParameterListProcedure")()
+	if p.verify(tokenComma, true) { // ,
+		return p.parameterProcedure()
+	} else if !p.verify(tokenRightParen, true) {
+		p.syntaxError(`"," or ")"`)
+	}
+	return nil
+}
+
+// ====================================== FUNCTION ======================================
+
+// <FunctionStatement>::= 'function' Identifier  '(' <ParameterFunction> '{' <LocalStatement> 'return' <Value>';' <FunctionStatement1> |
+func (p *Parser) functionStatement() []*ast.Function {
+	defer p.node("FunctionStatement")()
+	if p.verify(tokenFunctionKeyword, false) {
+		t, _ := p.match(tokenFunctionKeyword)
+		fn := &ast.Function{Function: position(t)}
+		fn.Name = p.identifier()
+		p.match(tokenLeftParen)
+		fn.Params, fn.Result = p.parameterFunction()
+		p.sync(syncSection)
+		lbrace, _ := p.match(tokenLeftBrace)
+		fn.Body = p.localStatement(lbrace)
+		t, _ = p.match(tokenReturnKeyword)
+		fn.Return = &ast.ReturnStmt{Return: position(t), Value: p.value()}
+		p.match(tokenSemicolon)
+		p.recover(tokenSemicolon, syncCommand)
+		return append([]*ast.Function{fn}, p.functionStatement1(fn.Body)...)
+	}
+	return nil
+}
+
+// <FunctionStatement1>::= '}' | '}' 'function' Identifier  '(' <ParameterFunction>  '{' <LocalStatement> 'return' <Value>';' <FunctionStatement1>
+func (p *Parser) functionStatement1(body *ast.Block) []*ast.Function {
+	defer p.node("FunctionStatement1")()
+	rbrace, _ := p.match(tokenRightBrace)
+	body.Rbrace = position(rbrace)
+	return p.functionStatement()
+}
+
+// <ParameterFunction> ::= <VarType> Identifier <ParameterListFunction> | ')' ':' <VarType>
+func (p *Parser) parameterFunction() ([]*ast.Param, *ast.TypeRef) {
+	defer p.node("ParameterFunction")()
+	if p.verify(tokenRightParen, true) || p.isEOF() { // )
+		p.match(tokenColon)
+		return nil, p.varType()
+	}
+	param := &ast.Param{Type: p.varType()}
+	param.Name = p.identifier()
+	params, result := p.parameterListFunction()
+	return append([]*ast.Param{param}, params...), result
+}
+
+// <ParameterListFunction> ::=   ',' <ParameterFunction> |  ')' ':' <VarType>
+func (p *Parser) parameterListFunction() ([]*ast.Param, *ast.TypeRef) {
+	defer p.node("ParameterListFunction")()
+	if p.verify(tokenComma, true) { // ,
+		return p.parameterFunction()
+	} else if !p.verify(tokenRightParen, true) {
+		p.syntaxError(`"," or ")"`)
+		return nil, &ast.TypeRef{NamePos: position(p.lookAhead(1)), Name: "_"}
+	} else {
+		p.match(tokenColon)
+		return nil, p.varType()
+	}
+}
+
+// ====================================== MAIN ======================================
+
+// <Main> ::= 'main' '{' <LocalStatement> '}'
+func (p *Parser) theMain() *ast.Main {
+	defer p.node("Main")()
+	t, _ := p.match(tokenMainKeyword)
+	m := &ast.Main{Main: position(t)}
+	p.sync(syncSection)
+	lbrace, _ := p.match(tokenLeftBrace)
+	m.Body = p.localStatement(lbrace)
+	rbrace, _ := p.match(tokenRightBrace)
+	m.Body.Rbrace = position(rbrace)
+	return m
+}
+
+// ====================================== BLOCKS ======================================
+
+// <LocalStatement> ::= <VarStatement> <LocalCommands>
+// lbrace is the already matched '{' opening the block, the closing '}' is left to the caller.
+func (p *Parser) localStatement(lbrace token) *ast.Block {
+	defer p.node("LocalStatement")()
+	block := &ast.Block{Lbrace: position(lbrace)}
+	block.Vars = p.varStatement()
+	block.Stmts = p.localCommands()
+	return block
+}
+
+// <LocalCommands> ::= <IfDecs> <LocalCommands> | <WriteDecs> <LocalCommands> | <ReadDecs> <LocalCommands> | <WhileDecs> <LocalCommands> | <Assigment> <LocalCommands> | <FunctionCall> <LocalCommands> | <ProcedureCall> <LocalCommands> |
+func (p *Parser) localCommands() []ast.Stmt {
+	defer p.node("LocalCommands")()
+	var stmt ast.Stmt
+	switch {
+	case p.verify(tokenIfKeyword, false):
+		stmt = p.ifDecs()
+	case p.verify(tokenPrintKeyword, false):
+		stmt = p.writeDecs()
+	case p.verify(tokenReadKeyword, false):
+		stmt = p.readDecs()
+	case p.verify(tokenWhileKeyword, false):
+		stmt = p.whileDecs()
+	case !p.panicking && p.lookAhead(1).typ == tokenIdentifier:
+		// Identifier '(' is a procedure call, Identifier '=' Identifier '(' a function call.
+		if p.lookAhead(2).typ == tokenLeftParen {
+			stmt = p.procedureCall()
+		} else if p.lookAhead(2).typ == tokenAssign && p.lookAhead(3).typ == tokenIdentifier && p.lookAhead(4).typ == tokenLeftParen {
+			stmt = p.functionCall()
+		} else {
+			stmt = p.assigment()
+		}
+	case p.verify(tokenRightBrace, false) || p.verify(tokenReturnKeyword, false) || p.isEOF():
+		return nil // FOLLOW(<LocalCommands>)
+	default:
+		if !p.expect("command", firstCommand, syncCommand) {
+			return nil
+		}
+		return p.localCommands()
+	}
+	p.recover(tokenSemicolon, syncCommand)
+	return append([]ast.Stmt{stmt}, p.localCommands()...)
+}
+
+// commandBlock '{' <LocalCommands> '}'
+func (p *Parser) commandBlock() *ast.BlockStmt {
+	p.sync(syncBlock)
+	lbrace, _ := p.match(tokenLeftBrace)
+	block := &ast.BlockStmt{Lbrace: position(lbrace)}
+	block.List = p.localCommands()
+	rbrace, _ := p.match(tokenRightBrace)
+	block.Rbrace = position(rbrace)
+	return block
+}
+
+// ====================================== ASSIGMENT ======================================
+
+// <Assigment> ::= Identifier <AssigmentRegister>
+func (p *Parser) assigment() ast.Stmt {
+	defer p.node("Assigment")()
+	return p.assigmentRegister(p.identifier())
+}
+
+// <AssigmentRegister> ::= '.' Identifier '=' <AssigmentOperators> ';' | '=' <AssigmentOperators> ';' | '++' ';' | '--' ';'
+func (p *Parser) assigmentRegister(x *ast.Ident) ast.Stmt {
+	defer p.node("AssigmentRegister")()
+	var stmt ast.Stmt
+	if t := p.lookAhead(1); p.verify(tokenDot, true) { // .
+		target := &ast.FieldExpr{X: x, Field: p.identifier()}
+		p.match(tokenAssign)
+		stmt = &ast.AssignStmt{Target: target, Value: p.assigmentOperators()}
+	} else if p.verify(tokenIncrement, true) || p.verify(tokenDecrement, true) {
+		stmt = &ast.IncDecStmt{X: x, TokPos: position(t), Op: t.val}
+	} else {
+		p.match(tokenAssign)
+		stmt = &ast.AssignStmt{Target: x, Value: p.assigmentOperators()}
+	}
+	p.match(tokenSemicolon)
+	return stmt
+}
+
+// <AssigmentOperators> ::= <Value> | <BinaryExpression> | <UnaryExpression>
+func (p *Parser) assigmentOperators() ast.Expr {
+	defer p.node("AssigmentOperators")()
+	if p.verify(tokenNot, false) {
+		return p.unaryExpression()
+	} else if isAddendOperator(p.lookAhead(1)) && isBinaryOperator(p.lookAhead(2)) {
+		return p.binaryExpression()
+	} else {
+		return p.value()
+	}
+}
+
+// ====================================== EXPRESSION ======================================
+
+// <BinaryExpression> ::= <AddendOperator> <BinaryExpressionContin>
+func (p *Parser) binaryExpression() ast.Expr {
+	defer p.node("BinaryExpression")()
+	return p.binaryExpressionContin(p.addendOperator())
+}
+
+// <BinaryExpressionContin> ::= '+' <AddendOperator> | '-' <AddendOperator> | '*' <AddendOperator> | '/' <AddendOperator> | '++' | '--' | <RelationalExpression> | <LogicalExpression>
+func (p *Parser) binaryExpressionContin(x ast.Expr) ast.Expr {
+	defer p.node("BinaryExpressionContin")()
+	switch t := p.lookAhead(1); {
+	case t.typ == tokenPlus || t.typ == tokenMinus || t.typ == tokenStar || t.typ == tokenSlash:
+		p.match(t.typ)
+		return &ast.BinaryExpr{X: x, OpPos: position(t), Op: t.val, Y: p.addendOperator()}
+	case t.typ == tokenIncrement || t.typ == tokenDecrement:
+		p.match(t.typ)
+		return &ast.IncDecExpr{X: x, OpPos: position(t), Op: t.val}
+	case isRelationalOperator(t):
+		return p.relationalExpression(x)
+	default:
+		return p.logicalExpression(x)
+	}
+}
+
+// <RelationalExpression> ::= '<' <AddendOperator> | '>' <AddendOperator> | '!=' <AddendOperator> | '<=' <AddendOperator> | '>=' <AddendOperator> | '==' <AddendOperator>
+func (p *Parser) relationalExpression(x ast.Expr) ast.Expr {
+	defer p.node("RelationalExpression")()
+	t := p.lookAhead(1)
+	if isRelationalOperator(t) {
+		p.match(t.typ)
+	} else {
+		p.syntaxError("relational operator")
+	}
+	return &ast.BinaryExpr{X: x, OpPos: position(t), Op: t.val, Y: p.addendOperator()}
+}
+
+// <LogicalExpression> ::= '||' <AddendOperator> | '&&' <AddendOperator>
+func (p *Parser) logicalExpression(x ast.Expr) ast.Expr {
+	defer p.node("LogicalExpression")()
+	t := p.lookAhead(1)
+	if p.verify(tokenOr, false) || p.verify(tokenAnd, false) {
+		p.match(t.typ)
+	} else {
+		p.syntaxError("logical operator")
+	}
+	return &ast.BinaryExpr{X: x, OpPos: position(t), Op: t.val, Y: p.addendOperator()}
+}
+
+// <AddendOperator> ::= Identifier | Decimal | RealNumber | Boolean
+func (p *Parser) addendOperator() ast.Expr {
+	defer p.node("AddendOperator")()
+	t := p.lookAhead(1)
+	if p.panicking || !isAddendOperator(t) {
+		p.syntaxError("identifier, number or boolean")
+		return &ast.BadExpr{From: position(t)}
+	}
+	p.nextToken()
+	if t.typ == tokenIdentifier {
+		return &ast.Ident{NamePos: position(t), Name: t.val}
+	}
+	return literal(t)
+}
+
+// <UnaryExpression> ::= '!' <AddendOperatorUnary>
+func (p *Parser) unaryExpression() ast.Expr {
+	defer p.node("UnaryExpression")()
+	t, _ := p.match(tokenNot)
+	return &ast.UnaryExpr{OpPos: position(t), Op: "!", X: p.addendOperatorUnary()}
+}
+
+// <AddendOperatorUnary> ::= Identifier | Boolean
+func (p *Parser) addendOperatorUnary() ast.Expr {
+	defer p.node("AddendOperatorUnary")()
+	t := p.lookAhead(1)
+	if p.verify(tokenIdentifier, true) {
+		return &ast.Ident{NamePos: position(t), Name: t.val}
+	} else if p.verify(tokenTrueKeyword, true) || p.verify(tokenFalseKeyword, true) {
+		return literal(t)
+	}
+	p.syntaxError("identifier or boolean")
+	return &ast.BadExpr{From: position(t)}
+}
+
+func isAddendOperator(t token) bool {
+	return t.typ == tokenIdentifier || t.typ == tokenNumber ||
+		t.typ == tokenTrueKeyword || t.typ == tokenFalseKeyword
+}
+
+func isRelationalOperator(t token) bool {
+	switch t.typ {
+	case tokenLess, tokenGreater, tokenNotEqual, tokenLessEqual, tokenGreaterEqual, tokenEqual:
+		return true
+	}
+	return false
+}
+
+func isBinaryOperator(t token) bool {
+	switch t.typ {
+	case tokenPlus, tokenMinus, tokenStar, tokenSlash, tokenIncrement, tokenDecrement, tokenOr, tokenAnd:
+		return true
+	}
+	return isRelationalOperator(t)
+}
+
+// ====================================== LOGICAL ======================================
+
+// <AssignExpr> ::= <LogicalOrExpression> |
+func (p *Parser) assignExpr() ast.Expr {
+	defer p.node("AssignExpr")()
+	if p.verify(tokenRightParen, false) {
+		return nil
+	}
+	return p.logicalOrExpression()
+}
+
+// <LogicalOrExpression> ::= <LogicalAndExpression> <LogicalOrExpression1>
+func (p *Parser) logicalOrExpression() ast.Expr {
+	defer p.node("LogicalOrExpression")()
+	return p.logicalOrExpression1(p.logicalAndExpression())
+}
+
+// <LogicalOrExpression1> ::= '||' <LogicalAndExpression> <LogicalOrExpression1> |
+func (p *Parser) logicalOrExpression1(x ast.Expr) ast.Expr {
+	defer p.node("LogicalOrExpression1")()
+	if t := p.lookAhead(1); p.verify(tokenOr, true) {
+		y := p.logicalAndExpression()
+		return p.logicalOrExpression1(&ast.BinaryExpr{X: x, OpPos: position(t), Op: t.val, Y: y})
+	}
+	return x
+}
+
+// <LogicalAndExpression> ::= <Condition> <LogicalAndExpression1>
+func (p *Parser) logicalAndExpression() ast.Expr {
+	defer p.node("LogicalAndExpression")()
+	return p.logicalAndExpression1(p.condition())
+}
+
+// <LogicalAndExpression1> ::= '&&' <Condition> <LogicalAndExpression1> |
+func (p *Parser) logicalAndExpression1(x ast.Expr) ast.Expr {
+	defer p.node("LogicalAndExpression1")()
+	if t := p.lookAhead(1); p.verify(tokenAnd, true) {
+		y := p.condition()
+		return p.logicalAndExpression1(&ast.BinaryExpr{X: x, OpPos: position(t), Op: t.val, Y: y})
+	}
+	return x
+}
+
+// <Condition> ::= <AddendOperator> <ConditionContin>
+func (p *Parser) condition() ast.Expr {
+	defer p.node("Condition")()
+	return p.conditionContin(p.addendOperator())
+}
+
+// <ConditionContin> ::= <RelationalExpression> | <LogicalExpression>
+func (p *Parser) conditionContin(x ast.Expr) ast.Expr {
+	defer p.node("ConditionContin")()
+	if isRelationalOperator(p.lookAhead(1)) {
+		return p.relationalExpression(x)
+	} else {
+		return p.logicalExpression(x)
+	}
+}
+
+// ====================================== CALLS ======================================
+
+// <FunctionCall> ::= Identifier '=' Identifier '(' <Argument> ')' ';'
+func (p *Parser) functionCall() ast.Stmt {
+	defer p.node("FunctionCall")()
+	target := p.identifier()
+	p.match(tokenAssign)
+	call := &ast.CallExpr{Fun: p.identifier()}
+	lparen, _ := p.match(tokenLeftParen)
+	call.Lparen = position(lparen)
+	call.Args = p.argument()
+	rparen, _ := p.match(tokenRightParen)
+	call.Rparen = position(rparen)
+	p.match(tokenSemicolon)
+	return &ast.AssignStmt{Target: target, Value: call}
+}
+
+// <ProcedureCall> ::= Identifier '(' <Argument> ')' ';'
+func (p *Parser) procedureCall() ast.Stmt {
+	defer p.node("ProcedureCall")()
+	call := &ast.CallExpr{Fun: p.identifier()}
+	lparen, _ := p.match(tokenLeftParen)
+	call.Lparen = position(lparen)
+	call.Args = p.argument()
+	rparen, _ := p.match(tokenRightParen)
+	call.Rparen = position(rparen)
+	p.match(tokenSemicolon)
+	return &ast.CallStmt{Call: call}
+}
+
+// <Argument> ::= <Value> <ArgumentList> |
+func (p *Parser) argument() []ast.Expr {
+	defer p.node("Argument")()
+	if p.verify(tokenRightParen, false) || p.isEOF() {
+		return nil
+	}
+	arg := p.value()
+	return append([]ast.Expr{arg}, p.argumentList()...)
+}
+
+// <ArgumentList> ::= ',' <Argument> |
+func (p *Parser) argumentList() []ast.Expr {
+	defer p.node("ArgumentList")()
+	if p.verify(tokenComma, true) { // ,
+		return p.argument()
+	}
+	return nil
+}
+
+// ====================================== IF / WHILE ======================================
+
+// <IfDecs> ::= 'if' '(' <AssignExpr> ')' '{' <LocalCommands> '}' <ElseDecs>
+func (p *Parser) ifDecs() ast.Stmt {
+	defer p.node("IfDecs")()
+	t, _ := p.match(tokenIfKeyword)
+	stmt := &ast.IfStmt{If: position(t)}
+	p.match(tokenLeftParen)
+	stmt.Cond = p.assignExpr()
+	p.sync(syncCondition)
+	p.match(tokenRightParen)
+	stmt.Then = p.commandBlock()
+	stmt.Else = p.elseDecs()
+	return stmt
+}
+
+// <ElseDecs>::= 'else' '{' <LocalCommands> '}' |
+func (p *Parser) elseDecs() *ast.BlockStmt {
+	defer p.node("ElseDecs")()
+	if p.verify(tokenElseKeyword, false) {
+		p.match(tokenElseKeyword)
+		return p.commandBlock()
+	}
+	return nil
+}
+
+// <WhileDecs>::= 'while' '('<AssignExpr>')' '{' <LocalCommands> '}'
+func (p *Parser) whileDecs() ast.Stmt {
+	defer p.node("WhileDecs")()
+	t, _ := p.match(tokenWhileKeyword)
+	stmt := &ast.WhileStmt{While: position(t)}
+	p.match(tokenLeftParen)
+	stmt.Cond = p.assignExpr()
+	p.sync(syncCondition)
+	p.match(tokenRightParen)
+	stmt.Body = p.commandBlock()
+	return stmt
+}
+
+// ====================================== PRINT / READ ======================================
+
+// <WriteDecs> ::= 'print' '(' <ArgumentsWrite>
+func (p *Parser) writeDecs() ast.Stmt {
+	defer p.node("WriteDecs")()
+	t, _ := p.match(tokenPrintKeyword)
+	p.match(tokenLeftParen)
+	return &ast.PrintStmt{Print: position(t), Args: p.argumentsWrite()}
+}
+
+// <ArgumentsWrite> ::= Identifier <RegisterWrite> <ListArgumentsWrite> | <WriteContent> <ListArgumentsWrite>
+func (p *Parser) argumentsWrite() []ast.Expr {
+	defer p.node("ArgumentsWrite")()
+	var arg ast.Expr
+	if t := p.lookAhead(1); p.verify(tokenIdentifier, true) {
+		arg = p.valueRegister(&ast.Ident{NamePos: position(t), Name: t.val}) // <RegisterWrite> ::= '.' Identifier |
+	} else {
+		arg = p.writeContent()
+	}
+	return append([]ast.Expr{arg}, p.listArgumentsWrite()...)
+}
+
+// <WriteContent> ::= Decimal | RealNumber | StringLiteral
+func (p *Parser) writeContent() ast.Expr {
+	defer p.node("WriteContent")()
+	t := p.lookAhead(1)
+	if p.verify(tokenNumber, true) {
+		return literal(t)
+	} else if p.verify(tokenString, true) {
+		return literal(t)
+	}
+	p.syntaxError("number or string")
+	return &ast.BadExpr{From: position(t)}
+}
+
+// <ListArgumentsWrite> ::= ',' <ArgumentsWrite> | ')' ';'
+func (p *Parser) listArgumentsWrite() []ast.Expr {
+	defer p.node("ListArgumentsWrite")()
+	if p.verify(tokenComma, true) { // ,
+		return p.argumentsWrite()
+	}
+	p.match(tokenRightParen)
+	p.match(tokenSemicolon)
+	return nil
+}
+
+// <ReadDecs> ::= 'read' '(' <ArgumentsRead>
+func (p *Parser) readDecs() ast.Stmt {
+	defer p.node("ReadDecs")()
+	t, _ := p.match(tokenReadKeyword)
+	p.match(tokenLeftParen)
+	return &ast.ReadStmt{Read: position(t), Args: p.argumentsRead()}
+}
+
+// <ArgumentsRead> ::= Identifier <RegisterRead> <ListArgumentsRead>
+func (p *Parser) argumentsRead() []ast.Expr {
+	defer p.node("ArgumentsRead")()
+	arg := p.valueRegister(p.identifier()) // <RegisterRead> ::= '.' Identifier |
+	return append([]ast.Expr{arg}, p.listArgumentsRead()...)
+}
+
+// <ListArgumentsRead> ::= ',' <ArgumentsRead> | ')' ';'
+func (p *Parser) listArgumentsRead() []ast.Expr {
+	defer p.node("ListArgumentsRead")()
+	if p.verify(tokenComma, true) { // ,
+		return p.argumentsRead()
+	}
+	p.match(tokenRightParen)
+	p.match(tokenSemicolon)
+	return nil
+}
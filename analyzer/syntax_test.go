@@ -0,0 +1,91 @@
+package Compiler
+
+import (
+	"compiladores/Compiler/ast"
+	"strings"
+	"testing"
+)
+
+func TestLiteralValues(t *testing.T) {
+	src := `program P;
+const {
+	integer A = 007;
+	real B = 2.5E-3;
+	string C = "a\tb";
+	char D = 'é';
+	boolean E = true;
+	boolean F = false;
+}
+main { var { } }
+`
+	want := []interface{}{int64(7), 0.0025, "a\tb", 'é', true, false}
+	var got []interface{}
+	ast.Inspect(Syntax(Lex("test", src)).Parse(), func(n ast.Node) bool {
+		if lit, ok := n.(*ast.BasicLit); ok {
+			got = append(got, lit.Literal)
+		}
+		return true
+	})
+	if len(got) != len(want) {
+		t.Fatalf("got literals %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("literal %d is %#v, want %#v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestLiteralCharWithoutValue(t *testing.T) {
+	if lit := literal(token{typ: tokenChar, val: "'a'"}); lit.Kind != ast.CharLit || lit.Literal != nil {
+		t.Errorf("got %v %#v, want a character literal without value", lit.Kind, lit.Literal)
+	}
+}
+
+// TestRecovery Makes a single typo in the sample: the parser must report it once, where it is, and
+// recover without cascading errors. A malformed token is also an unexpected one.
+func TestRecovery(t *testing.T) {
+	tests := []struct {
+		old, new     string
+		line, column int
+		codes        []string
+	}{
+		{"var { integer n; }", "var { integer n }", 2, 17, []string{CodeUnexpectedToken}},
+		{"integer LIM = 10;", "integer LIM = ;", 3, 23, []string{CodeUnexpectedToken}},
+		{"procedure Mostra (Ponto p) {", "procedure Mostra (Ponto p {", 5, 27, []string{CodeUnexpectedToken}},
+		{"function Fatorial (integer k) : integer", "function Fatorial (integer k) integer", 9, 31, []string{CodeUnexpectedToken}},
+		{"r = 1;", "r = 1", 12, 2, []string{CodeUnexpectedToken}},
+		{"if (k > 1) {", "if (k > ) {", 12, 10, []string{CodeUnexpectedToken}},
+		{"t = k - 1;", "t = k - ;", 13, 11, []string{CodeUnexpectedToken}},
+		{"r = Fatorial(t);", "r = Fatorial(t;", 14, 17, []string{CodeUnexpectedToken}},
+		{"r = r * k;", "r = r * * k;", 15, 11, []string{CodeUnexpectedToken}},
+		{"\t}\n\treturn r;", "\t\n\treturn r;", 17, 2, []string{CodeUnexpectedToken}},
+		{"return r;", "return r", 18, 1, []string{CodeUnexpectedToken}},
+		{"integer i, f;", "integer i f;", 20, 18, []string{CodeUnexpectedToken}},
+		{"while (i <= LIM) {", "while i <= LIM) {", 22, 8, []string{CodeUnexpectedToken}},
+		{`print(i, "! = ", f);`, `print(i, "! = " f);`, 24, 19, []string{CodeUnexpectedToken}},
+		{"i++;", "i++", 26, 2, []string{CodeUnexpectedToken}},
+		{"a.x = 3;", "a. = 3;", 27, 5, []string{CodeUnexpectedToken}},
+		{"Mostra(a);", "Mostra(a));", 28, 11, []string{CodeUnexpectedToken}},
+		{"ok = !ok;", "ok = !;", 29, 8, []string{CodeUnexpectedToken}},
+		{"read(n, a.y);", "read(n a.y);", 30, 9, []string{CodeUnexpectedToken}},
+		{"r = 1;", "r = 1 @;", 11, 8, []string{CodeInvalidChar, CodeUnexpectedToken}},
+		{"t = k - 1;", "t = k & 1;", 13, 9, []string{CodeMalformedLogicalOp, CodeUnexpectedToken}},
+	}
+	for _, test := range tests {
+		src := strings.Replace(sample, test.old, test.new, 1)
+		parser := Syntax(Lex("test", src))
+		parser.Parse()
+		errs := append(parser.LexicalErrors(), parser.Errors()...)
+		var codes []string
+		for _, d := range errs {
+			codes = append(codes, d.Code)
+			if d.Line != test.line || d.Column != test.column {
+				t.Errorf("%q: %v, want it at %d:%d", test.new, d, test.line, test.column)
+			}
+		}
+		if strings.Join(codes, " ") != strings.Join(test.codes, " ") {
+			t.Errorf("%q: got %v, want the codes %v", test.new, errs, test.codes)
+		}
+	}
+}
@@ -0,0 +1,383 @@
+package Compiler
+
+import (
+	"compiladores/Compiler/ast"
+	"sort"
+)
+
+// ====================================== TYPES ======================================
+
+// TypeKind Kind of a type: one of the five primitive types or a register.
+type TypeKind int
+
+const (
+	InvalidType TypeKind = iota // type of erroneous expressions, never reported twice
+	IntegerType
+	RealType
+	StringType
+	BooleanType
+	CharType
+	RegisterType
+)
+
+// Type Primitive types are the predeclared Typ values, every register has a single Type of its own,
+// thus types can be compared by pointer.
+type Type struct {
+	Kind     TypeKind
+	Register *Symbol // declaration of the register, for RegisterType only
+}
+
+// Typ Predeclared types, indexed by kind.
+var Typ = [...]*Type{
+	InvalidType: {Kind: InvalidType},
+	IntegerType: {Kind: IntegerType},
+	RealType:    {Kind: RealType},
+	StringType:  {Kind: StringType},
+	BooleanType: {Kind: BooleanType},
+	CharType:    {Kind: CharType},
+}
+
+func (t *Type) String() string {
+	switch t.Kind {
+	case IntegerType:
+		return integerKeyword
+	case RealType:
+		return realKeyword
+	case StringType:
+		return stringKeyword
+	case BooleanType:
+		return booleanKeyword
+	case CharType:
+		return charKeyword
+	case RegisterType:
+		return t.Register.Name
+	}
+	return "invalid type"
+}
+
+// IsNumeric Reports whether t is integer or real.
+func (t *Type) IsNumeric() bool {
+	return t.Kind == IntegerType || t.Kind == RealType
+}
+
+// IsPrimitive Reports whether t is one of the five primitive types.
+func (t *Type) IsPrimitive() bool {
+	return t.Kind != InvalidType && t.Kind != RegisterType
+}
+
+// AssignableTo Reports whether a value of type t may be stored in a location of type u.
+// Besides identical types, integers are implicitly converted to real.
+func (t *Type) AssignableTo(u *Type) bool {
+	return t == u || t.Kind == InvalidType || u.Kind == InvalidType || (t.Kind == IntegerType && u.Kind == RealType)
+}
+
+var primitiveTypes = map[string]*Type{
+	integerKeyword: Typ[IntegerType],
+	realKeyword:    Typ[RealType],
+	stringKeyword:  Typ[StringType],
+	booleanKeyword: Typ[BooleanType],
+	charKeyword:    Typ[CharType],
+}
+
+var literalTypes = [...]*Type{
+	ast.IntLit:    Typ[IntegerType],
+	ast.RealLit:   Typ[RealType],
+	ast.StringLit: Typ[StringType],
+	ast.CharLit:   Typ[CharType],
+	ast.BoolLit:   Typ[BooleanType],
+}
+
+// TypeOf Returns the type of an expression checked by TypeCheck, nil if unknown.
+func (info *Info) TypeOf(x ast.Expr) *Type {
+	return info.Types[x]
+}
+
+// SymbolType Returns the type of a variable, constant, parameter or field, or the result type of a function.
+func (info *Info) SymbolType(sym *Symbol) *Type {
+	if sym == nil || sym.Type == nil {
+		return Typ[InvalidType]
+	}
+	if t := info.TypeRefs[sym.Type]; t != nil {
+		return t
+	}
+	return Typ[InvalidType]
+}
+
+// ====================================== CHECKER ======================================
+
+// Checker Holds the state of the type checking of a single program.
+type Checker struct {
+	program   *ast.Program
+	info      *Info
+	registers map[*Symbol]*Type
+	errors    []Diagnostic
+}
+
+// TypeCheck Constructor, prepares the type checking of a program whose names were resolved by Semantic.
+func TypeCheck(program *ast.Program, info *Info) *Checker {
+	info.Types = make(map[ast.Expr]*Type)
+	info.TypeRefs = make(map[*ast.TypeRef]*Type)
+	return &Checker{program: program, info: info, registers: make(map[*Symbol]*Type)}
+}
+
+// Errors Returns the errors found by Check.
+func (c *Checker) Errors() []Diagnostic {
+	return c.errors
+}
+
+// errorf Reports a problem of the given kind about node n.
+func (c *Checker) errorf(code string, n ast.Node, format string, args ...interface{}) {
+	c.errors = append(c.errors, errorAt(c.program.File, n.Pos(), nodeEnd(n), code, format, args...))
+}
+
+// Check Computes the type of every expression and verifies that each one fits where it is used.
+func (c *Checker) Check() {
+	program := c.program
+	ast.Inspect(program, func(n ast.Node) bool {
+		if t, ok := n.(*ast.TypeRef); ok {
+			c.info.TypeRefs[t] = c.typeRef(t)
+		}
+		return true
+	})
+
+	for _, decl := range program.Consts {
+		t := c.info.TypeRefs[decl.Type]
+		for _, spec := range decl.Specs {
+			c.assign(t, spec.Value, "constant declaration")
+		}
+	}
+	for _, proc := range program.Procedures {
+		c.block(proc.Body)
+	}
+	for _, fn := range program.Functions {
+		c.block(fn.Body)
+		c.assign(c.info.TypeRefs[fn.Result], fn.Return.Value, "return statement")
+	}
+	if program.Main != nil {
+		c.block(program.Main.Body)
+	}
+
+	sort.SliceStable(c.errors, func(i, j int) bool {
+		return c.errors[i].Span.Start < c.errors[j].Span.Start
+	})
+}
+
+func (c *Checker) typeRef(t *ast.TypeRef) *Type {
+	if p := primitiveTypes[t.Name]; p != nil {
+		return p
+	}
+	sym := c.info.Registers[t]
+	if sym == nil {
+		return Typ[InvalidType]
+	}
+	if c.registers[sym] == nil {
+		c.registers[sym] = &Type{Kind: RegisterType, Register: sym}
+	}
+	return c.registers[sym]
+}
+
+// assign Checks that x may be stored in a location of type t.
+func (c *Checker) assign(t *Type, x ast.Expr, context string) {
+	if u := c.expr(x); !u.AssignableTo(t) {
+		c.errorf(CodeTypeMismatch, x, "cannot use %s value as %s value in %s", u, t, context)
+	}
+}
+
+// ====================================== COMMANDS ======================================
+
+func (c *Checker) block(block *ast.Block) {
+	c.stmts(block.Stmts)
+}
+
+func (c *Checker) stmts(list []ast.Stmt) {
+	for _, s := range list {
+		c.stmt(s)
+	}
+}
+
+func (c *Checker) stmt(s ast.Stmt) {
+	switch s := s.(type) {
+	case *ast.AssignStmt:
+		c.assign(c.expr(s.Target), s.Value, "assignment")
+	case *ast.IncDecStmt:
+		if t := c.expr(s.X); t.Kind != IntegerType && t.Kind != InvalidType {
+			c.errorf(CodeInvalidOperation, s, "invalid operation: %s%s (%s is not integer)", exprString(s.X), s.Op, t)
+		}
+	case *ast.CallStmt:
+		c.call(s.Call)
+	case *ast.IfStmt:
+		c.condition(s, s.Cond, ifKeyword)
+		c.stmts(s.Then.List)
+		if s.Else != nil {
+			c.stmts(s.Else.List)
+		}
+	case *ast.WhileStmt:
+		c.condition(s, s.Cond, whileKeyword)
+		c.stmts(s.Body.List)
+	case *ast.PrintStmt:
+		for _, x := range s.Args {
+			if t := c.expr(x); t.Kind == RegisterType {
+				c.errorf(CodeTypeMismatch, x, "cannot print %s (register %s)", exprString(x), t)
+			}
+		}
+	case *ast.ReadStmt:
+		for _, x := range s.Args {
+			if t := c.expr(x); t.Kind == RegisterType {
+				c.errorf(CodeTypeMismatch, x, "cannot read %s (register %s)", exprString(x), t)
+			}
+		}
+	case *ast.BlockStmt:
+		c.stmts(s.List)
+	}
+}
+
+func (c *Checker) condition(stmt ast.Stmt, cond ast.Expr, statement string) {
+	if cond == nil {
+		c.errorf(CodeInvalidCondition, stmt, "missing condition in %s statement", statement)
+		return
+	}
+	if t := c.expr(cond); t.Kind != BooleanType && t.Kind != InvalidType {
+		c.errorf(CodeInvalidCondition, cond, "non-boolean condition in %s statement (%s)", statement, t)
+	}
+}
+
+// ====================================== EXPRESSIONS ======================================
+
+// expr Returns (and records) the type of x.
+func (c *Checker) expr(x ast.Expr) *Type {
+	t := c.exprType(x)
+	c.info.Types[x] = t
+	return t
+}
+
+func (c *Checker) exprType(x ast.Expr) *Type {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		return literalTypes[x.Kind]
+	case *ast.Ident:
+		return c.symbolType(c.info.Uses[x])
+	case *ast.FieldExpr:
+		c.expr(x.X)
+		return c.symbolType(c.info.Uses[x.Field])
+	case *ast.CallExpr:
+		return c.call(x)
+	case *ast.UnaryExpr:
+		t := c.expr(x.X)
+		if t.Kind != BooleanType && t.Kind != InvalidType {
+			c.errorf(CodeInvalidOperation, x, "invalid operation: operator %s not defined on %s (%s)", x.Op, exprString(x.X), t)
+			return Typ[InvalidType]
+		}
+		return Typ[BooleanType]
+	case *ast.IncDecExpr:
+		t := c.expr(x.X)
+		if t.Kind != IntegerType && t.Kind != InvalidType {
+			c.errorf(CodeInvalidOperation, x, "invalid operation: %s%s (%s is not integer)", exprString(x.X), x.Op, t)
+			return Typ[InvalidType]
+		}
+		return t
+	case *ast.BinaryExpr:
+		return c.binary(x)
+	}
+	return Typ[InvalidType]
+}
+
+func (c *Checker) symbolType(sym *Symbol) *Type {
+	if sym == nil {
+		return Typ[InvalidType]
+	}
+	switch sym.Kind {
+	case VarSymbol, ConstSymbol, ParamSymbol, FieldSymbol, FunctionSymbol:
+		return c.info.SymbolType(sym)
+	}
+	return Typ[InvalidType]
+}
+
+func (c *Checker) binary(x *ast.BinaryExpr) *Type {
+	t, u := c.expr(x.X), c.expr(x.Y)
+	if t.Kind == InvalidType || u.Kind == InvalidType {
+		return Typ[InvalidType]
+	}
+
+	var ok bool
+	var result *Type
+	switch x.Op {
+	case "+", "-", "*", "/":
+		ok = t.IsNumeric() && u.IsNumeric()
+		result = Typ[IntegerType]
+		if t.Kind == RealType || u.Kind == RealType {
+			result = Typ[RealType]
+		}
+	case "<", ">", "<=", ">=":
+		ok = (t.IsNumeric() && u.IsNumeric()) || (t.Kind == CharType && u.Kind == CharType)
+		result = Typ[BooleanType]
+	case "==", "!=":
+		ok = (t.IsNumeric() && u.IsNumeric()) || (t == u && t.IsPrimitive())
+		result = Typ[BooleanType]
+	case "&&", "||":
+		ok = t.Kind == BooleanType && u.Kind == BooleanType
+		result = Typ[BooleanType]
+	}
+	if !ok {
+		if t == u {
+			c.errorf(CodeInvalidOperation, x, "invalid operation: operator %s not defined on %s (%s)", x.Op, exprString(x.X), t)
+		} else {
+			c.errorf(CodeTypeMismatch, x, "invalid operation: %s %s %s (mismatched types %s and %s)", exprString(x.X), x.Op, exprString(x.Y), t, u)
+		}
+		return Typ[InvalidType]
+	}
+	return result
+}
+
+// call Checks the arguments of a call against the parameters of the procedure or function.
+// The result type is returned, InvalidType for procedures.
+func (c *Checker) call(call *ast.CallExpr) *Type {
+	for _, arg := range call.Args {
+		c.expr(arg)
+	}
+	sym := c.info.Uses[call.Fun]
+	if sym == nil || (sym.Kind != ProcedureSymbol && sym.Kind != FunctionSymbol) {
+		return Typ[InvalidType]
+	}
+
+	var params []*ast.Param
+	switch decl := sym.Decl.(type) {
+	case *ast.Procedure:
+		params = decl.Params
+	case *ast.Function:
+		params = decl.Params
+	}
+	if len(call.Args) != len(params) {
+		c.errorf(CodeArgumentCount, call, "wrong number of arguments in call to %s: have %d, want %d", sym.Name, len(call.Args), len(params))
+	} else {
+		for i, arg := range call.Args {
+			if t, u := c.info.Types[arg], c.info.TypeRefs[params[i].Type]; !t.AssignableTo(u) {
+				c.errorf(CodeTypeMismatch, arg, "cannot use %s value as %s value in argument %s to %s", t, u, params[i].Name.Name, sym.Name)
+			}
+		}
+	}
+	if sym.Kind == ProcedureSymbol {
+		return Typ[InvalidType]
+	}
+	return c.info.SymbolType(sym)
+}
+
+// exprString Short textual form of an expression for error messages.
+func exprString(x ast.Expr) string {
+	switch x := x.(type) {
+	case *ast.Ident:
+		return x.Name
+	case *ast.BasicLit:
+		return x.Value
+	case *ast.FieldExpr:
+		return x.X.Name + "." + x.Field.Name
+	case *ast.CallExpr:
+		return x.Fun.Name + "(...)"
+	case *ast.UnaryExpr:
+		return x.Op + exprString(x.X)
+	case *ast.IncDecExpr:
+		return exprString(x.X) + x.Op
+	case *ast.BinaryExpr:
+		return exprString(x.X) + " " + x.Op + " " + exprString(x.Y)
+	}
+	return "_"
+}
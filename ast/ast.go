@@ -0,0 +1,326 @@
+// Package ast declares the types used to represent the syntax tree of a program
+// written in the language described by GramaticaUnica.txt.
+package ast
+
+import "fmt"
+
+// Pos Position of a node in the source.
+type Pos struct {
+	Line   int // line number, starting at 1
+	Column int // column number in characters, starting at 1
+	Offset int // byte offset, starting at 0
+}
+
+// IsValid Reports whether the position is known.
+func (p Pos) IsValid() bool {
+	return p.Line > 0
+}
+
+func (p Pos) String() string {
+	if !p.IsValid() {
+		return "-"
+	}
+	return fmt.Sprintf("%d", p.Line)
+}
+
+// Node Every node of the tree knows where it starts.
+type Node interface {
+	Pos() Pos
+}
+
+// Expr All expression nodes implement the Expr interface.
+type Expr interface {
+	Node
+	exprNode()
+}
+
+// Stmt All statement nodes implement the Stmt interface.
+type Stmt interface {
+	Node
+	stmtNode()
+}
+
+// ====================================== PROGRAM ======================================
+
+// Program <Start> ::= 'program' Identifier ';' <GlobalStatement>
+type Program struct {
+	File       string // name of the source, as given to the lexer
+	Program    Pos    // position of "program" keyword
+	Name       *Ident
+	Vars       []*VarDecl
+	Consts     []*ConstDecl
+	Registers  []*RegisterDecl
+	Procedures []*Procedure
+	Functions  []*Function
+	Main       *Main
+}
+
+// ====================================== DECLARATIONS ======================================
+
+// TypeRef <VarType> (or <ConstType>), either a primitive type keyword or a register name.
+type TypeRef struct {
+	NamePos Pos
+	Name    string
+}
+
+// VarDecl <VarDeclaration>, e.g. "integer a, b;". Also used for register fields.
+type VarDecl struct {
+	Type  *TypeRef
+	Names []*Ident
+}
+
+// ConstDecl <ConstDeclaration>, e.g. "integer MIN = 1, MAX = 2;".
+type ConstDecl struct {
+	Type  *TypeRef
+	Specs []*ConstSpec
+}
+
+// ConstSpec A single "Identifier '=' <Value>" pair of a ConstDecl.
+type ConstSpec struct {
+	Name  *Ident
+	Value Expr
+}
+
+// RegisterDecl <RegisterStatement>
+type RegisterDecl struct {
+	Register Pos // position of "register" keyword
+	Name     *Ident
+	Fields   []*VarDecl
+	Rbrace   Pos // position of the closing brace, invalid when it is missing
+}
+
+// Param A single parameter of a procedure or function.
+type Param struct {
+	Type *TypeRef
+	Name *Ident
+}
+
+// Procedure <ProcedureStatement>
+type Procedure struct {
+	Procedure Pos // position of "procedure" keyword
+	Name      *Ident
+	Params    []*Param
+	Body      *Block
+}
+
+// Function <FunctionStatement>. The grammar only allows a single return, at the end of the body.
+type Function struct {
+	Function Pos // position of "function" keyword
+	Name     *Ident
+	Params   []*Param
+	Result   *TypeRef
+	Body     *Block
+	Return   *ReturnStmt
+}
+
+// Main <Main>
+type Main struct {
+	Main Pos // position of "main" keyword
+	Body *Block
+}
+
+// Block <LocalStatement> enclosed by braces: the body of a procedure, function or main.
+type Block struct {
+	Lbrace Pos
+	Vars   []*VarDecl
+	Stmts  []Stmt
+	Rbrace Pos
+}
+
+func (x *Program) Pos() Pos      { return x.Program }
+func (x *TypeRef) Pos() Pos      { return x.NamePos }
+func (x *VarDecl) Pos() Pos      { return x.Type.Pos() }
+func (x *ConstDecl) Pos() Pos    { return x.Type.Pos() }
+func (x *ConstSpec) Pos() Pos    { return x.Name.Pos() }
+func (x *RegisterDecl) Pos() Pos { return x.Register }
+func (x *Param) Pos() Pos        { return x.Type.Pos() }
+func (x *Procedure) Pos() Pos    { return x.Procedure }
+func (x *Function) Pos() Pos     { return x.Function }
+func (x *Main) Pos() Pos         { return x.Main }
+func (x *Block) Pos() Pos        { return x.Lbrace }
+
+// ====================================== STATEMENTS ======================================
+
+type (
+	// AssignStmt <Assigment> and <FunctionCall>, e.g. "a = b + 1;", "p.idade = 2;" or "x = f(a);".
+	AssignStmt struct {
+		Target Expr // *Ident or *FieldExpr
+		Value  Expr
+	}
+
+	// IncDecStmt "Identifier '++' ';'" or "Identifier '--' ';'".
+	IncDecStmt struct {
+		X      Expr
+		TokPos Pos
+		Op     string // "++" or "--"
+	}
+
+	// CallStmt <ProcedureCall>
+	CallStmt struct {
+		Call *CallExpr
+	}
+
+	// IfStmt <IfDecs>
+	IfStmt struct {
+		If   Pos
+		Cond Expr // nil when the condition is empty
+		Then *BlockStmt
+		Else *BlockStmt // nil when there is no else
+	}
+
+	// WhileStmt <WhileDecs>
+	WhileStmt struct {
+		While Pos
+		Cond  Expr // nil when the condition is empty
+		Body  *BlockStmt
+	}
+
+	// PrintStmt <WriteDecs>
+	PrintStmt struct {
+		Print Pos
+		Args  []Expr
+	}
+
+	// ReadStmt <ReadDecs>. Every argument is an *Ident or *FieldExpr.
+	ReadStmt struct {
+		Read Pos
+		Args []Expr
+	}
+
+	// ReturnStmt "'return' <Value> ';'" at the end of a function.
+	ReturnStmt struct {
+		Return Pos
+		Value  Expr
+	}
+
+	// BlockStmt <LocalCommands> enclosed by braces, as in if/else and while bodies.
+	BlockStmt struct {
+		Lbrace Pos
+		List   []Stmt
+		Rbrace Pos
+	}
+)
+
+func (s *AssignStmt) Pos() Pos { return s.Target.Pos() }
+func (s *IncDecStmt) Pos() Pos { return s.X.Pos() }
+func (s *CallStmt) Pos() Pos   { return s.Call.Pos() }
+func (s *IfStmt) Pos() Pos     { return s.If }
+func (s *WhileStmt) Pos() Pos  { return s.While }
+func (s *PrintStmt) Pos() Pos  { return s.Print }
+func (s *ReadStmt) Pos() Pos   { return s.Read }
+func (s *ReturnStmt) Pos() Pos { return s.Return }
+func (s *BlockStmt) Pos() Pos  { return s.Lbrace }
+
+func (*AssignStmt) stmtNode() {}
+func (*IncDecStmt) stmtNode() {}
+func (*CallStmt) stmtNode()   {}
+func (*IfStmt) stmtNode()     {}
+func (*WhileStmt) stmtNode()  {}
+func (*PrintStmt) stmtNode()  {}
+func (*ReadStmt) stmtNode()   {}
+func (*ReturnStmt) stmtNode() {}
+func (*BlockStmt) stmtNode()  {}
+
+// ====================================== EXPRESSIONS ======================================
+
+// LitKind Kind of a literal value.
+type LitKind int
+
+const (
+	IntLit    LitKind = iota // Decimal
+	RealLit                  // RealNumber
+	StringLit                // StringLiteral
+	CharLit                  // Char
+	BoolLit                  // Boolean
+)
+
+var litKinds = [...]string{"integer", "real", "string", "char", "boolean"}
+
+func (k LitKind) String() string {
+	return litKinds[k]
+}
+
+type (
+	// BadExpr Placeholder for an expression containing syntax errors.
+	BadExpr struct {
+		From Pos
+	}
+
+	// Ident Identifier
+	Ident struct {
+		NamePos Pos
+		Name    string
+	}
+
+	// BasicLit Literal of a basic type. Value holds the literal text as written in the
+	// source, quotes included for strings and chars, and Literal the value the lexer read: an
+	// int64, float64, string, rune or bool according to Kind, nil for a number out of range.
+	BasicLit struct {
+		ValuePos Pos
+		Kind     LitKind
+		Value    string
+		Literal  interface{}
+	}
+
+	// FieldExpr Register field access, e.g. "p.idade".
+	FieldExpr struct {
+		X     *Ident
+		Field *Ident
+	}
+
+	// BinaryExpr Arithmetic, relational or logical expression.
+	BinaryExpr struct {
+		X     Expr
+		OpPos Pos
+		Op    string
+		Y     Expr
+	}
+
+	// UnaryExpr <UnaryExpression>, i.e. "'!' <AddendOperatorUnary>".
+	UnaryExpr struct {
+		OpPos Pos
+		Op    string
+		X     Expr
+	}
+
+	// IncDecExpr "<AddendOperator> '++'" or "<AddendOperator> '--'" used as a value.
+	// As in C, it yields the value of X before it is incremented (decremented).
+	IncDecExpr struct {
+		X     Expr
+		OpPos Pos
+		Op    string
+	}
+
+	// CallExpr Function or procedure call.
+	CallExpr struct {
+		Fun    *Ident
+		Lparen Pos
+		Args   []Expr
+		Rparen Pos
+	}
+)
+
+func (x *BadExpr) Pos() Pos    { return x.From }
+func (x *Ident) Pos() Pos      { return x.NamePos }
+func (x *BasicLit) Pos() Pos   { return x.ValuePos }
+func (x *FieldExpr) Pos() Pos  { return x.X.Pos() }
+func (x *BinaryExpr) Pos() Pos { return x.X.Pos() }
+func (x *UnaryExpr) Pos() Pos  { return x.OpPos }
+func (x *IncDecExpr) Pos() Pos { return x.X.Pos() }
+func (x *CallExpr) Pos() Pos   { return x.Fun.Pos() }
+
+func (*BadExpr) exprNode()    {}
+func (*Ident) exprNode()      {}
+func (*BasicLit) exprNode()   {}
+func (*FieldExpr) exprNode()  {}
+func (*BinaryExpr) exprNode() {}
+func (*UnaryExpr) exprNode()  {}
+func (*IncDecExpr) exprNode() {}
+func (*CallExpr) exprNode()   {}
+
+func (x *Ident) String() string {
+	if x == nil {
+		return "<nil>"
+	}
+	return x.Name
+}
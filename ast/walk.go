@@ -0,0 +1,158 @@
+package ast
+
+import "fmt"
+
+// Visitor Visit is invoked for each node encountered by Walk. If the result w is not nil,
+// Walk visits each of the children of node with w, followed by a call of w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk Traverses the tree in depth-first order, children in source order.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Program:
+		Walk(v, n.Name)
+		for _, d := range n.Vars {
+			Walk(v, d)
+		}
+		for _, d := range n.Consts {
+			Walk(v, d)
+		}
+		for _, d := range n.Registers {
+			Walk(v, d)
+		}
+		for _, d := range n.Procedures {
+			Walk(v, d)
+		}
+		for _, d := range n.Functions {
+			Walk(v, d)
+		}
+		if n.Main != nil {
+			Walk(v, n.Main)
+		}
+
+	case *TypeRef, *Ident, *BasicLit, *BadExpr:
+		// leaves
+
+	case *VarDecl:
+		Walk(v, n.Type)
+		for _, name := range n.Names {
+			Walk(v, name)
+		}
+	case *ConstDecl:
+		Walk(v, n.Type)
+		for _, s := range n.Specs {
+			Walk(v, s)
+		}
+	case *ConstSpec:
+		Walk(v, n.Name)
+		Walk(v, n.Value)
+	case *RegisterDecl:
+		Walk(v, n.Name)
+		for _, f := range n.Fields {
+			Walk(v, f)
+		}
+	case *Param:
+		Walk(v, n.Type)
+		Walk(v, n.Name)
+	case *Procedure:
+		Walk(v, n.Name)
+		for _, p := range n.Params {
+			Walk(v, p)
+		}
+		Walk(v, n.Body)
+	case *Function:
+		Walk(v, n.Name)
+		for _, p := range n.Params {
+			Walk(v, p)
+		}
+		Walk(v, n.Result)
+		Walk(v, n.Body)
+		Walk(v, n.Return)
+	case *Main:
+		Walk(v, n.Body)
+	case *Block:
+		for _, d := range n.Vars {
+			Walk(v, d)
+		}
+		for _, s := range n.Stmts {
+			Walk(v, s)
+		}
+
+	case *AssignStmt:
+		Walk(v, n.Target)
+		Walk(v, n.Value)
+	case *IncDecStmt:
+		Walk(v, n.X)
+	case *CallStmt:
+		Walk(v, n.Call)
+	case *IfStmt:
+		if n.Cond != nil {
+			Walk(v, n.Cond)
+		}
+		Walk(v, n.Then)
+		if n.Else != nil {
+			Walk(v, n.Else)
+		}
+	case *WhileStmt:
+		if n.Cond != nil {
+			Walk(v, n.Cond)
+		}
+		Walk(v, n.Body)
+	case *PrintStmt:
+		for _, x := range n.Args {
+			Walk(v, x)
+		}
+	case *ReadStmt:
+		for _, x := range n.Args {
+			Walk(v, x)
+		}
+	case *ReturnStmt:
+		Walk(v, n.Value)
+	case *BlockStmt:
+		for _, s := range n.List {
+			Walk(v, s)
+		}
+
+	case *FieldExpr:
+		Walk(v, n.X)
+		Walk(v, n.Field)
+	case *BinaryExpr:
+		Walk(v, n.X)
+		Walk(v, n.Y)
+	case *UnaryExpr:
+		Walk(v, n.X)
+	case *IncDecExpr:
+		Walk(v, n.X)
+	case *CallExpr:
+		Walk(v, n.Fun)
+		for _, x := range n.Args {
+			Walk(v, x)
+		}
+
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect Traverses the tree calling f(node) for each node; if f returns true,
+// Inspect is invoked recursively for each of the children, followed by a call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,1028 @@
+package codegen
+
+import (
+	"bufio"
+	Compiler "compiladores/Compiler/analyzer"
+	"compiladores/Compiler/ast"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+)
+
+// asmRuntime print and read routines of the generated assembly, built on the C library.
+// Every routine follows the System V calling convention; reals are passed in %xmm0.
+const asmRuntime = `
+	.text
+rt_print_int:
+	pushq %rbp
+	movq %rsp, %rbp
+	movq %rdi, %rsi
+	leaq .Lfmt_int(%rip), %rdi
+	xorl %eax, %eax
+	call printf@PLT
+	leave
+	ret
+
+rt_print_real:
+	pushq %rbp
+	movq %rsp, %rbp
+	ucomisd %xmm0, %xmm0
+	jp 2f
+	movq %xmm0, %rax
+	movq %rax, %rcx
+	btrq $63, %rcx
+	movabsq $0x7ff0000000000000, %rdx
+	cmpq %rdx, %rcx
+	je 1f
+	leaq .Lfmt_real(%rip), %rdi
+	movl $1, %eax
+	call printf@PLT
+	leave
+	ret
+1:	leaq .Lpos_inf(%rip), %rsi
+	testq %rax, %rax
+	jns 3f
+	leaq .Lneg_inf(%rip), %rsi
+	jmp 3f
+2:	leaq .Lnan(%rip), %rsi
+3:	leaq .Lfmt_str(%rip), %rdi
+	xorl %eax, %eax
+	call printf@PLT
+	leave
+	ret
+
+rt_print_string:
+	pushq %rbp
+	movq %rsp, %rbp
+	testq %rdi, %rdi
+	jz 1f
+	movq %rdi, %rsi
+	leaq .Lfmt_str(%rip), %rdi
+	xorl %eax, %eax
+	call printf@PLT
+1:	leave
+	ret
+
+rt_print_bool:
+	pushq %rbp
+	movq %rsp, %rbp
+	leaq .Ltrue(%rip), %rsi
+	leaq .Lfalse(%rip), %rax
+	testq %rdi, %rdi
+	cmovzq %rax, %rsi
+	leaq .Lfmt_str(%rip), %rdi
+	xorl %eax, %eax
+	call printf@PLT
+	leave
+	ret
+
+# rt_print_char writes the UTF-8 encoding of the code point in %edi
+rt_print_char:
+	pushq %rbp
+	movq %rsp, %rbp
+	pushq %rbx
+	pushq %r12
+	movl %edi, %ebx
+	cmpl $0x80, %ebx
+	jae 1f
+	call putchar@PLT
+	jmp 9f
+1:	cmpl $0x800, %ebx
+	jae 2f
+	movl $6, %r12d
+	movl %ebx, %edi
+	shrl $6, %edi
+	orl $0xC0, %edi
+	jmp 4f
+2:	cmpl $0x10000, %ebx
+	jae 3f
+	movl $12, %r12d
+	movl %ebx, %edi
+	shrl $12, %edi
+	orl $0xE0, %edi
+	jmp 4f
+3:	movl $18, %r12d
+	movl %ebx, %edi
+	shrl $18, %edi
+	orl $0xF0, %edi
+4:	call putchar@PLT
+5:	subl $6, %r12d
+	movl %ebx, %edi
+	movl %r12d, %ecx
+	shrl %cl, %edi
+	andl $0x3F, %edi
+	orl $0x80, %edi
+	call putchar@PLT
+	testl %r12d, %r12d
+	jnz 5b
+9:	popq %r12
+	popq %rbx
+	leave
+	ret
+
+rt_println:
+	pushq %rbp
+	movq %rsp, %rbp
+	movl $10, %edi
+	call putchar@PLT
+	leave
+	ret
+
+# rt_error writes "runtime error at line %edi: %rsi" followed by the word %rdx, if any, and exits
+rt_error:
+	pushq %rbp
+	movq %rsp, %rbp
+	pushq %rbx
+	pushq %r12
+	pushq %r13
+	subq $8, %rsp
+	movl %edi, %ebx
+	movq %rsi, %r12
+	movq %rdx, %r13
+	xorl %edi, %edi
+	call fflush@PLT
+	movq stderr@GOTPCREL(%rip), %rax
+	movq (%rax), %rdi
+	leaq .Lfmt_error(%rip), %rsi
+	movl %ebx, %edx
+	movq %r12, %rcx
+	xorl %eax, %eax
+	call fprintf@PLT
+	testq %r13, %r13
+	jz 1f
+	movq stderr@GOTPCREL(%rip), %rax
+	movq (%rax), %rdi
+	leaq .Lfmt_error_word(%rip), %rsi
+	movq %r13, %rdx
+	xorl %eax, %eax
+	call fprintf@PLT
+1:	movq stderr@GOTPCREL(%rip), %rax
+	movq (%rax), %rsi
+	movl $10, %edi
+	call fputc@PLT
+	movl $1, %edi
+	call exit@PLT
+
+rt_div_zero:
+	leaq .Lmsg_div(%rip), %rsi
+	xorl %edx, %edx
+	jmp rt_error
+
+# rt_read_word returns the next whitespace separated word of the input, %edi is the line of the read
+rt_read_word:
+	pushq %rbp
+	movq %rsp, %rbp
+	pushq %rbx
+	subq $24, %rsp
+	movl %edi, %ebx
+	xorl %edi, %edi
+	call fflush@PLT
+	leaq -16(%rbp), %rsi
+	leaq .Lfmt_word(%rip), %rdi
+	xorl %eax, %eax
+	call scanf@PLT
+	cmpl $1, %eax
+	je 1f
+	movl %ebx, %edi
+	leaq .Lmsg_eof(%rip), %rsi
+	xorl %edx, %edx
+	call rt_error
+1:	movq -16(%rbp), %rax
+	movq -8(%rbp), %rbx
+	leave
+	ret
+
+rt_read_int:
+	pushq %rbp
+	movq %rsp, %rbp
+	pushq %rbx
+	pushq %r12
+	subq $16, %rsp
+	movl %edi, %ebx
+	call rt_read_word
+	movq %rax, %r12
+	call __errno_location@PLT
+	movl $0, (%rax)
+	movq %r12, %rdi
+	leaq -32(%rbp), %rsi
+	movl $10, %edx
+	call strtoll@PLT
+	movq %rax, -24(%rbp)
+	call __errno_location@PLT
+	cmpl $0, (%rax)
+	jne 1f
+	movq -32(%rbp), %rax
+	cmpb $0, (%rax)
+	jne 1f
+	movq %r12, %rdi
+	call free@PLT
+	movq -24(%rbp), %rax
+	movq -8(%rbp), %rbx
+	movq -16(%rbp), %r12
+	leave
+	ret
+1:	movl %ebx, %edi
+	leaq .Lmsg_int(%rip), %rsi
+	movq %r12, %rdx
+	call rt_error
+
+rt_read_real:
+	pushq %rbp
+	movq %rsp, %rbp
+	pushq %rbx
+	pushq %r12
+	subq $16, %rsp
+	movl %edi, %ebx
+	call rt_read_word
+	movq %rax, %r12
+	call __errno_location@PLT
+	movl $0, (%rax)
+	movq %r12, %rdi
+	leaq -32(%rbp), %rsi
+	call strtod@PLT
+	movsd %xmm0, -24(%rbp)
+	call __errno_location@PLT
+	cmpl $0, (%rax)
+	jne 1f
+	movq -32(%rbp), %rax
+	cmpb $0, (%rax)
+	jne 1f
+	movq %r12, %rdi
+	call free@PLT
+	movsd -24(%rbp), %xmm0
+	movq -8(%rbp), %rbx
+	movq -16(%rbp), %r12
+	leave
+	ret
+1:	movl %ebx, %edi
+	leaq .Lmsg_real(%rip), %rsi
+	movq %r12, %rdx
+	call rt_error
+
+rt_read_string:
+	jmp rt_read_word
+
+rt_read_bool:
+	pushq %rbp
+	movq %rsp, %rbp
+	pushq %rbx
+	pushq %r12
+	movl %edi, %ebx
+	call rt_read_word
+	movq %rax, %r12
+	movq %rax, %rdi
+	leaq .Ltrue(%rip), %rsi
+	call strcmp@PLT
+	testl %eax, %eax
+	jz 1f
+	movq %r12, %rdi
+	leaq .Lfalse(%rip), %rsi
+	call strcmp@PLT
+	testl %eax, %eax
+	jnz 2f
+	xorl %ebx, %ebx
+	jmp 3f
+1:	movl $1, %ebx
+3:	movq %r12, %rdi
+	call free@PLT
+	movq %rbx, %rax
+	popq %r12
+	popq %rbx
+	leave
+	ret
+2:	movl %ebx, %edi
+	leaq .Lmsg_bool(%rip), %rsi
+	movq %r12, %rdx
+	call rt_error
+
+# rt_read_char returns the code point of the first UTF-8 encoded character of the next word
+rt_read_char:
+	pushq %rbp
+	movq %rsp, %rbp
+	pushq %rbx
+	pushq %r12
+	call rt_read_word
+	movq %rax, %r12
+	movzbl (%r12), %ebx
+	cmpl $0xC0, %ebx
+	jb 9f
+	movzbl 1(%r12), %ecx
+	testl %ecx, %ecx
+	jz 9f
+	andl $0x3F, %ecx
+	cmpl $0xE0, %ebx
+	jae 1f
+	andl $0x1F, %ebx
+	shll $6, %ebx
+	orl %ecx, %ebx
+	jmp 9f
+1:	movzbl 2(%r12), %edx
+	testl %edx, %edx
+	jz 9f
+	andl $0x3F, %edx
+	cmpl $0xF0, %ebx
+	jae 2f
+	andl $0x0F, %ebx
+	shll $12, %ebx
+	shll $6, %ecx
+	orl %ecx, %ebx
+	orl %edx, %ebx
+	jmp 9f
+2:	movzbl 3(%r12), %esi
+	testl %esi, %esi
+	jz 9f
+	andl $0x3F, %esi
+	andl $0x07, %ebx
+	shll $18, %ebx
+	shll $12, %ecx
+	orl %ecx, %ebx
+	shll $6, %edx
+	orl %edx, %ebx
+	orl %esi, %ebx
+9:	movq %r12, %rdi
+	call free@PLT
+	movl %ebx, %eax
+	popq %r12
+	popq %rbx
+	leave
+	ret
+
+rt_str_eq:
+	pushq %rbp
+	movq %rsp, %rbp
+	leaq .Lempty(%rip), %rax
+	testq %rdi, %rdi
+	cmovzq %rax, %rdi
+	testq %rsi, %rsi
+	cmovzq %rax, %rsi
+	call strcmp@PLT
+	testl %eax, %eax
+	sete %al
+	movzbl %al, %eax
+	leave
+	ret
+
+	.section .rodata
+.Lfmt_int:	.string "%lld"
+.Lfmt_real:	.string "%g"
+.Lfmt_str:	.string "%s"
+.Lfmt_word:	.string "%ms"
+.Lfmt_error:	.string "runtime error at line %d: %s"
+.Lfmt_error_word:	.string " \"%s\""
+.Lpos_inf:	.string "+Inf"
+.Lneg_inf:	.string "-Inf"
+.Lnan:	.string "NaN"
+.Ltrue:	.string "true"
+.Lfalse:	.string "false"
+.Lempty:	.string ""
+.Lmsg_eof:	.string "read: unexpected end of input"
+.Lmsg_int:	.string "read: invalid integer"
+.Lmsg_real:	.string "read: invalid real"
+.Lmsg_bool:	.string "read: invalid boolean"
+.Lmsg_div:	.string "integer division by zero"
+`
+
+var (
+	intRegs = [...]string{"%rdi", "%rsi", "%rdx", "%rcx", "%r8", "%r9"}
+	sseRegs = 8
+)
+
+// asmgen Holds the state of the translation of a single program to assembly. Every expression
+// leaves its value in %rax, reals as their bits; registers are heap allocated and held by pointer.
+type asmgen struct {
+	info   *Compiler.Info
+	w      *bufio.Writer
+	labels int
+	depth  int // bytes pushed since the frame of the current function was set up
+	slots  map[*Compiler.Symbol]int
+	fields map[*Compiler.Symbol]int
+	strs   map[string]string
+	reals  map[uint64]string
+	data   []string // read only data, in order of first use
+}
+
+// GenerateAsm Writes GNU as x86-64 assembly for Linux of a program free of syntax, semantic
+// and type errors. Procedures and functions follow the System V calling convention.
+func GenerateAsm(w io.Writer, program *ast.Program, info *Compiler.Info) error {
+	g := &asmgen{
+		info:   info,
+		w:      bufio.NewWriter(w),
+		fields: make(map[*Compiler.Symbol]int),
+		strs:   make(map[string]string),
+		reals:  make(map[uint64]string),
+	}
+	fmt.Fprintf(g.w, "# program %s, generated by the compiler\n", program.Name.Name)
+	for _, r := range program.Registers {
+		if sym := info.Defs[r.Name]; sym != nil {
+			for i, field := range sym.Members.Symbols {
+				g.fields[field] = i
+			}
+		}
+	}
+
+	g.w.WriteString("\n\t.bss\n\t.align 8\n")
+	for _, sym := range info.Global.Symbols {
+		if sym.Kind == Compiler.VarSymbol || sym.Kind == Compiler.ConstSymbol {
+			fmt.Fprintf(g.w, "g_%s:\n\t.zero 8\n", sym.Name)
+		}
+	}
+
+	g.w.WriteString("\n\t.text\n")
+	for _, proc := range program.Procedures {
+		if sym := info.Defs[proc.Name]; sym != nil {
+			g.function(sym, proc.Params, proc.Body, nil, nil)
+		}
+	}
+	for _, fn := range program.Functions {
+		if sym := info.Defs[fn.Name]; sym != nil {
+			g.function(sym, fn.Params, fn.Body, fn.Return, info.TypeRefs[fn.Result])
+		}
+	}
+	g.main(program)
+
+	g.w.WriteString(asmRuntime)
+	for _, line := range g.data {
+		g.w.WriteString(line)
+	}
+	g.w.WriteString("\t.section .note.GNU-stack,\"\",@progbits\n")
+	return g.w.Flush()
+}
+
+// Build Compiles the program into the executable output with the C compiler driver (cc, or $CC). On
+// x86-64 Linux the assembly of GenerateAsm is assembled by the system assembler and linked against
+// the C library; as it follows the System V ABI and ELF conventions, other platforms compile the C
+// of GenerateC instead.
+func Build(program *ast.Program, info *Compiler.Info, output string) error {
+	return build(program, info, output, runtime.GOOS == "linux" && runtime.GOARCH == "amd64")
+}
+
+// build Compiles the program into the executable output through the assembly when native is set,
+// through the C otherwise.
+func build(program *ast.Program, info *Compiler.Info, output string, native bool) error {
+	dir, err := os.MkdirTemp("", "compiler")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	cc := os.Getenv("CC")
+	if cc == "" {
+		cc = "cc"
+	}
+	src, generate := filepath.Join(dir, "program.c"), GenerateC
+	commands := [][]string{{cc, "-o", output, src, "-lm"}}
+	if native {
+		obj := filepath.Join(dir, "program.o")
+		src, generate = filepath.Join(dir, "program.s"), GenerateAsm
+		commands = [][]string{{"as", "-o", obj, src}, {cc, "-o", output, obj}}
+	}
+
+	f, err := os.Create(src)
+	if err != nil {
+		return err
+	}
+	err = generate(f, program, info)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	for _, args := range commands {
+		out, err := exec.Command(args[0], args[1:]...).CombinedOutput()
+		if err != nil {
+			return fmt.Errorf("%s: %v\n%s", args[0], err, out)
+		}
+	}
+	return nil
+}
+
+func (g *asmgen) emit(format string, args ...interface{}) {
+	g.w.WriteByte('\t')
+	fmt.Fprintf(g.w, format, args...)
+	g.w.WriteByte('\n')
+}
+
+func (g *asmgen) newLabel() string {
+	g.labels++
+	return fmt.Sprintf(".L%d", g.labels)
+}
+
+func (g *asmgen) label(l string) {
+	fmt.Fprintf(g.w, "%s:\n", l)
+}
+
+func (g *asmgen) push() {
+	g.emit("pushq %%rax")
+	g.depth += 8
+}
+
+func (g *asmgen) pop(reg string) {
+	g.emit("popq %s", reg)
+	g.depth -= 8
+}
+
+// callRuntime Calls a runtime or C library routine with the stack aligned to 16 bytes.
+func (g *asmgen) callRuntime(name string) {
+	if g.depth%16 != 0 {
+		g.emit("subq $8, %%rsp")
+		g.emit("call %s", name)
+		g.emit("addq $8, %%rsp")
+	} else {
+		g.emit("call %s", name)
+	}
+}
+
+// addr Operand addressing a variable, constant or parameter.
+func (g *asmgen) addr(sym *Compiler.Symbol) string {
+	if off, ok := g.slots[sym]; ok {
+		return fmt.Sprintf("%d(%%rbp)", off)
+	}
+	return fmt.Sprintf("g_%s(%%rip)", sym.Name)
+}
+
+// ====================================== DECLARATIONS ======================================
+
+// frame Assigns a stack slot to the parameters and local variables and emits the prologue.
+func (g *asmgen) frame(name string, syms []*Compiler.Symbol) {
+	g.slots = make(map[*Compiler.Symbol]int)
+	for i, sym := range syms {
+		g.slots[sym] = -8 * (i + 1)
+	}
+	size := (8*len(syms) + 15) &^ 15
+	g.depth = 0
+	g.label(name)
+	g.emit("pushq %%rbp")
+	g.emit("movq %%rsp, %%rbp")
+	if size > 0 {
+		g.emit("subq $%d, %%rsp", size)
+	}
+}
+
+func (g *asmgen) blockSymbols(block *ast.Block) []*Compiler.Symbol {
+	var syms []*Compiler.Symbol
+	for _, decl := range block.Vars {
+		for _, name := range decl.Names {
+			if sym := g.info.Defs[name]; sym != nil {
+				syms = append(syms, sym)
+			}
+		}
+	}
+	return syms
+}
+
+func (g *asmgen) function(sym *Compiler.Symbol, params []*ast.Param, body *ast.Block, ret *ast.ReturnStmt, result *Compiler.Type) {
+	var syms []*Compiler.Symbol
+	for _, param := range params {
+		syms = append(syms, g.info.Defs[param.Name])
+	}
+	locals := g.blockSymbols(body)
+	g.w.WriteByte('\n')
+	g.frame("f_"+sym.Name, append(syms, locals...))
+
+	ints, sses, stack := 0, 0, 0
+	for _, param := range syms {
+		t := g.info.SymbolType(param)
+		switch {
+		case t.Kind == Compiler.RealType && sses < sseRegs:
+			g.emit("movsd %%xmm%d, %s", sses, g.addr(param))
+			sses++
+		case t.Kind != Compiler.RealType && ints < len(intRegs):
+			g.emit("movq %s, %s", intRegs[ints], g.addr(param))
+			ints++
+		default:
+			g.emit("movq %d(%%rbp), %%rax", 16+8*stack)
+			g.emit("movq %%rax, %s", g.addr(param))
+			stack++
+		}
+	}
+	// registers are passed by value, the callee works on its own copy
+	for _, param := range syms {
+		if t := g.info.SymbolType(param); t.Kind == Compiler.RegisterType {
+			g.alloc(t)
+			g.emit("movq %s, %%rsi", g.addr(param))
+			g.emit("movq %%rax, %s", g.addr(param))
+			g.copyRecord(t)
+		}
+	}
+	g.initialize(locals)
+	g.stmts(body.Stmts)
+
+	if ret != nil {
+		g.comment(ret)
+		g.value(ret.Value, result)
+		if result.Kind == Compiler.RealType {
+			g.emit("movq %%rax, %%xmm0")
+		}
+	}
+	g.emit("leave")
+	g.emit("ret")
+}
+
+func (g *asmgen) main(program *ast.Program) {
+	var locals []*Compiler.Symbol
+	if program.Main != nil {
+		locals = g.blockSymbols(program.Main.Body)
+	}
+	g.w.WriteString("\n\t.globl main\n")
+	g.frame("main", locals)
+
+	var globals []*Compiler.Symbol
+	for _, decl := range program.Vars {
+		for _, name := range decl.Names {
+			if sym := g.info.Defs[name]; sym != nil {
+				globals = append(globals, sym)
+			}
+		}
+	}
+	g.initialize(globals)
+	for _, decl := range program.Consts {
+		for _, spec := range decl.Specs {
+			if sym := g.info.Defs[spec.Name]; sym != nil {
+				g.value(spec.Value, g.info.SymbolType(sym))
+				g.emit("movq %%rax, %s", g.addr(sym))
+			}
+		}
+	}
+
+	g.initialize(locals)
+	if program.Main != nil {
+		g.stmts(program.Main.Body.Stmts)
+	}
+	g.emit("xorl %%eax, %%eax")
+	g.emit("leave")
+	g.emit("ret")
+}
+
+// initialize Sets variables to their zero value: strings are empty and registers allocated.
+func (g *asmgen) initialize(syms []*Compiler.Symbol) {
+	for _, sym := range syms {
+		switch t := g.info.SymbolType(sym); t.Kind {
+		case Compiler.RegisterType:
+			g.alloc(t)
+		case Compiler.StringType:
+			g.emit("leaq .Lempty(%%rip), %%rax")
+		default:
+			g.emit("xorl %%eax, %%eax")
+		}
+		g.emit("movq %%rax, %s", g.addr(sym))
+	}
+}
+
+// alloc Leaves in %rax a new register of type t, fields set to zero.
+func (g *asmgen) alloc(t *Compiler.Type) {
+	n := len(t.Register.Members.Symbols)
+	if n == 0 {
+		n = 1
+	}
+	g.emit("movl $%d, %%edi", n)
+	g.emit("movl $8, %%esi")
+	g.callRuntime("calloc@PLT")
+}
+
+// copyRecord Copies the fields of the register at %rsi to the register at %rax.
+func (g *asmgen) copyRecord(t *Compiler.Type) {
+	g.emit("movq %%rax, %%rdi")
+	g.emit("movl $%d, %%ecx", len(t.Register.Members.Symbols))
+	g.emit("rep movsq")
+}
+
+// ====================================== COMMANDS ======================================
+
+func (g *asmgen) comment(s ast.Node) {
+	fmt.Fprintf(g.w, "\t# line %d\n", s.Pos().Line)
+}
+
+func (g *asmgen) stmts(list []ast.Stmt) {
+	for _, s := range list {
+		g.comment(s)
+		g.stmt(s)
+	}
+}
+
+func (g *asmgen) stmt(s ast.Stmt) {
+	switch s := s.(type) {
+	case *ast.AssignStmt:
+		t := g.info.TypeOf(s.Target)
+		g.value(s.Value, t)
+		if t.Kind == Compiler.RegisterType {
+			g.emit("movq %%rax, %%rsi")
+			g.emit("movq %s, %%rax", g.addr(g.info.Uses[s.Target.(*ast.Ident)]))
+			g.copyRecord(t)
+			return
+		}
+		g.store(s.Target)
+	case *ast.IncDecStmt:
+		g.expr(s.X)
+		if s.Op == "++" {
+			g.emit("addq $1, %%rax")
+		} else {
+			g.emit("subq $1, %%rax")
+		}
+		g.store(s.X)
+	case *ast.CallStmt:
+		g.call(s.Call)
+	case *ast.IfStmt:
+		elseLabel, end := g.newLabel(), g.newLabel()
+		g.expr(s.Cond)
+		g.emit("testq %%rax, %%rax")
+		g.emit("jz %s", elseLabel)
+		g.stmts(s.Then.List)
+		g.emit("jmp %s", end)
+		g.label(elseLabel)
+		if s.Else != nil {
+			g.stmts(s.Else.List)
+		}
+		g.label(end)
+	case *ast.WhileStmt:
+		top, end := g.newLabel(), g.newLabel()
+		g.label(top)
+		g.expr(s.Cond)
+		g.emit("testq %%rax, %%rax")
+		g.emit("jz %s", end)
+		g.stmts(s.Body.List)
+		g.emit("jmp %s", top)
+		g.label(end)
+	case *ast.PrintStmt:
+		for _, x := range s.Args {
+			t := g.info.TypeOf(x)
+			g.expr(x)
+			if t.Kind == Compiler.RealType {
+				g.emit("movq %%rax, %%xmm0")
+			} else {
+				g.emit("movq %%rax, %%rdi")
+			}
+			g.callRuntime("rt_print_" + runtimeSuffix(t))
+		}
+		g.callRuntime("rt_println")
+	case *ast.ReadStmt:
+		for _, x := range s.Args {
+			t := g.info.TypeOf(x)
+			g.emit("movl $%d, %%edi", x.Pos().Line)
+			g.callRuntime("rt_read_" + runtimeSuffix(t))
+			if t.Kind == Compiler.RealType {
+				g.emit("movq %%xmm0, %%rax")
+			}
+			g.store(x)
+		}
+	case *ast.BlockStmt:
+		g.stmts(s.List)
+	}
+}
+
+// store Stores %rax into a variable or a register field.
+func (g *asmgen) store(target ast.Expr) {
+	switch x := target.(type) {
+	case *ast.Ident:
+		g.emit("movq %%rax, %s", g.addr(g.info.Uses[x]))
+	case *ast.FieldExpr:
+		g.emit("movq %s, %%rcx", g.addr(g.info.Uses[x.X]))
+		g.emit("movq %%rax, %d(%%rcx)", 8*g.fields[g.info.Uses[x.Field]])
+	}
+}
+
+// ====================================== EXPRESSIONS ======================================
+
+// value Evaluates x converted to a location of type t.
+func (g *asmgen) value(x ast.Expr, t *Compiler.Type) {
+	g.expr(x)
+	if g.info.TypeOf(x).Kind == Compiler.IntegerType && t.Kind == Compiler.RealType {
+		g.emit("cvtsi2sdq %%rax, %%xmm0")
+		g.emit("movq %%xmm0, %%rax")
+	}
+}
+
+func (g *asmgen) expr(x ast.Expr) {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		g.literal(x)
+	case *ast.Ident:
+		g.emit("movq %s, %%rax", g.addr(g.info.Uses[x]))
+	case *ast.FieldExpr:
+		g.emit("movq %s, %%rax", g.addr(g.info.Uses[x.X]))
+		g.emit("movq %d(%%rax), %%rax", 8*g.fields[g.info.Uses[x.Field]])
+	case *ast.CallExpr:
+		g.call(x)
+	case *ast.UnaryExpr:
+		g.expr(x.X)
+		g.emit("xorq $1, %%rax")
+	case *ast.IncDecExpr:
+		addr := g.addr(g.info.Uses[x.X.(*ast.Ident)])
+		g.emit("movq %s, %%rax", addr)
+		if x.Op == "++" {
+			g.emit("leaq 1(%%rax), %%rcx")
+		} else {
+			g.emit("leaq -1(%%rax), %%rcx")
+		}
+		g.emit("movq %%rcx, %s", addr)
+	case *ast.BinaryExpr:
+		g.binary(x)
+	}
+}
+
+func (g *asmgen) literal(lit *ast.BasicLit) {
+	switch v := lit.Literal.(type) {
+	case int64:
+		if v < math.MinInt32 || v > math.MaxInt32 {
+			g.emit("movabsq $%d, %%rax", v)
+		} else {
+			g.emit("movq $%d, %%rax", v)
+		}
+	case float64:
+		bits := math.Float64bits(v)
+		label, ok := g.reals[bits]
+		if !ok {
+			label = fmt.Sprintf(".LC%d", len(g.reals))
+			g.reals[bits] = label
+			g.data = append(g.data, fmt.Sprintf("\t.align 8\n%s:\t.quad %#x\n", label, bits))
+		}
+		g.emit("movq %s(%%rip), %%rax", label)
+	case string:
+		label, ok := g.strs[v]
+		if !ok {
+			label = fmt.Sprintf(".LS%d", len(g.strs))
+			g.strs[v] = label
+			g.data = append(g.data, fmt.Sprintf("%s:\t.string %s\n", label, cString(v)))
+		}
+		g.emit("leaq %s(%%rip), %%rax", label)
+	case rune:
+		g.emit("movq $%d, %%rax", v)
+	case bool:
+		if v {
+			g.emit("movq $1, %%rax")
+		} else {
+			g.emit("xorl %%eax, %%eax")
+		}
+	}
+}
+
+var (
+	intConds  = map[string]string{"==": "e", "!=": "ne", "<": "l", ">": "g", "<=": "le", ">=": "ge"}
+	realArith = map[string]string{"+": "addsd", "-": "subsd", "*": "mulsd", "/": "divsd"}
+)
+
+func (g *asmgen) binary(x *ast.BinaryExpr) {
+	switch x.Op {
+	case "&&", "||":
+		end := g.newLabel()
+		g.expr(x.X)
+		g.emit("testq %%rax, %%rax")
+		if x.Op == "&&" {
+			g.emit("jz %s", end)
+		} else {
+			g.emit("jnz %s", end)
+		}
+		g.expr(x.Y)
+		g.label(end)
+		return
+	}
+
+	t, u := g.info.TypeOf(x.X), g.info.TypeOf(x.Y)
+	switch {
+	case t.Kind == Compiler.StringType:
+		g.expr(x.X)
+		g.push()
+		g.expr(x.Y)
+		g.emit("movq %%rax, %%rsi")
+		g.pop("%rdi")
+		g.callRuntime("rt_str_eq")
+		if x.Op == "!=" {
+			g.emit("xorq $1, %%rax")
+		}
+	case t.Kind == Compiler.RealType || u.Kind == Compiler.RealType:
+		g.value(x.X, Compiler.Typ[Compiler.RealType])
+		g.push()
+		g.value(x.Y, Compiler.Typ[Compiler.RealType])
+		g.emit("movq %%rax, %%xmm1")
+		g.pop("%rax")
+		g.emit("movq %%rax, %%xmm0")
+		g.realOp(x.Op)
+	default:
+		g.expr(x.X)
+		g.push()
+		g.expr(x.Y)
+		g.emit("movq %%rax, %%rcx")
+		g.pop("%rax")
+		g.intOp(x.Op, x.OpPos.Line)
+	}
+}
+
+// intOp Applies op to %rax and %rcx.
+func (g *asmgen) intOp(op string, line int) {
+	switch op {
+	case "+":
+		g.emit("addq %%rcx, %%rax")
+	case "-":
+		g.emit("subq %%rcx, %%rax")
+	case "*":
+		g.emit("imulq %%rcx, %%rax")
+	case "/":
+		nonZero, other, end := g.newLabel(), g.newLabel(), g.newLabel()
+		g.emit("testq %%rcx, %%rcx")
+		g.emit("jnz %s", nonZero)
+		g.emit("movl $%d, %%edi", line)
+		g.callRuntime("rt_div_zero")
+		g.label(nonZero)
+		g.emit("cmpq $-1, %%rcx") // idiv traps on the minimum integer divided by -1
+		g.emit("jne %s", other)
+		g.emit("negq %%rax")
+		g.emit("jmp %s", end)
+		g.label(other)
+		g.emit("cqto")
+		g.emit("idivq %%rcx")
+		g.label(end)
+	default:
+		g.emit("cmpq %%rcx, %%rax")
+		g.emit("set%s %%al", intConds[op])
+		g.emit("movzbl %%al, %%eax")
+	}
+}
+
+// realOp Applies op to %xmm0 and %xmm1. Comparisons with NaN are false, except !=.
+func (g *asmgen) realOp(op string) {
+	switch op {
+	case "+", "-", "*", "/":
+		g.emit("%s %%xmm1, %%xmm0", realArith[op])
+		g.emit("movq %%xmm0, %%rax")
+		return
+	case "==":
+		g.emit("ucomisd %%xmm1, %%xmm0")
+		g.emit("sete %%al")
+		g.emit("setnp %%cl")
+		g.emit("andb %%cl, %%al")
+	case "!=":
+		g.emit("ucomisd %%xmm1, %%xmm0")
+		g.emit("setne %%al")
+		g.emit("setp %%cl")
+		g.emit("orb %%cl, %%al")
+	case ">":
+		g.emit("ucomisd %%xmm1, %%xmm0")
+		g.emit("seta %%al")
+	case ">=":
+		g.emit("ucomisd %%xmm1, %%xmm0")
+		g.emit("setae %%al")
+	case "<":
+		g.emit("ucomisd %%xmm0, %%xmm1")
+		g.emit("seta %%al")
+	case "<=":
+		g.emit("ucomisd %%xmm0, %%xmm1")
+		g.emit("setae %%al")
+	}
+	g.emit("movzbl %%al, %%eax")
+}
+
+// call Evaluates the arguments onto the stack, then moves them to the argument registers
+// or pushes them again, in reverse order, when the registers are exhausted.
+func (g *asmgen) call(call *ast.CallExpr) {
+	sym := g.info.Uses[call.Fun]
+	var params []*ast.Param
+	var result *Compiler.Type
+	switch decl := sym.Decl.(type) {
+	case *ast.Procedure:
+		params = decl.Params
+	case *ast.Function:
+		params, result = decl.Params, g.info.TypeRefs[decl.Result]
+	}
+
+	n := len(call.Args)
+	for i, arg := range call.Args {
+		g.value(arg, g.info.TypeRefs[params[i].Type])
+		g.push()
+	}
+	var stack []int
+	ints, sses := 0, 0
+	for i := range call.Args {
+		off := 8 * (n - 1 - i)
+		isReal := g.info.TypeRefs[params[i].Type].Kind == Compiler.RealType
+		switch {
+		case isReal && sses < sseRegs:
+			g.emit("movsd %d(%%rsp), %%xmm%d", off, sses)
+			sses++
+		case !isReal && ints < len(intRegs):
+			g.emit("movq %d(%%rsp), %s", off, intRegs[ints])
+			ints++
+		default:
+			stack = append(stack, i)
+		}
+	}
+	pad := 0
+	if (g.depth+8*len(stack))%16 != 0 {
+		pad = 8
+		g.emit("subq $8, %%rsp")
+	}
+	for k := len(stack) - 1; k >= 0; k-- {
+		g.emit("pushq %d(%%rsp)", 8*(n-1-stack[k])+pad+8*(len(stack)-1-k))
+	}
+	g.emit("call f_%s", sym.Name)
+	if drop := 8*n + pad + 8*len(stack); drop > 0 {
+		g.emit("addq $%d, %%rsp", drop)
+	}
+	g.depth -= 8 * n
+	if result != nil && result.Kind == Compiler.RealType {
+		g.emit("movq %%xmm0, %%rax")
+	}
+}
@@ -0,0 +1,43 @@
+package codegen
+
+import (
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+)
+
+func TestGenerateAsm(t *testing.T) {
+	for _, name := range []string{"sample", "registers"} {
+		golden(t, name, ".s", GenerateAsm)
+	}
+}
+
+// TestBuildNative Builds each sample program from its assembly and compares what it prints with
+// the interpreter. The assembly is for x86-64 System V and ELF, hence only run on x86-64 Linux.
+func TestBuildNative(t *testing.T) {
+	if runtime.GOOS != "linux" || runtime.GOARCH != "amd64" {
+		t.Skipf("the assembly does not run on %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+	if _, err := exec.LookPath("as"); err != nil {
+		t.Skipf("no assembler: %v", err)
+	}
+	compiler(t)
+	testBuild(t, true)
+}
+
+// testBuild Builds each sample program, from its assembly when native is set and from its C
+// otherwise, and compares what it prints with the interpreter.
+func testBuild(t *testing.T, native bool) {
+	dir := t.TempDir()
+	for _, p := range programs {
+		program, info := load(t, p.name)
+		exe := filepath.Join(dir, p.name)
+		if err := build(program, info, exe, native); err != nil {
+			t.Fatalf("%s: %v", p.name, err)
+		}
+		if got, want := run(t, exe, p.input), interpret(program, info, p.input); got != want {
+			t.Errorf("%s printed\n%s\nthe interpreter\n%s", p.name, got, want)
+		}
+	}
+}
@@ -0,0 +1,557 @@
+// Package codegen translates checked programs to target code: a portable C source file
+// or x86-64 assembly.
+package codegen
+
+import (
+	"bufio"
+	Compiler "compiladores/Compiler/analyzer"
+	"compiladores/Compiler/ast"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// cRuntime Helpers shared by every generated C file, inline so unused ones raise no warnings. Integer arithmetic wraps around and
+// integer division by zero stops the program, as in the interpreter.
+const cRuntime = `#include <errno.h>
+#include <math.h>
+#include <stdint.h>
+#include <stdio.h>
+#include <stdlib.h>
+#include <string.h>
+
+#define ADD(a, b) ((int64_t)((uint64_t)(a) + (uint64_t)(b)))
+#define SUB(a, b) ((int64_t)((uint64_t)(a) - (uint64_t)(b)))
+#define MUL(a, b) ((int64_t)((uint64_t)(a) * (uint64_t)(b)))
+
+static inline void runtime_error(int line, const char *message, const char *word) {
+	fflush(stdout);
+	if (word != NULL) {
+		fprintf(stderr, "runtime error at line %d: %s \"%s\"\n", line, message, word);
+	} else {
+		fprintf(stderr, "runtime error at line %d: %s\n", line, message);
+	}
+	exit(1);
+}
+
+static inline int64_t div_int(int64_t a, int64_t b, int line) {
+	if (b == 0) {
+		runtime_error(line, "integer division by zero", NULL);
+	}
+	if (b == -1) {
+		return SUB(0, a);
+	}
+	return a / b;
+}
+
+static inline int64_t post_inc(int64_t *x, int64_t delta) {
+	int64_t old = *x;
+	*x = ADD(old, delta);
+	return old;
+}
+
+static inline int str_eq(const char *a, const char *b) {
+	return strcmp(a != NULL ? a : "", b != NULL ? b : "") == 0;
+}
+
+static inline void print_int(int64_t v) {
+	printf("%lld", (long long)v);
+}
+
+static inline void print_real(double v) {
+	if (isnan(v)) {
+		fputs("NaN", stdout);
+	} else if (isinf(v)) {
+		fputs(v > 0 ? "+Inf" : "-Inf", stdout);
+	} else {
+		printf("%g", v);
+	}
+}
+
+static inline void print_string(const char *v) {
+	fputs(v != NULL ? v : "", stdout);
+}
+
+static inline void print_bool(int v) {
+	fputs(v ? "true" : "false", stdout);
+}
+
+static inline void print_char(int32_t c) {
+	char buf[4];
+	int n = 0;
+	if (c < 0x80) {
+		buf[n++] = (char)c;
+	} else if (c < 0x800) {
+		buf[n++] = (char)(0xC0 | c >> 6);
+		buf[n++] = (char)(0x80 | (c & 0x3F));
+	} else if (c < 0x10000) {
+		buf[n++] = (char)(0xE0 | c >> 12);
+		buf[n++] = (char)(0x80 | (c >> 6 & 0x3F));
+		buf[n++] = (char)(0x80 | (c & 0x3F));
+	} else {
+		buf[n++] = (char)(0xF0 | c >> 18);
+		buf[n++] = (char)(0x80 | (c >> 12 & 0x3F));
+		buf[n++] = (char)(0x80 | (c >> 6 & 0x3F));
+		buf[n++] = (char)(0x80 | (c & 0x3F));
+	}
+	fwrite(buf, 1, n, stdout);
+}
+
+/* read_word Reads the next whitespace separated word of the input. */
+static inline char *read_word(int line) {
+	size_t len = 0, cap = 16;
+	char *word = malloc(cap);
+	int c;
+	fflush(stdout);
+	while ((c = getchar()) != EOF && (c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f')) {
+	}
+	if (c == EOF) {
+		runtime_error(line, "read: unexpected end of input", NULL);
+	}
+	do {
+		if (len + 1 == cap) {
+			word = realloc(word, cap *= 2);
+		}
+		word[len++] = (char)c;
+	} while ((c = getchar()) != EOF && !(c == ' ' || c == '\t' || c == '\n' || c == '\r' || c == '\v' || c == '\f'));
+	word[len] = '\0';
+	return word;
+}
+
+static inline int64_t read_int(int line) {
+	char *word = read_word(line), *end;
+	long long v;
+	errno = 0;
+	v = strtoll(word, &end, 10);
+	if (errno != 0 || *end != '\0') {
+		runtime_error(line, "read: invalid integer", word);
+	}
+	free(word);
+	return v;
+}
+
+static inline double read_real(int line) {
+	char *word = read_word(line), *end;
+	double v;
+	errno = 0;
+	v = strtod(word, &end);
+	if (errno != 0 || *end != '\0') {
+		runtime_error(line, "read: invalid real", word);
+	}
+	free(word);
+	return v;
+}
+
+static inline const char *read_string(int line) {
+	return read_word(line);
+}
+
+static inline int read_bool(int line) {
+	char *word = read_word(line);
+	int v = strcmp(word, "true") == 0;
+	if (!v && strcmp(word, "false") != 0) {
+		runtime_error(line, "read: invalid boolean", word);
+	}
+	free(word);
+	return v;
+}
+
+static inline int32_t read_char(int line) {
+	unsigned char *word = (unsigned char *)read_word(line);
+	int32_t c = word[0];
+	if (c >= 0xF0 && word[1] && word[2] && word[3]) {
+		c = (c & 0x07) << 18 | (word[1] & 0x3F) << 12 | (word[2] & 0x3F) << 6 | (word[3] & 0x3F);
+	} else if (c >= 0xE0 && word[1] && word[2]) {
+		c = (c & 0x0F) << 12 | (word[1] & 0x3F) << 6 | (word[2] & 0x3F);
+	} else if (c >= 0xC0 && word[1]) {
+		c = (c & 0x1F) << 6 | (word[1] & 0x3F);
+	}
+	free(word);
+	return c;
+}
+`
+
+// cgen Holds the state of the translation of a single program to C.
+type cgen struct {
+	info   *Compiler.Info
+	w      *bufio.Writer
+	indent int
+}
+
+// GenerateC Writes a C translation of a program free of syntax, semantic and type errors.
+// Registers become structs, procedures void functions and functions return their declared type.
+func GenerateC(w io.Writer, program *ast.Program, info *Compiler.Info) error {
+	g := &cgen{info: info, w: bufio.NewWriter(w)}
+	g.printf("/* program %s, generated by the compiler */\n", program.Name.Name)
+	g.w.WriteString(cRuntime)
+
+	for _, r := range program.Registers {
+		sym := info.Defs[r.Name]
+		if sym == nil {
+			continue
+		}
+		g.printf("\ntypedef struct %s {\n", cName(sym))
+		g.indent++
+		for _, field := range sym.Members.Symbols {
+			g.line("%s;", g.decl(info.SymbolType(field), cName(field)))
+		}
+		if len(sym.Members.Symbols) == 0 {
+			g.line("char unused;")
+		}
+		g.indent--
+		g.printf("} %s;\n", cName(sym))
+	}
+
+	g.printf("\n")
+	for _, decl := range program.Vars {
+		for _, name := range decl.Names {
+			if sym := info.Defs[name]; sym != nil {
+				g.printf("static %s;\n", g.decl(info.SymbolType(sym), cName(sym)))
+			}
+		}
+	}
+	for _, decl := range program.Consts {
+		for _, spec := range decl.Specs {
+			if sym := info.Defs[spec.Name]; sym != nil {
+				g.printf("static %s;\n", g.decl(info.SymbolType(sym), cName(sym)))
+			}
+		}
+	}
+
+	// prototypes, procedures and functions may be called before their definition
+	g.printf("\n")
+	for _, proc := range program.Procedures {
+		if sym := info.Defs[proc.Name]; sym != nil {
+			g.printf("%s;\n", g.signature("void", sym, proc.Params))
+		}
+	}
+	for _, fn := range program.Functions {
+		if sym := info.Defs[fn.Name]; sym != nil {
+			g.printf("%s;\n", g.signature(g.typ(info.TypeRefs[fn.Result]), sym, fn.Params))
+		}
+	}
+
+	for _, proc := range program.Procedures {
+		if sym := info.Defs[proc.Name]; sym != nil {
+			g.printf("\n%s {\n", g.signature("void", sym, proc.Params))
+			g.indent++
+			g.block(proc.Body)
+			g.indent--
+			g.printf("}\n")
+		}
+	}
+	for _, fn := range program.Functions {
+		if sym := info.Defs[fn.Name]; sym != nil {
+			g.printf("\n%s {\n", g.signature(g.typ(info.TypeRefs[fn.Result]), sym, fn.Params))
+			g.indent++
+			g.block(fn.Body)
+			g.line("return %s;", g.expr(fn.Return.Value))
+			g.indent--
+			g.printf("}\n")
+		}
+	}
+
+	g.printf("\nint main(void) {\n")
+	g.indent++
+	if program.Main != nil {
+		g.locals(program.Main.Body)
+	}
+	for _, decl := range program.Vars {
+		for _, name := range decl.Names {
+			if sym := info.Defs[name]; sym != nil && info.SymbolType(sym).Kind == Compiler.StringType {
+				g.line("%s = \"\";", cName(sym))
+			}
+		}
+	}
+	for _, decl := range program.Consts {
+		for _, spec := range decl.Specs {
+			if sym := info.Defs[spec.Name]; sym != nil {
+				g.line("%s = %s;", cName(sym), g.expr(spec.Value))
+			}
+		}
+	}
+	if program.Main != nil {
+		g.stmts(program.Main.Body.Stmts)
+	}
+	g.line("return 0;")
+	g.indent--
+	g.printf("}\n")
+	return g.w.Flush()
+}
+
+func (g *cgen) printf(format string, args ...interface{}) {
+	fmt.Fprintf(g.w, format, args...)
+}
+
+// line Writes a single indented line.
+func (g *cgen) line(format string, args ...interface{}) {
+	g.w.WriteString(strings.Repeat("\t", g.indent))
+	fmt.Fprintf(g.w, format, args...)
+	g.w.WriteByte('\n')
+}
+
+// cName Identifiers are prefixed by kind, thus they never clash with C keywords or the runtime.
+func cName(sym *Compiler.Symbol) string {
+	switch sym.Kind {
+	case Compiler.RegisterSymbol:
+		return "r_" + sym.Name
+	case Compiler.FieldSymbol:
+		return "m_" + sym.Name
+	case Compiler.ProcedureSymbol, Compiler.FunctionSymbol:
+		return "f_" + sym.Name
+	}
+	return "v_" + sym.Name
+}
+
+func (g *cgen) typ(t *Compiler.Type) string {
+	switch t.Kind {
+	case Compiler.IntegerType:
+		return "int64_t"
+	case Compiler.RealType:
+		return "double"
+	case Compiler.StringType:
+		return "const char *"
+	case Compiler.BooleanType:
+		return "int"
+	case Compiler.CharType:
+		return "int32_t"
+	case Compiler.RegisterType:
+		return cName(t.Register)
+	}
+	return "void"
+}
+
+// decl Declaration of name with type t.
+func (g *cgen) decl(t *Compiler.Type, name string) string {
+	typ := g.typ(t)
+	if strings.HasSuffix(typ, "*") {
+		return typ + name
+	}
+	return typ + " " + name
+}
+
+func (g *cgen) signature(result string, sym *Compiler.Symbol, params []*ast.Param) string {
+	var list []string
+	for _, param := range params {
+		list = append(list, g.decl(g.info.TypeRefs[param.Type], cName(g.info.Defs[param.Name])))
+	}
+	if len(list) == 0 {
+		list = append(list, "void")
+	}
+	return fmt.Sprintf("static %s %s(%s)", result, cName(sym), strings.Join(list, ", "))
+}
+
+// ====================================== COMMANDS ======================================
+
+func (g *cgen) block(block *ast.Block) {
+	g.locals(block)
+	g.stmts(block.Stmts)
+}
+
+// locals Declares the variables of a block, initialized to their zero value.
+func (g *cgen) locals(block *ast.Block) {
+	for _, decl := range block.Vars {
+		for _, name := range decl.Names {
+			sym := g.info.Defs[name]
+			if sym == nil {
+				continue
+			}
+			t := g.info.SymbolType(sym)
+			switch t.Kind {
+			case Compiler.RegisterType:
+				g.line("%s = {0};", g.decl(t, cName(sym)))
+			case Compiler.StringType:
+				g.line("%s = \"\";", g.decl(t, cName(sym)))
+			default:
+				g.line("%s = 0;", g.decl(t, cName(sym)))
+			}
+		}
+	}
+}
+
+func (g *cgen) stmts(list []ast.Stmt) {
+	for _, s := range list {
+		g.stmt(s)
+	}
+}
+
+func (g *cgen) stmt(s ast.Stmt) {
+	switch s := s.(type) {
+	case *ast.AssignStmt:
+		g.line("%s = %s;", g.expr(s.Target), g.expr(s.Value))
+	case *ast.IncDecStmt:
+		x := g.expr(s.X)
+		if s.Op == "++" {
+			g.line("%s = ADD(%s, 1);", x, x)
+		} else {
+			g.line("%s = SUB(%s, 1);", x, x)
+		}
+	case *ast.CallStmt:
+		g.line("%s;", g.expr(s.Call))
+	case *ast.IfStmt:
+		g.line("if (%s) {", unparen(g.expr(s.Cond)))
+		g.indent++
+		g.stmts(s.Then.List)
+		g.indent--
+		if s.Else != nil {
+			g.line("} else {")
+			g.indent++
+			g.stmts(s.Else.List)
+			g.indent--
+		}
+		g.line("}")
+	case *ast.WhileStmt:
+		g.line("while (%s) {", unparen(g.expr(s.Cond)))
+		g.indent++
+		g.stmts(s.Body.List)
+		g.indent--
+		g.line("}")
+	case *ast.PrintStmt:
+		for _, x := range s.Args {
+			g.line("print_%s(%s);", runtimeSuffix(g.info.TypeOf(x)), g.expr(x))
+		}
+		g.line("putchar('\\n');")
+	case *ast.ReadStmt:
+		for _, x := range s.Args {
+			g.line("%s = read_%s(%d);", g.expr(x), runtimeSuffix(g.info.TypeOf(x)), x.Pos().Line)
+		}
+	case *ast.BlockStmt:
+		g.line("{")
+		g.indent++
+		g.stmts(s.List)
+		g.indent--
+		g.line("}")
+	}
+}
+
+// runtimeSuffix Suffix of the print and read helpers of the runtime for a primitive type.
+func runtimeSuffix(t *Compiler.Type) string {
+	switch t.Kind {
+	case Compiler.RealType:
+		return "real"
+	case Compiler.StringType:
+		return "string"
+	case Compiler.BooleanType:
+		return "bool"
+	case Compiler.CharType:
+		return "char"
+	}
+	return "int"
+}
+
+// ====================================== EXPRESSIONS ======================================
+
+// expr C expression for x. Conversions from integer to real are left to C.
+func (g *cgen) expr(x ast.Expr) string {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		return cLiteral(x)
+	case *ast.Ident:
+		return cName(g.info.Uses[x])
+	case *ast.FieldExpr:
+		return g.expr(x.X) + "." + cName(g.info.Uses[x.Field])
+	case *ast.CallExpr:
+		var args []string
+		for _, arg := range x.Args {
+			args = append(args, g.expr(arg))
+		}
+		return fmt.Sprintf("%s(%s)", cName(g.info.Uses[x.Fun]), strings.Join(args, ", "))
+	case *ast.UnaryExpr:
+		return "!" + g.expr(x.X)
+	case *ast.IncDecExpr:
+		if x.Op == "++" {
+			return fmt.Sprintf("post_inc(&%s, 1)", g.expr(x.X))
+		}
+		return fmt.Sprintf("post_inc(&%s, -1)", g.expr(x.X))
+	case *ast.BinaryExpr:
+		return g.binary(x)
+	}
+	return "0"
+}
+
+func (g *cgen) binary(x *ast.BinaryExpr) string {
+	a, b := g.expr(x.X), g.expr(x.Y)
+	t, u := g.info.TypeOf(x.X), g.info.TypeOf(x.Y)
+	integer := t.Kind == Compiler.IntegerType && u.Kind == Compiler.IntegerType
+	switch {
+	case t.Kind == Compiler.StringType && x.Op == "==":
+		return fmt.Sprintf("str_eq(%s, %s)", a, b)
+	case t.Kind == Compiler.StringType:
+		return fmt.Sprintf("!str_eq(%s, %s)", a, b)
+	case integer && x.Op == "+":
+		return fmt.Sprintf("ADD(%s, %s)", a, b)
+	case integer && x.Op == "-":
+		return fmt.Sprintf("SUB(%s, %s)", a, b)
+	case integer && x.Op == "*":
+		return fmt.Sprintf("MUL(%s, %s)", a, b)
+	case integer && x.Op == "/":
+		return fmt.Sprintf("div_int(%s, %s, %d)", a, b, x.OpPos.Line)
+	case t.Kind == Compiler.RealType || u.Kind == Compiler.RealType:
+		if t.Kind == Compiler.IntegerType {
+			a = "(double)" + a
+		}
+		if u.Kind == Compiler.IntegerType {
+			b = "(double)" + b
+		}
+	}
+	return fmt.Sprintf("(%s %s %s)", a, x.Op, b)
+}
+
+// unparen Strips the parentheses around a binary expression, for the condition of if and while.
+func unparen(x string) string {
+	if strings.HasPrefix(x, "(") && strings.HasSuffix(x, ")") {
+		return x[1 : len(x)-1]
+	}
+	return x
+}
+
+func cLiteral(lit *ast.BasicLit) string {
+	switch v := lit.Literal.(type) {
+	case int64:
+		if v < math.MinInt32 || v > math.MaxInt32 {
+			return fmt.Sprintf("INT64_C(%d)", v)
+		}
+		return strconv.FormatInt(v, 10)
+	case float64:
+		s := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(s, ".eIN") {
+			s += ".0"
+		}
+		return s
+	case string:
+		return cString(v)
+	case rune:
+		if v >= ' ' && v < 0x7F && v != '\'' && v != '\\' {
+			return fmt.Sprintf("'%c'", v)
+		}
+		return strconv.Itoa(int(v))
+	case bool:
+		if v {
+			return "1"
+		}
+		return "0"
+	}
+	return "0"
+}
+
+// cString C string literal for s, also valid for the GNU assembler. Non printable and non ASCII
+// bytes are escaped in octal, as is '?' so no trigraph is formed.
+func cString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '"' || c == '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		case c < ' ' || c >= 0x7F || c == '?':
+			fmt.Fprintf(&b, "\\%03o", c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
@@ -0,0 +1,121 @@
+package codegen
+
+import (
+	"bytes"
+	Compiler "compiladores/Compiler/analyzer"
+	"compiladores/Compiler/ast"
+	"compiladores/Compiler/interpreter"
+	"flag"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the tests")
+
+// programs The sample programs compiled by the tests, with the input they read.
+var programs = []struct {
+	name  string
+	input string
+}{
+	{"sample", "5 2.5 x"},
+	{"operators", "true"},
+	{"registers", "q word name false 1.5"},
+	{"numbers", ""},
+	{"comments", ""},
+}
+
+// load Reads, parses and checks files/name.txt, failing the test on any error.
+func load(t *testing.T, name string) (*ast.Program, *Compiler.Info) {
+	t.Helper()
+	src, err := os.ReadFile(filepath.Join("..", "files", name+".txt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parser := Compiler.Syntax(Compiler.Lex(name, string(src)))
+	program := parser.Parse()
+	errs := append(parser.LexicalErrors(), parser.Errors()...)
+	analyzer := Compiler.Semantic(program)
+	info := analyzer.Analyze()
+	checker := Compiler.TypeCheck(program, info)
+	checker.Check()
+	errs = append(append(errs, analyzer.Errors()...), checker.Errors()...)
+	if Compiler.HasErrors(errs) {
+		t.Fatalf("%s does not check: %v", name, errs)
+	}
+	return program, info
+}
+
+// interpret The output of the program on the interpreter, up to its runtime error if any.
+func interpret(program *ast.Program, info *Compiler.Info, input string) string {
+	var out bytes.Buffer
+	interpreter.New(program, info, strings.NewReader(input), &out).Run()
+	return out.String()
+}
+
+// golden Compares the output of generate for files/name.txt with testdata/name.ext.golden; run the
+// tests with -update to rewrite it after changing the generator.
+func golden(t *testing.T, name, ext string, generate func(io.Writer, *ast.Program, *Compiler.Info) error) {
+	t.Helper()
+	program, info := load(t, name)
+	var b bytes.Buffer
+	if err := generate(&b, program, info); err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Join("testdata", name+ext+".golden")
+	if *update {
+		if err := os.WriteFile(file, b.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != string(want) {
+		t.Errorf("the output for %s differs from %s", name, file)
+	}
+}
+
+// run Runs the executable with input, returning its standard output.
+func run(t *testing.T, exe, input string) string {
+	t.Helper()
+	cmd := exec.Command(exe)
+	cmd.Stdin = strings.NewReader(input)
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	if err := cmd.Run(); err != nil {
+		if _, ok := err.(*exec.ExitError); !ok { // a runtime error exits with a status
+			t.Fatal(err)
+		}
+	}
+	return out.String()
+}
+
+// compiler The C compiler driver, $CC or cc; the test is skipped without one.
+func compiler(t *testing.T) string {
+	cc := os.Getenv("CC")
+	if cc == "" {
+		cc = "cc"
+	}
+	if _, err := exec.LookPath(cc); err != nil {
+		t.Skipf("no C compiler: %v", err)
+	}
+	return cc
+}
+
+func TestGenerateC(t *testing.T) {
+	for _, name := range []string{"sample", "registers"} {
+		golden(t, name, ".c", GenerateC)
+	}
+}
+
+// TestRunC Builds each sample program from its C, as Build does outside x86-64 Linux, and
+// compares what it prints with the interpreter.
+func TestRunC(t *testing.T) {
+	compiler(t)
+	testBuild(t, false)
+}
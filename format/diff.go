@@ -0,0 +1,140 @@
+package format
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// context Number of unchanged lines shown around the changes of a diff.
+const context = 3
+
+// edit A line of a diff: kept (' '), deleted from the old text ('-') or inserted in the new one ('+').
+type edit struct {
+	op   byte
+	text string
+}
+
+// Diff Returns the unified diff turning text a, named oldName, into text b, named newName, or nil
+// when they are equal.
+func Diff(oldName, newName string, a, b []byte) []byte {
+	if bytes.Equal(a, b) {
+		return nil
+	}
+	edits := diffLines(splitLines(string(a)), splitLines(string(b)))
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", oldName, newName)
+	oldLine, newLine := 1, 1 // of edits[i]
+	for i := 0; i < len(edits); {
+		if edits[i].op == ' ' {
+			oldLine, newLine = oldLine+1, newLine+1
+			i++
+			continue
+		}
+		// A hunk runs from the context before this change up to the context after the last change
+		// closer to the previous one than twice the context.
+		start := i
+		for start > 0 && i-start < context && edits[start-1].op == ' ' {
+			start--
+		}
+		end, kept := i, 0
+		for ; end < len(edits) && kept <= 2*context; end++ {
+			if edits[end].op == ' ' {
+				kept++
+			} else {
+				kept = 0
+			}
+		}
+		if kept > context {
+			end -= kept - context
+		}
+
+		oldStart, newStart := oldLine-(i-start), newLine-(i-start)
+		oldCount, newCount := 0, 0
+		var hunk bytes.Buffer
+		for _, e := range edits[start:end] {
+			if e.op != '+' {
+				oldCount++
+			}
+			if e.op != '-' {
+				newCount++
+			}
+			hunk.WriteByte(e.op)
+			hunk.WriteString(e.text)
+			if !strings.HasSuffix(e.text, "\n") {
+				hunk.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n", hunkRange(oldStart, oldCount), hunkRange(newStart, newCount))
+		out.Write(hunk.Bytes())
+
+		for _, e := range edits[i:end] {
+			if e.op != '+' {
+				oldLine++
+			}
+			if e.op != '-' {
+				newLine++
+			}
+		}
+		i = end
+	}
+	return out.Bytes()
+}
+
+// hunkRange Formats the lines of a hunk in one of the texts. An empty range names the line before it.
+func hunkRange(start, count int) string {
+	if count == 0 {
+		start--
+	}
+	if count == 1 {
+		return fmt.Sprint(start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// splitLines Splits text after each newline. The last line has no newline if the text does not end with one.
+func splitLines(text string) []string {
+	lines := strings.SplitAfter(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines Shortest edit script from a to b, from the longest common subsequence of their lines.
+func diffLines(a, b []string) []edit {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	var edits []edit
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i]})
+			i, j = i+1, j+1
+		case j == len(b) || i < len(a) && lcs[i+1][j] >= lcs[i][j+1]:
+			edits = append(edits, edit{'-', a[i]})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j]})
+			j++
+		}
+	}
+	return edits
+}
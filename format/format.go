@@ -0,0 +1,232 @@
+// Package format prints programs in the canonical layout of the language.
+package format
+
+import (
+	"bytes"
+	Compiler "compiladores/Compiler/analyzer"
+	"io"
+	"strings"
+)
+
+// printer Holds the state of the printing of a single program.
+type printer struct {
+	buf    bytes.Buffer
+	line   strings.Builder // the line being built, without its indentation
+	indent int             // of the line being built
+	braces []bool          // for each open brace, whether it indents the lines after it
+
+	continued bool // the statement of the line being built was broken by a line comment
+	blank     bool // a blank line goes before the next line
+	opened    bool // the last line written ends with an opening brace
+}
+
+// Fprint Writes the program of a concrete syntax tree in canonical form: one section, declaration or
+// command per line, indented by tabs, with single spaces around binary operators and a blank line
+// between the sections of the program. Comments are kept where they are, and single blank lines
+// between declarations or commands are kept too. The tree must be free of syntax errors.
+func Fprint(w io.Writer, tree *Compiler.SyntaxNode) error {
+	_, err := w.Write(Source(tree))
+	return err
+}
+
+// Source Returns the program of a concrete syntax tree in canonical form.
+func Source(tree *Compiler.SyntaxNode) []byte {
+	p := &printer{}
+	tokens := tree.Tokens()
+	for i, t := range tokens {
+		var prev, next *Compiler.SyntaxToken
+		if i > 0 {
+			prev = tokens[i-1]
+		}
+		if i+1 < len(tokens) {
+			next = tokens[i+1]
+		}
+		p.token(prev, t, next)
+	}
+	p.newline()
+	return p.buf.Bytes()
+}
+
+// sections Keywords starting the sections of a program.
+var sections = []string{"var", "const", "register", "procedure", "function", "main"}
+
+// startsSection Reports whether the token starts a section of a program.
+func startsSection(t *Compiler.SyntaxToken) bool {
+	return isAny(t, sections...)
+}
+
+func (p *printer) token(prev, t, next *Compiler.SyntaxToken) {
+	if len(p.braces) == 0 && startsSection(t) {
+		p.blank = true
+	}
+	p.leading(t.Leading)
+	if t.IsEOF() {
+		return
+	}
+
+	if t.Is("}") {
+		if n := len(p.braces) - 1; n >= 0 {
+			if p.braces[n] {
+				p.newline()
+				p.blank = false
+			}
+			p.braces = p.braces[:n]
+		}
+	}
+	p.write(t.Value, prev != nil && space(prev, t))
+
+	broken := p.trailing(t.Trailing)
+	switch {
+	case t.Is(";"):
+		p.end()
+	case t.Is("{"):
+		empty := next != nil && next.Is("}") && !broken && !hasComments(next.Leading)
+		p.braces = append(p.braces, !empty)
+		if !empty {
+			p.end()
+		}
+	case t.Is("}"):
+		if next == nil || !next.Is("else") || broken {
+			p.end()
+		}
+	case broken:
+		p.continued = true
+	}
+}
+
+// leading Writes the comments before a token, on lines of their own unless the token follows them
+// on the same line. Blank lines before and between them are kept.
+func (p *printer) leading(list []Compiler.Trivia) {
+	comment := false // the line being built holds only comments
+	ended := true    // the source line before the trivia ended, the next newline leaves a blank line
+	for i, tr := range list {
+		switch tr.Kind {
+		case Compiler.TriviaNewline:
+			if ended && p.line.Len() == 0 { // line breaks inside a declaration or command are not kept
+				p.blank = true
+			}
+			ended = true
+		case Compiler.TriviaBlockComment, Compiler.TriviaLineComment:
+			if p.line.Len() > 0 && !comment {
+				p.newline()
+				p.continued = true
+			}
+			comment, ended = true, false
+			p.write(commentText(tr), true)
+			if tr.Kind == Compiler.TriviaLineComment || endsLine(list[i+1:]) {
+				p.newline()
+			}
+		}
+	}
+}
+
+// trailing Writes the comments after a token on its line, reporting whether the line had to end
+// after a line comment.
+func (p *printer) trailing(list []Compiler.Trivia) bool {
+	for _, tr := range list {
+		switch tr.Kind {
+		case Compiler.TriviaBlockComment:
+			p.write(commentText(tr), true)
+		case Compiler.TriviaLineComment:
+			p.write(tr.Text, true)
+			p.newline()
+			return true
+		}
+	}
+	return false
+}
+
+// commentText Text of a comment as written: the lines of a block end in "\n", as the others do.
+func commentText(tr Compiler.Trivia) string {
+	return strings.ReplaceAll(tr.Text, "\r\n", "\n")
+}
+
+// write Appends text to the line being built, after a space if requested.
+func (p *printer) write(text string, space bool) {
+	if p.line.Len() == 0 {
+		if p.blank && p.buf.Len() > 0 && !p.opened {
+			p.buf.WriteByte('\n')
+		}
+		p.blank = false
+		p.indent = indentation(p.braces)
+		if p.continued {
+			p.indent++
+		}
+	} else if space {
+		p.line.WriteByte(' ')
+	}
+	p.line.WriteString(text)
+}
+
+// newline Ends the line being built, if any.
+func (p *printer) newline() {
+	if p.line.Len() == 0 {
+		return
+	}
+	text := p.line.String()
+	p.buf.WriteString(strings.Repeat("\t", p.indent))
+	p.buf.WriteString(text)
+	p.buf.WriteByte('\n')
+	p.opened = strings.HasSuffix(text, "{")
+	p.line.Reset()
+}
+
+// end Ends the line at the end of a declaration, a command or a brace.
+func (p *printer) end() {
+	p.newline()
+	p.continued = false
+}
+
+// indentation Number of open braces that indent, all but those of empty sections.
+func indentation(braces []bool) int {
+	n := 0
+	for _, indents := range braces {
+		if indents {
+			n++
+		}
+	}
+	return n
+}
+
+// space Reports whether a space separates two tokens on the same line.
+func space(prev, t *Compiler.SyntaxToken) bool {
+	switch {
+	case isAny(t, ";", ",", ")", ".", "++", "--"):
+		return false
+	case t.Is("("): // calls, but not if and while
+		return isAny(prev, "if", "while")
+	}
+	return !isAny(prev, "(", ".", "!")
+}
+
+// isAny Reports whether the token is one of the keywords, operators or delimiters spelled texts.
+func isAny(t *Compiler.SyntaxToken, texts ...string) bool {
+	for _, text := range texts {
+		if t.Is(text) {
+			return true
+		}
+	}
+	return false
+}
+
+func hasComments(list []Compiler.Trivia) bool {
+	for _, tr := range list {
+		if tr.Kind == Compiler.TriviaBlockComment || tr.Kind == Compiler.TriviaLineComment {
+			return true
+		}
+	}
+	return false
+}
+
+// endsLine Reports whether the rest of the trivia has a newline before the next comment.
+func endsLine(list []Compiler.Trivia) bool {
+	for _, tr := range list {
+		switch tr.Kind {
+		case Compiler.TriviaNewline:
+			return true
+		case Compiler.TriviaBlockComment, Compiler.TriviaLineComment:
+			return false
+		}
+	}
+	return false
+}
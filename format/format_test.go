@@ -0,0 +1,118 @@
+package format
+
+import (
+	Compiler "compiladores/Compiler/analyzer"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// source Formats src, failing the test if it does not parse.
+func source(t *testing.T, src string) string {
+	t.Helper()
+	parser := Compiler.Syntax(Compiler.Lex("test", src))
+	parser.Parse()
+	if errs := append(parser.LexicalErrors(), parser.Errors()...); Compiler.HasErrors(errs) {
+		t.Fatalf("%q does not parse: %v", src, errs)
+	}
+	return string(Source(parser.Tree()))
+}
+
+var canonical = []struct {
+	input, want string
+}{
+	{
+		"program P;var{integer x,y;}const{}main{var{}x=1;if(x>0){print(x);}else{x++;}}",
+		"program P;\n\nvar {\n\tinteger x, y;\n}\n\nconst { }\n\nmain {\n\tvar { }\n\tx = 1;\n\tif (x > 0) {\n\t\tprint(x);\n\t} else {\n\t\tx++;\n\t}\n}\n",
+	},
+	{
+		"program  P ; % c1\nvar { } /# c2 #/ const { }\nmain { var { }\n  while (x<1) { x=x+1; } % c3\n}",
+		"program P; % c1\n\nvar { } /# c2 #/\n\nconst { }\n\nmain {\n\tvar { }\n\twhile (x < 1) {\n\t\tx = x + 1;\n\t} % c3\n}\n",
+	},
+	{
+		"program P; var { } const { } register R { integer a; } procedure Q(R r, integer k) { var { } read(r.a); } " +
+			"function F(integer k) : integer { var { } return k; } main { var { } Q(s, 1); x = F(2); }",
+		"program P;\n\nvar { }\n\nconst { }\n\nregister R {\n\tinteger a;\n}\n\nprocedure Q(R r, integer k) {\n\tvar { }\n\tread(r.a);\n}\n\n" +
+			"function F(integer k) : integer {\n\tvar { }\n\treturn k;\n}\n\nmain {\n\tvar { }\n\tQ(s, 1);\n\tx = F(2);\n}\n",
+	},
+	{
+		"program P;\r\nvar { }\r\nconst { }\r\nmain {\r\n\tvar { }\r\n\tx = 1; % one\r\n}\r\n",
+		"program P;\n\nvar { }\n\nconst { }\n\nmain {\n\tvar { }\n\tx = 1; % one\n}\n",
+	},
+}
+
+func TestCanonical(t *testing.T) {
+	for _, test := range canonical {
+		if got := source(t, test.input); got != test.want {
+			t.Errorf("%q formats as\n%s\nwant\n%s", test.input, got, test.want)
+		}
+	}
+}
+
+// TestIdempotent Checks that formatting canonical text leaves it as it is.
+func TestIdempotent(t *testing.T) {
+	inputs := []string{}
+	for _, test := range canonical {
+		inputs = append(inputs, test.input)
+	}
+	files, _ := filepath.Glob("../files/*.txt")
+	for _, file := range files {
+		src, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputs = append(inputs, string(src), strings.ReplaceAll(string(src), "\n", "\r\n"))
+	}
+	for _, input := range inputs {
+		once := source(t, input)
+		if twice := source(t, once); twice != once {
+			t.Errorf("formatting again changes\n%s\ninto\n%s", once, twice)
+		}
+	}
+}
+
+// TestComments Checks that every comment is kept, in its order.
+func TestComments(t *testing.T) {
+	src, err := os.ReadFile("../files/comments.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	comments := func(src string) []string {
+		var list []string
+		tokens, _ := Compiler.Tokens(Compiler.Lex("test", src))
+		for _, tok := range tokens {
+			if tok.IsComment() {
+				list = append(list, tok.Value)
+			}
+		}
+		return list
+	}
+	want := comments(string(src))
+	got := comments(source(t, strings.ReplaceAll(string(src), "\n", "\r\n")))
+	if strings.Join(got, "\x00") != strings.Join(want, "\x00") {
+		t.Errorf("the comments became\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		a, b, want string
+	}{
+		{"a\nb\n", "a\nb\n", ""},
+		{"a\nb\nc\n", "a\nB\nc\n", "--- old\n+++ new\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n"},
+		{"a\n", "a\nb\n", "--- old\n+++ new\n@@ -1 +1,2 @@\n a\n+b\n"},
+		{"a\nb\n", "b\n", "--- old\n+++ new\n@@ -1,2 +1 @@\n-a\n b\n"},
+		{"a", "a\n", "--- old\n+++ new\n@@ -1 +1 @@\n-a\n\\ No newline at end of file\n+a\n"},
+		{
+			"1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n", "1\nX\n3\n4\n5\n6\n7\n8\n9\n10\nY\n12\n",
+			"--- old\n+++ new\n@@ -1,5 +1,5 @@\n 1\n-2\n+X\n 3\n 4\n 5\n@@ -8,5 +8,5 @@\n 8\n 9\n 10\n-11\n+Y\n 12\n",
+		},
+		{"", "a\n", "--- old\n+++ new\n@@ -0,0 +1 @@\n+a\n"},
+	}
+	for _, test := range tests {
+		if got := string(Diff("old", "new", []byte(test.a), []byte(test.b))); got != test.want {
+			t.Errorf("Diff(%q, %q) =\n%s\nwant\n%s", test.a, test.b, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,374 @@
+package grammar
+
+import (
+	Compiler "compiladores/Compiler/analyzer"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Analysis The FIRST and FOLLOW sets of a grammar, its LL(1) parse table and what keeps it from
+// being LL(1).
+type Analysis struct {
+	Grammar *Grammar
+
+	// Table The production to expand each nonterminal by, for each terminal that may come next.
+	// Where productions conflict the first in the file is kept.
+	Table     map[string]map[string]*Production
+	Conflicts []Conflict
+	Cycles    [][]string // of left recursion, each from a nonterminal back to itself
+
+	nullable map[string]bool
+	first    map[string]set
+	follow   map[string]set
+	edges    map[string][]Symbol // nonterminals each nonterminal may start with
+}
+
+// Conflict Two productions of a nonterminal both predicted by the same terminal.
+type Conflict struct {
+	Terminal string
+	Chosen   *Production // the one in the table
+	Other    *Production
+}
+
+type set map[string]bool
+
+// add Adds the terminals of t to s, and reports whether s changed.
+func (s set) add(t set) bool {
+	changed := false
+	for x := range t {
+		if !s[x] {
+			s[x] = true
+			changed = true
+		}
+	}
+	return changed
+}
+
+// Analyze Computes the FIRST and FOLLOW sets of the grammar, its parse table and its cycles of
+// left recursion.
+func Analyze(g *Grammar) *Analysis {
+	a := &Analysis{
+		Grammar:  g,
+		Table:    make(map[string]map[string]*Production),
+		nullable: make(map[string]bool),
+		first:    make(map[string]set),
+		follow:   make(map[string]set),
+		edges:    make(map[string][]Symbol),
+	}
+	for _, nt := range g.Nonterminals {
+		a.first[nt] = make(set)
+		a.follow[nt] = make(set)
+	}
+	a.follow[g.Start] = set{EOF: true}
+
+	for changed := true; changed; {
+		changed = false
+		for _, p := range g.Productions {
+			first, nullable := a.firstOf(p.Right)
+			if a.first[p.Left].add(first) {
+				changed = true
+			}
+			if nullable && !a.nullable[p.Left] {
+				a.nullable[p.Left] = true
+				changed = true
+			}
+		}
+	}
+	for changed := true; changed; {
+		changed = false
+		for _, p := range g.Productions {
+			for i, s := range p.Right {
+				if s.Terminal || g.rules[s.Name] == nil {
+					continue
+				}
+				first, nullable := a.firstOf(p.Right[i+1:])
+				if a.follow[s.Name].add(first) {
+					changed = true
+				}
+				if nullable && a.follow[s.Name].add(a.follow[p.Left]) {
+					changed = true
+				}
+			}
+		}
+	}
+
+	for _, p := range g.Productions {
+		row := a.Table[p.Left]
+		if row == nil {
+			row = make(map[string]*Production)
+			a.Table[p.Left] = row
+		}
+		for _, t := range a.Predict(p) {
+			if chosen := row[t]; chosen != nil {
+				a.Conflicts = append(a.Conflicts, Conflict{t, chosen, p})
+			} else {
+				row[t] = p
+			}
+		}
+		for _, s := range p.Right {
+			if s.Terminal {
+				break
+			}
+			a.edges[p.Left] = append(a.edges[p.Left], s)
+			if !a.nullable[s.Name] {
+				break
+			}
+		}
+	}
+	a.Cycles = a.cycles()
+	return a
+}
+
+// firstOf The terminals starting the strings the symbols derive, and whether they may derive the
+// empty string.
+func (a *Analysis) firstOf(symbols []Symbol) (set, bool) {
+	first := make(set)
+	for _, s := range symbols {
+		if s.Terminal {
+			first[s.Name] = true
+			return first, false
+		}
+		first.add(a.first[s.Name])
+		if !a.nullable[s.Name] {
+			return first, false
+		}
+	}
+	return first, true
+}
+
+// sorted The terminals of s in the order of Grammar.Terminals, EOF last.
+func (a *Analysis) sorted(s set) []string {
+	list := make([]string, 0, len(s))
+	for t := range s {
+		list = append(list, t)
+	}
+	index := a.Grammar.index
+	sort.Slice(list, func(i, j int) bool {
+		if list[i] == EOF || list[j] == EOF {
+			return list[j] == EOF && list[i] != EOF
+		}
+		return index[list[i]] < index[list[j]]
+	})
+	return list
+}
+
+// Nullable Reports whether the nonterminal derives the empty string.
+func (a *Analysis) Nullable(nonterminal string) bool {
+	return a.nullable[nonterminal]
+}
+
+// First The terminals starting the strings the nonterminal derives.
+func (a *Analysis) First(nonterminal string) []string {
+	return a.sorted(a.first[nonterminal])
+}
+
+// Follow The terminals that may follow the nonterminal, EOF included.
+func (a *Analysis) Follow(nonterminal string) []string {
+	return a.sorted(a.follow[nonterminal])
+}
+
+// Predict The terminals that may come next when the production applies: those starting its right
+// side and, when that may derive the empty string, those following its nonterminal.
+func (a *Analysis) Predict(p *Production) []string {
+	first, nullable := a.firstOf(p.Right)
+	if nullable {
+		first.add(a.follow[p.Left])
+	}
+	return a.sorted(first)
+}
+
+// cycles Finds the cycles of left recursion: nonterminals that derive strings starting with
+// themselves. Each cycle is reported once, from the first of its nonterminals in the file.
+func (a *Analysis) cycles() [][]string {
+	var list [][]string
+	reported := make(map[string]bool)
+	for _, nt := range a.Grammar.Nonterminals {
+		if reported[nt] {
+			continue
+		}
+		// Breadth-first search of the shortest path from nt back to itself.
+		from := map[string]string{}
+		queue := []string{nt}
+		for len(queue) > 0 && from[nt] == "" {
+			x := queue[0]
+			queue = queue[1:]
+			for _, s := range a.edges[x] {
+				if _, seen := from[s.Name]; !seen {
+					from[s.Name] = x
+					queue = append(queue, s.Name)
+				}
+			}
+		}
+		if from[nt] == "" {
+			continue
+		}
+		cycle := []string{nt}
+		for x := from[nt]; x != nt; x = from[x] {
+			cycle = append(cycle, x)
+		}
+		cycle = append(cycle, nt)
+		for i, j := 0, len(cycle)-1; i < j; i, j = i+1, j-1 {
+			cycle[i], cycle[j] = cycle[j], cycle[i]
+		}
+		for _, x := range cycle {
+			reported[x] = true
+		}
+		list = append(list, cycle)
+	}
+	return list
+}
+
+// ====================================== PROBLEMS ======================================
+
+// Problems Reports what keeps the grammar from being LL(1), or from being parsed with the tokens of
+// the lexer, as errors: nonterminals without rules, terminals the lexer has no token for, left
+// recursion and conflicts. Nonterminals the start symbol never leads to are warnings.
+func (a *Analysis) Problems() []Compiler.Diagnostic {
+	g := a.Grammar
+	var list []Compiler.Diagnostic
+	if g.rules[g.Start] == nil {
+		list = append(list, g.diagnostic(g.uses[g.Start].pos, Compiler.SeverityError, CodeUndefined,
+			"the start symbol %s has no rule", g.Start))
+	}
+	for _, p := range g.Productions {
+		for _, s := range p.Right {
+			if !s.Terminal && g.rules[s.Name] == nil && g.uses[s.Name].pos == s.pos && s.Name != g.Start {
+				list = append(list, g.diagnostic(s.pos, Compiler.SeverityError, CodeUndefined, "%s has no rule", s.Name))
+			}
+		}
+	}
+	for _, t := range g.Terminals {
+		if !Lexes(t, g.named[t]) {
+			what := "is not a keyword, operator or delimiter of the lexer"
+			if g.named[t] {
+				what = "has no counterpart among the tokens of the lexer"
+			}
+			list = append(list, g.diagnostic(g.uses[t].pos, Compiler.SeverityError, CodeUnknownTerminal, "terminal %s %s", g.Display(t), what))
+		}
+	}
+	reachable := a.reachable()
+	for _, nt := range g.Nonterminals {
+		if !reachable[nt] {
+			list = append(list, g.diagnostic(g.uses[nt].pos, Compiler.SeverityWarning, CodeUnreachable,
+				"%s is unreachable from the start symbol %s", nt, g.Start))
+		}
+	}
+
+	for _, cycle := range a.Cycles {
+		var at Symbol
+		for _, s := range a.edges[cycle[0]] {
+			if s.Name == cycle[1] {
+				at = s
+				break
+			}
+		}
+		list = append(list, g.diagnostic(at.pos, Compiler.SeverityError, CodeLeftRecursion,
+			"%s is left-recursive: %s", cycle[0], strings.Join(cycle, " -> ")))
+	}
+
+	// A single error for each pair of conflicting productions, naming every terminal they share.
+	type pair struct{ chosen, other *Production }
+	var pairs []pair
+	shared := make(map[pair][]string)
+	for _, c := range a.Conflicts {
+		k := pair{c.Chosen, c.Other}
+		if shared[k] == nil {
+			pairs = append(pairs, k)
+		}
+		shared[k] = append(shared[k], c.Terminal)
+	}
+	for _, k := range pairs {
+		list = append(list, g.diagnostic(k.other.pos, Compiler.SeverityError, CodeConflict, "%s", a.conflict(k.chosen, k.other, shared[k])))
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		return list[i].Span.Start < list[j].Span.Start
+	})
+	return list
+}
+
+// conflict Describes a conflict between two productions of the same nonterminal on the terminals.
+func (a *Analysis) conflict(chosen, other *Production, terminals []string) string {
+	g := a.Grammar
+	first1, _ := a.firstOf(chosen.Right)
+	first2, _ := a.firstOf(other.Right)
+	var starting, following []string
+	for _, t := range terminals {
+		if first1[t] && first2[t] {
+			starting = append(starting, g.Display(t))
+		} else {
+			following = append(following, g.Display(t))
+		}
+	}
+	var parts []string
+	if len(starting) > 0 {
+		parts = append(parts, "both start with "+join(starting, "and"))
+	}
+	if len(following) > 0 {
+		parts = append(parts, "are both chosen by "+join(following, "and")+", which may follow "+chosen.Left)
+	}
+	return fmt.Sprintf("alternatives %s and %s of %s %s", a.alternative(chosen), a.alternative(other), chosen.Left, strings.Join(parts, " and "))
+}
+
+// alternative Names a production in messages by its number among the alternatives of its rule.
+func (a *Analysis) alternative(p *Production) string {
+	n := 0
+	for i, q := range a.Grammar.rules[p.Left] {
+		if q == p {
+			n = i + 1
+		}
+	}
+	if len(p.Right) == 0 {
+		return fmt.Sprintf("%d (empty)", n)
+	}
+	return fmt.Sprint(n)
+}
+
+// join Joins the words with commas, the last two with conj.
+func join(words []string, conj string) string {
+	if len(words) < 2 {
+		return strings.Join(words, "")
+	}
+	return strings.Join(words[:len(words)-1], ", ") + " " + conj + " " + words[len(words)-1]
+}
+
+// reachable The nonterminals the start symbol leads to.
+func (a *Analysis) reachable() map[string]bool {
+	g := a.Grammar
+	seen := map[string]bool{g.Start: true}
+	stack := []string{g.Start}
+	for len(stack) > 0 {
+		nt := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, p := range g.rules[nt] {
+			for _, s := range p.Right {
+				if !s.Terminal && !seen[s.Name] {
+					seen[s.Name] = true
+					stack = append(stack, s.Name)
+				}
+			}
+		}
+	}
+	return seen
+}
+
+// diagnostic A problem found at a position of the grammar file.
+func (g *Grammar) diagnostic(p pos, severity Compiler.Severity, code, format string, args ...interface{}) Compiler.Diagnostic {
+	return newDiagnostic(g.File, p, severity, code, format, args...)
+}
+
+func newDiagnostic(file string, p pos, severity Compiler.Severity, code, format string, args ...interface{}) Compiler.Diagnostic {
+	if p.line == 0 { // the whole file
+		p.line, p.column = 1, 1
+	}
+	return Compiler.Diagnostic{
+		Severity: severity,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+		File:     file,
+		Line:     p.line,
+		Column:   p.column,
+		Span:     Compiler.Span{Start: p.offset, End: p.end},
+	}
+}
@@ -0,0 +1,155 @@
+package grammar
+
+import (
+	Compiler "compiladores/Compiler/analyzer"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+// analyze Reads a grammar whose start symbol is <S> from its rules and analyzes it.
+func analyze(t *testing.T, rules string) *Analysis {
+	t.Helper()
+	g, errs := Parse("test.grm", "\"Start Symbol\" = <S>\n\n"+rules)
+	if g == nil {
+		t.Fatalf("the grammar does not parse: %v", errs)
+	}
+	return Analyze(g)
+}
+
+// codes The codes of the diagnostics.
+func codes(list []Compiler.Diagnostic) string {
+	var codes []string
+	for _, d := range list {
+		codes = append(codes, d.Code)
+	}
+	return strings.Join(codes, " ")
+}
+
+// expressions The classic LL(1) grammar of expressions.
+const expressions = `
+<S>  ::= <T> <S'>
+<S'> ::= '+' <T> <S'> |
+<T>  ::= <F> <T'>
+<T'> ::= '*' <F> <T'> |
+<F>  ::= '(' <S> ')' | id
+`
+
+func TestFirstFollow(t *testing.T) {
+	a := analyze(t, expressions)
+	tests := []struct {
+		nonterminal   string
+		nullable      bool
+		first, follow string
+	}{
+		{"<S>", false, "( id", ") (EOF)"},
+		{"<S'>", true, "+", ") (EOF)"},
+		{"<T>", false, "( id", "+ ) (EOF)"},
+		{"<T'>", true, "*", "+ ) (EOF)"},
+		{"<F>", false, "( id", "+ * ) (EOF)"},
+	}
+	for _, test := range tests {
+		if nullable := a.Nullable(test.nonterminal); nullable != test.nullable {
+			t.Errorf("%s nullable is %v, want %v", test.nonterminal, nullable, test.nullable)
+		}
+		if first := strings.Join(a.First(test.nonterminal), " "); first != test.first {
+			t.Errorf("FIRST(%s) = %s, want %s", test.nonterminal, first, test.first)
+		}
+		if follow := strings.Join(a.Follow(test.nonterminal), " "); follow != test.follow {
+			t.Errorf("FOLLOW(%s) = %s, want %s", test.nonterminal, follow, test.follow)
+		}
+	}
+	if len(a.Conflicts) > 0 || len(a.Cycles) > 0 {
+		t.Errorf("got conflicts %v and cycles %v, want none", a.Conflicts, a.Cycles)
+	}
+
+	// The empty alternative is chosen by what follows the nonterminal.
+	empty := a.Grammar.Rules("<T'>")[1]
+	if predict := strings.Join(a.Predict(empty), " "); predict != "+ ) (EOF)" {
+		t.Errorf("the empty alternative of <T'> is predicted by %s, want + ) (EOF)", predict)
+	}
+	for _, terminal := range []string{"+", ")", EOF} {
+		if a.Table["<T'>"][terminal] != empty {
+			t.Errorf("the table expands <T'> on %s by %v", terminal, a.Table["<T'>"][terminal])
+		}
+	}
+}
+
+func TestConflicts(t *testing.T) {
+	tests := []struct {
+		rules    string
+		terminal string
+		message  string
+	}{
+		{
+			"<S> ::= while ';' | while ','\n",
+			"while", "alternatives 1 and 2 of <S> both start with 'while'",
+		},
+		{
+			"<S> ::= <A> ';'\n<A> ::= ';' |\n",
+			";", "alternatives 1 and 2 (empty) of <A> are both chosen by ';', which may follow <A>",
+		},
+		{
+			"<S> ::= if '(' ')' <S> <E> | ';'\n<E> ::= else <S> |\n", // the dangling else
+			"else", "alternatives 1 and 2 (empty) of <E> are both chosen by 'else', which may follow <E>",
+		},
+	}
+	for _, test := range tests {
+		a := analyze(t, test.rules)
+		if len(a.Conflicts) != 1 || a.Conflicts[0].Terminal != test.terminal {
+			t.Errorf("%q: got conflicts %v, want one on %s", test.rules, a.Conflicts, test.terminal)
+			continue
+		}
+		if c := a.Conflicts[0]; a.Table[c.Chosen.Left][test.terminal] != c.Chosen || c.Chosen.Index > c.Other.Index {
+			t.Errorf("%q: the table does not keep the first alternative", test.rules)
+		}
+		problems := a.Problems()
+		if codes(problems) != CodeConflict || problems[0].Message != test.message {
+			t.Errorf("%q: got %v, want %q", test.rules, problems, test.message)
+		}
+	}
+}
+
+func TestLeftRecursion(t *testing.T) {
+	tests := []struct {
+		rules string
+		cycle string
+	}{
+		{"<S> ::= <S> '+' x | x\n", "<S> <S>"},
+		{"<S> ::= <A> x\n<A> ::= <B> y | z\n<B> ::= <S> w\n", "<S> <A> <B> <S>"},
+		{"<S> ::= <N> <S> x | y\n<N> ::= n |\n", "<S> <S>"}, // hidden by a nullable nonterminal
+	}
+	for _, test := range tests {
+		a := analyze(t, test.rules)
+		if len(a.Cycles) != 1 || strings.Join(a.Cycles[0], " ") != test.cycle {
+			t.Errorf("%q: got cycles %v, want %s", test.rules, a.Cycles, test.cycle)
+		}
+		problems := a.Problems()
+		if !strings.Contains(codes(problems), CodeLeftRecursion) {
+			t.Errorf("%q: got %v, want left recursion", test.rules, problems)
+		}
+		if err := WriteGo(ioutil.Discard, a, "parser"); err == nil {
+			t.Errorf("%q: generated a parser of a left-recursive grammar", test.rules)
+		}
+	}
+
+	// Recursion that is not leftmost is fine.
+	if a := analyze(t, expressions+"<G> ::= '(' <G> ')' |\n"); len(a.Cycles) > 0 {
+		t.Errorf("got cycles %v, want none", a.Cycles)
+	}
+}
+
+func TestProblems(t *testing.T) {
+	a := analyze(t, "<S> ::= while <A> '@'\n<B> ::= Identifier\n")
+	problems := a.Problems()
+	want := CodeUndefined + " " + CodeUnknownTerminal + " " + CodeUnreachable + " " + CodeUnknownTerminal
+	if codes(problems) != want {
+		t.Fatalf("got %v, want the codes %s", problems, want)
+	}
+	if problems[2].Severity != Compiler.SeverityWarning {
+		t.Errorf("an unreachable nonterminal is an error: %v", problems[2])
+	}
+	if err := WriteGo(ioutil.Discard, a, "parser"); err == nil || err.Error() != "<A> has no rule" {
+		t.Errorf("got error %v, want <A> has no rule", err)
+	}
+}
@@ -0,0 +1,288 @@
+package grammar
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/format"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// WriteReport Writes the FIRST and FOLLOW sets of each nonterminal, after a summary of the grammar.
+func WriteReport(w io.Writer, a *Analysis) error {
+	g := a.Grammar
+	var b bytes.Buffer
+	name := g.Name
+	if name == "" {
+		name = filepath.Base(g.File)
+	}
+	fmt.Fprintf(&b, "%s: start symbol %s, %d nonterminals, %d terminals, %d productions\n",
+		name, g.Start, len(g.Nonterminals), len(g.Terminals), len(g.Productions))
+	switch {
+	case len(a.Cycles) > 0:
+		fmt.Fprintf(&b, "not LL(1), conflicts in the parse table: %d, cycles of left recursion: %d\n", len(a.Conflicts), len(a.Cycles))
+	case len(a.Conflicts) > 0:
+		fmt.Fprintf(&b, "not LL(1), conflicts in the parse table: %d\n", len(a.Conflicts))
+	default:
+		b.WriteString("LL(1)\n")
+	}
+	display := func(list []string) string {
+		for i, t := range list {
+			list[i] = g.Display(t)
+		}
+		return strings.Join(list, " ")
+	}
+	for _, nt := range g.Nonterminals {
+		b.WriteString("\n" + nt)
+		if a.nullable[nt] {
+			b.WriteString(" (nullable)")
+		}
+		b.WriteString("\n" + strings.TrimRight("  first:  "+display(a.First(nt)), " "))
+		b.WriteString("\n" + strings.TrimRight("  follow: "+display(a.Follow(nt)), " ") + "\n")
+	}
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// analysis The analysis in the JSON form WriteJSON writes. Symbols are named as in Grammar.
+type analysis struct {
+	Name         string                    `json:"name"`
+	Start        string                    `json:"start"`
+	Terminals    []terminal                `json:"terminals"` // EOF last
+	Nonterminals []nonterminal             `json:"nonterminals"`
+	Productions  []production              `json:"productions"`
+	Table        map[string]map[string]int `json:"table"` // index of the production for each nonterminal and terminal
+	Conflicts    []conflict                `json:"conflicts"`
+}
+
+type terminal struct {
+	Name  string `json:"name"`
+	Named bool   `json:"named"` // stands for the tokens of a definition rather than for its text
+}
+
+type nonterminal struct {
+	Name     string   `json:"name"`
+	Nullable bool     `json:"nullable"`
+	First    []string `json:"first"`
+	Follow   []string `json:"follow"`
+}
+
+type production struct {
+	Left  string   `json:"left"`
+	Right []string `json:"right"`
+}
+
+type conflict struct {
+	Nonterminal string `json:"nonterminal"`
+	Terminal    string `json:"terminal"`
+	Chosen      int    `json:"chosen"`
+	Other       int    `json:"other"`
+}
+
+// WriteJSON Writes the analysis of the grammar as JSON: its symbols, the FIRST and FOLLOW sets of
+// the nonterminals, the productions, the LL(1) parse table and the conflicts left out of it.
+func WriteJSON(w io.Writer, a *Analysis) error {
+	g := a.Grammar
+	t := analysis{
+		Name:         g.Name,
+		Start:        g.Start,
+		Terminals:    []terminal{},
+		Nonterminals: []nonterminal{},
+		Productions:  []production{},
+		Table:        make(map[string]map[string]int),
+		Conflicts:    []conflict{},
+	}
+	for _, name := range append(append([]string(nil), g.Terminals...), EOF) {
+		t.Terminals = append(t.Terminals, terminal{name, g.named[name] || name == EOF})
+	}
+	for _, nt := range g.Nonterminals {
+		t.Nonterminals = append(t.Nonterminals, nonterminal{nt, a.nullable[nt], a.First(nt), a.Follow(nt)})
+	}
+	for _, p := range g.Productions {
+		right := []string{}
+		for _, s := range p.Right {
+			right = append(right, s.Name)
+		}
+		t.Productions = append(t.Productions, production{p.Left, right})
+	}
+	for nt, row := range a.Table {
+		t.Table[nt] = make(map[string]int)
+		for terminal, p := range row {
+			t.Table[nt][terminal] = p.Index
+		}
+	}
+	for _, c := range a.Conflicts {
+		t.Conflicts = append(t.Conflicts, conflict{c.Chosen.Left, c.Terminal, c.Chosen.Index, c.Other.Index})
+	}
+	e := json.NewEncoder(w)
+	e.SetEscapeHTML(false)
+	e.SetIndent("", "  ")
+	return e.Encode(t)
+}
+
+// WriteGo Writes a recursive-descent parser of the grammar in Go, as package pkg: a Parse function
+// recognizing the tokens of a program and a method for each nonterminal, which chooses its
+// production by the next token as the parse table does, the first of the conflicting productions
+// winning. The grammar must have a rule for every nonterminal and no left recursion, as the parser
+// would never end.
+func WriteGo(w io.Writer, a *Analysis, pkg string) error {
+	g := a.Grammar
+	for _, p := range g.Productions {
+		for _, s := range p.Right {
+			if !s.Terminal && g.rules[s.Name] == nil {
+				return fmt.Errorf("%s has no rule", s.Name)
+			}
+		}
+	}
+	if g.rules[g.Start] == nil {
+		return fmt.Errorf("the start symbol %s has no rule", g.Start)
+	}
+	if len(a.Cycles) > 0 {
+		return fmt.Errorf("%s is left-recursive", a.Cycles[0][0])
+	}
+
+	names := make(map[string]string) // of the method of each nonterminal
+	taken := make(map[string]bool)
+	for _, nt := range g.Nonterminals {
+		name := methodName(nt)
+		for i := 2; taken[name]; i++ {
+			name = methodName(nt) + strconv.Itoa(i)
+		}
+		names[nt] = name
+		taken[name] = true
+	}
+	literal := func(terminal string) string {
+		if terminal == EOF {
+			return "grammar.EOF"
+		}
+		return strconv.Quote(terminal)
+	}
+	literals := func(terminals []string) string {
+		list := make([]string, len(terminals))
+		for i, t := range terminals {
+			list[i] = literal(t)
+		}
+		return strings.Join(list, ", ")
+	}
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by compiler grammar from %s. DO NOT EDIT.\n\n", filepath.Base(g.File))
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	b.WriteString("import (\n\tCompiler \"compiladores/Compiler/analyzer\"\n\t\"compiladores/Compiler/grammar\"\n)\n\n")
+	fmt.Fprintf(&b, "// Parse Recognizes the tokens of a program, as delivered by Compiler.Tokens, as a %s and returns\n", g.Start)
+	b.WriteString("// the first syntax error, if any.\n")
+	b.WriteString("func Parse(file string, tokens []Compiler.Token) []Compiler.Diagnostic {\n")
+	b.WriteString("\tp := parser{grammar.NewRecognizer(file, tokens)}\n")
+	fmt.Fprintf(&b, "\tp.%s()\n\tp.Match(grammar.EOF)\n\treturn p.Errors()\n}\n\n", names[g.Start])
+	b.WriteString("// parser Recognizes a nonterminal with each of its methods.\ntype parser struct {\n\t*grammar.Recognizer\n}\n")
+
+	for _, nt := range g.Nonterminals {
+		rules := g.rules[nt]
+		fmt.Fprintf(&b, "\n// %s Recognizes %s:\n//\n", names[nt], nt)
+		for i, p := range rules {
+			text := g.Text(p)
+			if i > 0 {
+				text = strings.Repeat(" ", len(nt)+1) + "|" + strings.TrimPrefix(text, nt+" ::=")
+			}
+			fmt.Fprintf(&b, "//\t%s\n", strings.TrimRight(text, " "))
+		}
+		var conflicts []string
+		for _, c := range a.Conflicts {
+			if c.Chosen.Left == nt {
+				conflicts = append(conflicts, g.Display(c.Terminal))
+			}
+		}
+		if len(conflicts) > 0 {
+			fmt.Fprintf(&b, "//\n// LL(1) conflicts, where the first alternative is chosen: %s.\n", join(dedupe(conflicts), "and"))
+		}
+		fmt.Fprintf(&b, "func (p parser) %s() {\n", names[nt])
+
+		body := func(p *Production, indent string) {
+			if len(p.Right) == 0 {
+				b.WriteString(indent + "// empty\n")
+			}
+			for _, s := range p.Right {
+				if s.Terminal {
+					fmt.Fprintf(&b, "%sp.Match(%s)\n", indent, literal(s.Name))
+				} else {
+					fmt.Fprintf(&b, "%sp.%s()\n", indent, names[s.Name])
+				}
+			}
+		}
+		if len(rules) == 1 {
+			body(rules[0], "\t")
+			b.WriteString("}\n")
+			continue
+		}
+		b.WriteString("\tswitch {\n")
+		var expected []string
+		for _, p := range rules {
+			var lookahead []string
+			for _, t := range a.Predict(p) {
+				if a.Table[nt][t] == p {
+					lookahead = append(lookahead, t)
+				}
+			}
+			if len(lookahead) == 0 { // every terminal chooses an earlier alternative
+				continue
+			}
+			expected = append(expected, lookahead...)
+			fmt.Fprintf(&b, "\tcase p.At(%s):\n", literals(lookahead))
+			body(p, "\t\t")
+		}
+		fmt.Fprintf(&b, "\tdefault:\n\t\tp.Fail(%s)\n\t}\n}\n", literals(a.sorted(toSet(expected))))
+	}
+
+	src, err := format.Source(b.Bytes())
+	if err != nil {
+		return errors.New("generated invalid Go code: " + err.Error())
+	}
+	_, err = w.Write(src)
+	return err
+}
+
+// methodName Name of the method recognizing a nonterminal: parse followed by the words of its
+// name, capitalized.
+func methodName(nonterminal string) string {
+	var b strings.Builder
+	b.WriteString("parse")
+	upper := true
+	for _, c := range strings.Trim(nonterminal, "<>") {
+		switch {
+		case unicode.IsLetter(c) || unicode.IsDigit(c):
+			if upper {
+				c = unicode.ToUpper(c)
+			}
+			b.WriteRune(c)
+			upper = false
+		default:
+			upper = true
+		}
+	}
+	return b.String()
+}
+
+func toSet(list []string) set {
+	s := make(set)
+	for _, x := range list {
+		s[x] = true
+	}
+	return s
+}
+
+func dedupe(list []string) []string {
+	var out []string
+	seen := make(map[string]bool)
+	for _, x := range list {
+		if !seen[x] {
+			seen[x] = true
+			out = append(out, x)
+		}
+	}
+	return out
+}
@@ -0,0 +1,44 @@
+package grammar
+
+import (
+	"bytes"
+	"flag"
+	"io/ioutil"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the generated parsers of the tests")
+
+// TestWriteGo The parser of testdata/statements.grm, tested in internal/statements, is the one
+// WriteGo generates.
+func TestWriteGo(t *testing.T) {
+	const file, parser = "testdata/statements.grm", "internal/statements/parser.go"
+	src, err := ioutil.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g, errs := Parse(file, string(src))
+	if g == nil {
+		t.Fatal(errs)
+	}
+	a := Analyze(g)
+	if problems := a.Problems(); len(problems) > 0 {
+		t.Fatal(problems)
+	}
+	var b bytes.Buffer
+	if err := WriteGo(&b, a, "statements"); err != nil {
+		t.Fatal(err)
+	}
+	if *update {
+		if err := ioutil.WriteFile(parser, b.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := ioutil.ReadFile(parser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(b.Bytes(), want) {
+		t.Errorf("%s is out of date, run go test -update", parser)
+	}
+}
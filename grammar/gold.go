@@ -0,0 +1,417 @@
+// Package grammar reads the grammar of the language from a GOLD grammar file, such as
+// GramaticaUnica.txt, computes its FIRST and FOLLOW sets, finds what keeps it from being LL(1) and
+// generates its parse table or a recursive-descent parser in Go.
+package grammar
+
+import (
+	Compiler "compiladores/Compiler/analyzer"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// EOF Name of the terminal standing for the end of the input, as in GOLD.
+const EOF = "(EOF)"
+
+// Codes Of the problems found in grammars, see Analysis.Problems.
+const (
+	CodeMalformedGrammar = "malformed-grammar"
+	CodeUndefined        = "undefined-nonterminal"
+	CodeUnreachable      = "unreachable-nonterminal"
+	CodeUnknownTerminal  = "unknown-terminal"
+	CodeLeftRecursion    = "left-recursion"
+	CodeConflict         = "ll1-conflict"
+)
+
+// Grammar A context-free grammar. Nonterminals are named with their angle brackets, as in <Start>;
+// terminals by their text, as in while or ;, or by the name of their definition, as in Identifier.
+type Grammar struct {
+	File         string
+	Name         string // the "Name" parameter
+	Start        string // the "Start Symbol" parameter
+	Terminals    []string
+	Nonterminals []string // in the order of their first rule
+	Productions  []*Production
+
+	named map[string]bool // terminals with a definition in the file
+	index map[string]int  // of each terminal in Terminals
+	rules map[string][]*Production
+	uses  map[string]Symbol // first use of each symbol
+}
+
+// Production One alternative of a rule, Left ::= Right. Right is empty for the empty alternative.
+type Production struct {
+	Index int // in Grammar.Productions
+	Left  string
+	Right []Symbol
+	pos   pos // of the first symbol or, for the empty alternative, of the ::= or | before it
+}
+
+// Symbol A terminal or nonterminal on the right of a production.
+type Symbol struct {
+	Name     string
+	Terminal bool
+	pos      pos
+}
+
+// pos Position of a piece of the grammar file.
+type pos struct {
+	offset, line, column int
+	end                  int // offset of the first byte after the piece
+}
+
+// Rules The productions of a nonterminal, in the order of the file.
+func (g *Grammar) Rules(nonterminal string) []*Production {
+	return g.rules[nonterminal]
+}
+
+// Display Name of a symbol as written in the grammar: nonterminals between angle brackets, named
+// terminals bare and the others quoted.
+func (g *Grammar) Display(symbol string) string {
+	if strings.HasPrefix(symbol, "<") || symbol == EOF || g.named[symbol] {
+		return symbol
+	}
+	return "'" + strings.Replace(symbol, "'", "''", -1) + "'"
+}
+
+// Text The production as written in the grammar.
+func (g *Grammar) Text(p *Production) string {
+	list := []string{p.Left, "::="}
+	for _, s := range p.Right {
+		list = append(list, g.Display(s.Name))
+	}
+	return strings.Join(list, " ")
+}
+
+// ====================================== READER ======================================
+
+// Parse Reads a grammar in the format of the GOLD Parsing System: parameters such as "Name" and
+// "Start Symbol", set and terminal definitions and rules, with ! line comments and !* *! block
+// comments. Only the rules and the names of the terminal definitions matter here: in rules, a word
+// naming no definition stands for its own text, as a quoted terminal does. The grammar is nil when
+// the file could not be read.
+func Parse(file, src string) (*Grammar, []Compiler.Diagnostic) {
+	r := &reader{file: file, src: src, line: 1, column: 1}
+	r.scan()
+	if len(r.errors) > 0 {
+		return nil, r.errors
+	}
+	g := &Grammar{
+		File:  file,
+		named: make(map[string]bool),
+		index: make(map[string]int),
+		rules: make(map[string][]*Production),
+		uses:  make(map[string]Symbol),
+	}
+	var rules [][]item
+	for _, statement := range r.statements() {
+		first := statement[0]
+		switch {
+		case first.kind == itemParameter:
+			r.parameter(g, statement)
+		case first.kind == itemNonterminal && len(statement) > 1 && statement[1].kind == itemDefine:
+			rules = append(rules, statement)
+		case first.kind == itemSetName:
+			// Sets of characters only serve to define terminals.
+		case first.kind == itemWord:
+			name := r.terminalName(statement)
+			if name != "" {
+				g.named[name] = true
+			}
+		default:
+			r.errorf(first.pos, "expected a rule, a definition or a parameter, found %s", first)
+		}
+	}
+	for _, statement := range rules { // once every terminal definition is known
+		r.rule(g, statement)
+	}
+	if g.Start == "" && len(r.errors) == 0 {
+		r.errorf(pos{end: 0}, "the grammar has no \"Start Symbol\" parameter")
+	}
+	if len(r.errors) > 0 {
+		return nil, r.errors
+	}
+	return g, nil
+}
+
+// itemKind Kind of the pieces of a grammar file.
+type itemKind int
+
+const (
+	itemParameter   itemKind = iota // "Name"
+	itemNonterminal                 // <Name>
+	itemSetName                     // {Name}
+	itemLiteral                     // 'text', quotes doubled inside
+	itemSet                         // [characters]
+	itemWord                        // Name, a terminal or part of the name of a definition
+	itemDefine                      // ::=
+	itemEquals                      // =
+	itemBar                         // |
+	itemOperator                    // one of + - * ? ( )
+	itemNewline
+)
+
+// item A piece of a grammar file. The text of literals has its quotes removed and undoubled.
+type item struct {
+	kind itemKind
+	text string
+	pos  pos
+}
+
+func (i item) String() string {
+	switch i.kind {
+	case itemNewline:
+		return "end of line"
+	case itemLiteral:
+		return "'" + strings.Replace(i.text, "'", "''", -1) + "'"
+	}
+	return strconv.Quote(i.text)
+}
+
+// reader Splits a grammar file into items.
+type reader struct {
+	file   string
+	src    string
+	offset int
+	line   int
+	column int
+	items  []item
+	errors []Compiler.Diagnostic
+}
+
+func (r *reader) errorf(p pos, format string, args ...interface{}) {
+	r.errors = append(r.errors, newDiagnostic(r.file, p, Compiler.SeverityError, CodeMalformedGrammar, format, args...))
+}
+
+// peek The character at the offset, or -1 at the end of the file.
+func (r *reader) peek() rune {
+	if r.offset >= len(r.src) {
+		return -1
+	}
+	c, _ := utf8.DecodeRuneInString(r.src[r.offset:])
+	return c
+}
+
+func (r *reader) next() rune {
+	if r.offset >= len(r.src) {
+		return -1
+	}
+	c, size := utf8.DecodeRuneInString(r.src[r.offset:])
+	r.offset += size
+	if c == '\n' {
+		r.line++
+		r.column = 1
+	} else {
+		r.column++
+	}
+	return c
+}
+
+func (r *reader) here() pos {
+	return pos{offset: r.offset, line: r.line, column: r.column}
+}
+
+// closing Reads up to and including the delimiter ending an item. On success the text between the
+// opening delimiter and the closing one is returned, quotes undoubled for literals.
+func (r *reader) closing(start pos, delim rune, what string) (string, bool) {
+	var b strings.Builder
+	for {
+		c := r.next()
+		switch {
+		case c < 0 || c == '\n':
+			start.end = r.offset
+			r.errorf(start, "%s is not terminated", what)
+			return "", false
+		case c == delim && delim == '\'' && r.peek() == '\'':
+			r.next()
+			b.WriteRune(c)
+		case c == delim:
+			return b.String(), true
+		default:
+			b.WriteRune(c)
+		}
+	}
+}
+
+// scan Splits the whole file into items, leaving out the comments.
+func (r *reader) scan() {
+	for {
+		start := r.here()
+		c := r.next()
+		switch {
+		case c < 0:
+			return
+		case c == '\n':
+			r.emit(itemNewline, "", start)
+		case unicode.IsSpace(c):
+		case c == '!' && r.peek() == '*':
+			end := strings.Index(r.src[r.offset:], "*!")
+			if end < 0 {
+				start.end = len(r.src)
+				r.errorf(start, "comment is not terminated")
+				return
+			}
+			for stop := r.offset + end + 2; r.offset < stop; {
+				r.next()
+			}
+		case c == '!':
+			for r.peek() >= 0 && r.peek() != '\n' {
+				r.next()
+			}
+		case c == '"':
+			if text, ok := r.closing(start, '"', "parameter name"); ok {
+				r.emit(itemParameter, text, start)
+			}
+		case c == '<':
+			if text, ok := r.closing(start, '>', "nonterminal"); ok {
+				r.emit(itemNonterminal, "<"+text+">", start)
+			}
+		case c == '{':
+			if text, ok := r.closing(start, '}', "set name"); ok {
+				r.emit(itemSetName, text, start)
+			}
+		case c == '[':
+			if text, ok := r.closing(start, ']', "set"); ok {
+				r.emit(itemSet, text, start)
+			}
+		case c == '\'':
+			if text, ok := r.closing(start, '\'', "literal"); ok {
+				if text == "" { // '' alone is the quote itself
+					text = "'"
+				}
+				r.emit(itemLiteral, text, start)
+			}
+		case c == ':' && strings.HasPrefix(r.src[r.offset:], ":="):
+			r.next()
+			r.next()
+			r.emit(itemDefine, "::=", start)
+		case c == '=':
+			r.emit(itemEquals, "=", start)
+		case c == '|':
+			r.emit(itemBar, "|", start)
+		case strings.ContainsRune("+-*?()", c):
+			r.emit(itemOperator, string(c), start)
+		case isWordChar(c):
+			for isWordChar(r.peek()) {
+				r.next()
+			}
+			r.emit(itemWord, r.src[start.offset:r.offset], start)
+		default:
+			start.end = r.offset
+			r.errorf(start, "invalid character %q", c)
+		}
+	}
+}
+
+func isWordChar(c rune) bool {
+	return c == '_' || c == '.' || unicode.IsLetter(c) || unicode.IsDigit(c)
+}
+
+func (r *reader) emit(kind itemKind, text string, start pos) {
+	start.end = r.offset
+	r.items = append(r.items, item{kind, text, start})
+}
+
+// statements Groups the items into statements: each starts a line, and lines starting with | go on
+// with the statement before them. The newlines are left out.
+func (r *reader) statements() [][]item {
+	var list [][]item
+	lineStart := true
+	for _, it := range r.items {
+		switch {
+		case it.kind == itemNewline:
+			lineStart = true
+			continue
+		case lineStart && (it.kind != itemBar || len(list) == 0):
+			list = append(list, nil)
+		}
+		lineStart = false
+		list[len(list)-1] = append(list[len(list)-1], it)
+	}
+	return list
+}
+
+// parameter Records the parameters of the grammar that matter here, "Name" and "Start Symbol".
+func (r *reader) parameter(g *Grammar, statement []item) {
+	if len(statement) < 3 || statement[1].kind != itemEquals {
+		r.errorf(statement[0].pos, "expected = after the parameter %s", statement[0])
+		return
+	}
+	value := statement[2]
+	switch statement[0].text {
+	case "Name":
+		g.Name = value.text
+	case "Start Symbol":
+		if value.kind != itemNonterminal {
+			r.errorf(value.pos, "the start symbol must be a nonterminal, found %s", value)
+			return
+		}
+		g.Start = value.text
+	}
+}
+
+// terminalName Name of the terminal a definition defines, the words before its =. Comments and
+// whitespace are defined in the same way, their names are returned as well.
+func (r *reader) terminalName(statement []item) string {
+	var words []string
+	for _, it := range statement {
+		switch it.kind {
+		case itemWord:
+			words = append(words, it.text)
+		case itemEquals:
+			return strings.Join(words, " ")
+		default:
+			r.errorf(it.pos, "expected = in the definition of %s, found %s", strings.Join(words, " "), it)
+			return ""
+		}
+	}
+	r.errorf(statement[0].pos, "expected = in the definition of %s", strings.Join(words, " "))
+	return ""
+}
+
+// rule Adds the alternatives of a rule to the productions of its nonterminal.
+func (r *reader) rule(g *Grammar, statement []item) {
+	left := statement[0].text
+	if _, ok := g.rules[left]; !ok {
+		g.Nonterminals = append(g.Nonterminals, left)
+		g.rules[left] = nil
+	}
+	if _, ok := g.uses[left]; !ok {
+		g.uses[left] = Symbol{left, false, statement[0].pos}
+	}
+	p := &Production{Left: left, pos: statement[1].pos}
+	add := func() {
+		p.Index = len(g.Productions)
+		g.Productions = append(g.Productions, p)
+		g.rules[left] = append(g.rules[left], p)
+	}
+	for _, it := range statement[2:] {
+		var s Symbol
+		switch it.kind {
+		case itemBar:
+			add()
+			p = &Production{Left: left, pos: it.pos}
+			continue
+		case itemNonterminal:
+			s = Symbol{it.text, false, it.pos}
+		case itemLiteral, itemWord:
+			s = Symbol{it.text, true, it.pos}
+		default:
+			r.errorf(it.pos, "expected a symbol in the rule of %s, found %s", left, it)
+			return
+		}
+		if len(p.Right) == 0 {
+			p.pos = it.pos
+		}
+		p.Right = append(p.Right, s)
+		if _, ok := g.uses[s.Name]; !ok {
+			g.uses[s.Name] = s
+		}
+		if _, ok := g.index[s.Name]; s.Terminal && !ok {
+			g.index[s.Name] = len(g.Terminals)
+			g.Terminals = append(g.Terminals, s.Name)
+		}
+	}
+	add()
+}
@@ -0,0 +1,51 @@
+package statements
+
+import (
+	Compiler "compiladores/Compiler/analyzer"
+	"testing"
+)
+
+func parse(src string) []Compiler.Diagnostic {
+	tokens, _ := Compiler.Tokens(Compiler.Lex("test", src))
+	return Parse("test", tokens)
+}
+
+func TestAccept(t *testing.T) {
+	for _, src := range []string{
+		"",
+		"x = 1;",
+		"x = (a + 2) - b; % comment\ny = x;",
+		"while (i) { i = i - 1; } while ((n)) { }",
+		"while (a) { while (b) { c = 1 + (2 + 3); } }",
+	} {
+		if errs := parse(src); len(errs) > 0 {
+			t.Errorf("%q: got %v, want it accepted", src, errs)
+		}
+	}
+}
+
+func TestReject(t *testing.T) {
+	tests := []struct {
+		src          string
+		line, column int
+		message      string
+	}{
+		{"x = 1", 1, 6, `expected ";", ")", "+" or "-", found end of file`},
+		{"x = ;", 1, 5, `expected identifier, "(" or integer, found ";"`},
+		{"x = 1.5;", 1, 5, `expected identifier, "(" or integer, found "1.5"`},
+		{"1 = x;", 1, 1, `expected identifier, "while", "}" or end of file, found "1"`},
+		{"while (x) {\n\tx = x * 2;\n}", 2, 8, `expected ";", ")", "+" or "-", found "*"`},
+		{"while (x) { x = 1;", 1, 19, `expected "}", found end of file`},
+		{"x = 1; }", 1, 8, `expected end of file, found "}"`},
+	}
+	for _, test := range tests {
+		errs := parse(test.src)
+		if len(errs) != 1 {
+			t.Errorf("%q: got %v, want one error", test.src, errs)
+			continue
+		}
+		if e := errs[0]; e.Code != Compiler.CodeUnexpectedToken || e.Line != test.line || e.Column != test.column || e.Message != test.message {
+			t.Errorf("%q: got %v, want %q at %d:%d", test.src, e, test.message, test.line, test.column)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package grammar
+
+import (
+	Compiler "compiladores/Compiler/analyzer"
+	"strconv"
+	"strings"
+)
+
+// tokenEOF Type of the end of file token of the lexer.
+const tokenEOF = "tokenEOF"
+
+// lexerTerminals The tokens of the lexer the named terminals of GramaticaUnica.txt stand for, with the
+// way they are described in messages. Every other terminal is a keyword, operator or delimiter
+// written as in the grammar.
+var lexerTerminals = map[string]struct {
+	describe string
+	match    func(t Compiler.Token) bool
+}{
+	"Identifier": {"identifier", func(t Compiler.Token) bool { return t.Type == "tokenIdentifier" }},
+	"Decimal": {"integer", func(t Compiler.Token) bool {
+		return t.Type == "tokenNumber" && !strings.ContainsAny(t.Value, ".eE")
+	}},
+	"RealNumber": {"real", func(t Compiler.Token) bool {
+		return t.Type == "tokenNumber" && strings.ContainsAny(t.Value, ".eE")
+	}},
+	"Boolean": {"boolean", func(t Compiler.Token) bool {
+		return t.IsKeyword() && (t.Value == "true" || t.Value == "false")
+	}},
+	"StringLiteral": {"string", func(t Compiler.Token) bool { return t.Type == "tokenString" }},
+	"Char":          {"character", func(t Compiler.Token) bool { return t.Type == "tokenChar" }},
+}
+
+// Lexes Reports whether the lexer delivers tokens for the terminal: one of the named terminals of
+// GramaticaUnica.txt when named is set, or a keyword, operator or delimiter otherwise.
+func Lexes(terminal string, named bool) bool {
+	if named {
+		_, ok := lexerTerminals[terminal]
+		return ok
+	}
+	tokens, errs := Compiler.Tokens(Compiler.Lex("", terminal))
+	return len(errs) == 0 && len(tokens) == 2 && tokens[0].Is(terminal)
+}
+
+// Match Reports whether the token is one the terminal stands for.
+func Match(terminal string, t Compiler.Token) bool {
+	if terminal == EOF {
+		return t.IsEOF()
+	}
+	if named, ok := lexerTerminals[terminal]; ok {
+		return named.match(t)
+	}
+	return t.Is(terminal)
+}
+
+// describe Description of a terminal in messages, as in "expected identifier".
+func describe(terminal string) string {
+	if terminal == EOF {
+		return "end of file"
+	}
+	if named, ok := lexerTerminals[terminal]; ok {
+		return named.describe
+	}
+	return strconv.Quote(terminal)
+}
+
+// Recognizer Reads the tokens of a program for the parsers WriteGo generates, and keeps the first
+// syntax error. Once there is an error every method but Errors does nothing and At reports false,
+// so the generated parsers need no error handling of their own.
+type Recognizer struct {
+	file   string
+	tokens []Compiler.Token // comments left out, ending with the end of file
+	next   int
+	errors []Compiler.Diagnostic
+}
+
+// NewRecognizer Reads the tokens of a program, as delivered by Compiler.Tokens.
+func NewRecognizer(file string, tokens []Compiler.Token) *Recognizer {
+	r := &Recognizer{file: file}
+	for _, t := range tokens {
+		if !t.IsComment() {
+			r.tokens = append(r.tokens, t)
+		}
+	}
+	if n := len(r.tokens); n == 0 || !r.tokens[n-1].IsEOF() {
+		r.tokens = append(r.tokens, Compiler.Token{Type: tokenEOF, Line: 1, Column: 1})
+	}
+	return r
+}
+
+// At Reports whether the next token is one of the terminals.
+func (r *Recognizer) At(terminals ...string) bool {
+	if len(r.errors) > 0 {
+		return false
+	}
+	for _, terminal := range terminals {
+		if Match(terminal, r.tokens[r.next]) {
+			return true
+		}
+	}
+	return false
+}
+
+// Match Reads the next token if it is the terminal, and reports an error otherwise.
+func (r *Recognizer) Match(terminal string) {
+	if !r.At(terminal) {
+		r.Fail(terminal)
+		return
+	}
+	if r.next < len(r.tokens)-1 {
+		r.next++
+	}
+}
+
+// Fail Reports that the next token is none of the terminals expected there.
+func (r *Recognizer) Fail(expected ...string) {
+	if len(r.errors) > 0 {
+		return
+	}
+	list := make([]string, len(expected))
+	for i, terminal := range expected {
+		list[i] = describe(terminal)
+	}
+	t := r.tokens[r.next]
+	found := "end of file"
+	if !t.IsEOF() {
+		found = strconv.Quote(t.Value)
+	}
+	p := pos{offset: t.Offset, line: t.Line, column: t.Column, end: t.Offset + len(t.Value)}
+	r.errors = append(r.errors, newDiagnostic(r.file, p, Compiler.SeverityError, Compiler.CodeUnexpectedToken,
+		"expected %s, found %s", join(list, "or"), found))
+}
+
+// Errors The syntax error found, if any.
+func (r *Recognizer) Errors() []Compiler.Diagnostic {
+	return r.errors
+}
@@ -0,0 +1,112 @@
+// Package highlight generates the grammars editors use to highlight programs, from the keywords and
+// token classes of the lexer.
+package highlight
+
+import (
+	Compiler "compiladores/Compiler/analyzer"
+	"encoding/json"
+	"io"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Name of the language and root scope of the generated grammars.
+const (
+	Name  = "Compiler"
+	Scope = "source.compiler"
+)
+
+// scopes TextMate scope of each token class, without the language suffix.
+var scopes = map[Compiler.TokenClass]string{
+	Compiler.ClassKeyword:  "keyword.control",
+	Compiler.ClassType:     "storage.type",
+	Compiler.ClassBoolean:  "constant.language.boolean",
+	Compiler.ClassNumber:   "constant.numeric",
+	Compiler.ClassString:   "string.quoted.double",
+	Compiler.ClassChar:     "string.quoted.single",
+	Compiler.ClassOperator: "keyword.operator",
+}
+
+// grammar A TextMate grammar (https://macromates.com/manual/en/language_grammars), in the JSON form
+// read by VS Code and most editors supporting TextMate grammars.
+type grammar struct {
+	Schema     string          `json:"$schema"`
+	Name       string          `json:"name"`
+	ScopeName  string          `json:"scopeName"`
+	Patterns   []rule          `json:"patterns"`
+	Repository map[string]rule `json:"repository"`
+}
+
+type rule struct {
+	Name     string `json:"name,omitempty"`
+	Include  string `json:"include,omitempty"`
+	Match    string `json:"match,omitempty"`
+	Begin    string `json:"begin,omitempty"`
+	End      string `json:"end,omitempty"`
+	Patterns []rule `json:"patterns,omitempty"`
+}
+
+// TextMate Writes a TextMate grammar for the language. Its patterns mirror the lexer: the keywords
+// are those of Compiler.Keywords, grouped by Compiler.KeywordClass, and the operators those of
+// Compiler.Operators.
+func TextMate(w io.Writer) error {
+	g := grammar{
+		Schema:     "https://raw.githubusercontent.com/martinring/tmlanguage/master/tmlanguage.json",
+		Name:       Name,
+		ScopeName:  Scope,
+		Repository: make(map[string]rule),
+	}
+	add := func(key string, r rule) {
+		g.Patterns = append(g.Patterns, rule{Include: "#" + key})
+		g.Repository[key] = r
+	}
+
+	// Comments and literals come first: keywords and operators mean nothing inside them.
+	add("comments", rule{Patterns: []rule{
+		{Name: scope("comment.line.percentage"), Match: `%.*$`}, // lexLineComment
+		{Name: scope("comment.block"), Begin: `/#`, End: `#/`},  // lexCommentBlock
+	}})
+	// Literals end with their line, as in lexString and lexChar.
+	escapes := []rule{{Name: scope("constant.character.escape"), Match: `\\(?:[nt\\'"]|u[0-9A-Fa-f]{4}|U[0-9A-Fa-f]{8})`}}
+	add("strings", rule{Name: scope(scopes[Compiler.ClassString]), Begin: `"`, End: `"|$`, Patterns: escapes})
+	add("chars", rule{Name: scope(scopes[Compiler.ClassChar]), Begin: `'`, End: `'|$`, Patterns: escapes})
+	add("numbers", rule{Name: scope(scopes[Compiler.ClassNumber]), Match: `\b\d+(?:\.\d+)?(?:[eE][+-]?\d+)?\b`}) // lexNumber
+
+	words := make(map[Compiler.TokenClass][]string)
+	for _, word := range Compiler.Keywords {
+		class := Compiler.KeywordClass(word)
+		words[class] = append(words[class], word)
+	}
+	for _, c := range []struct {
+		key   string
+		class Compiler.TokenClass
+	}{{"keywords", Compiler.ClassKeyword}, {"types", Compiler.ClassType}, {"booleans", Compiler.ClassBoolean}} {
+		if len(words[c.class]) > 0 {
+			add(c.key, rule{Name: scope(scopes[c.class]), Match: `\b(?:` + alternatives(words[c.class]) + `)\b`})
+		}
+	}
+	add("operators", rule{Name: scope(scopes[Compiler.ClassOperator]), Match: alternatives(Compiler.Operators)})
+
+	e := json.NewEncoder(w)
+	e.SetEscapeHTML(false)
+	e.SetIndent("", "  ")
+	return e.Encode(g)
+}
+
+// scope Scope name of the language.
+func scope(name string) string {
+	return name + "." + strings.TrimPrefix(Scope, "source.")
+}
+
+// alternatives Regular expression matching any of the words, the longest first.
+func alternatives(words []string) string {
+	list := append([]string(nil), words...)
+	sort.SliceStable(list, func(i, j int) bool {
+		return len(list[i]) > len(list[j])
+	})
+	for i, word := range list {
+		list[i] = regexp.QuoteMeta(word)
+	}
+	return strings.Join(list, "|")
+}
@@ -0,0 +1,87 @@
+package highlight
+
+import (
+	"bytes"
+	Compiler "compiladores/Compiler/analyzer"
+	"encoding/json"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+// textMate Decodes the grammar written by TextMate.
+func textMate(t *testing.T) grammar {
+	t.Helper()
+	var out bytes.Buffer
+	if err := TextMate(&out); err != nil {
+		t.Fatal(err)
+	}
+	var g grammar
+	if err := json.Unmarshal(out.Bytes(), &g); err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestTextMate(t *testing.T) {
+	g := textMate(t)
+	if g.ScopeName != Scope || g.Name != Name {
+		t.Errorf("got scope %q and name %q", g.ScopeName, g.Name)
+	}
+	for _, p := range g.Patterns {
+		if _, ok := g.Repository[strings.TrimPrefix(p.Include, "#")]; !ok {
+			t.Errorf("pattern %q includes nothing", p.Include)
+		}
+	}
+	if first := g.Patterns[0].Include; first != "#comments" {
+		t.Errorf("the first pattern is %q, want the comments", first)
+	}
+}
+
+// TestTextMateKeywords Every keyword is matched, as a whole word, by the rule of its class.
+func TestTextMateKeywords(t *testing.T) {
+	g := textMate(t)
+	rules := map[Compiler.TokenClass]string{Compiler.ClassKeyword: "keywords", Compiler.ClassType: "types", Compiler.ClassBoolean: "booleans"}
+	for _, word := range Compiler.Keywords {
+		r := g.Repository[rules[Compiler.KeywordClass(word)]]
+		if r.Name != scope(scopes[Compiler.KeywordClass(word)]) {
+			t.Errorf("%s is highlighted as %q", word, r.Name)
+		}
+		re := regexp.MustCompile(r.Match)
+		if got := re.FindString(" " + word + " "); got != word {
+			t.Errorf("the rule of %s matches %q in it", word, got)
+		}
+		if re.MatchString(word + "x") {
+			t.Errorf("the rule of %s matches %sx", word, word)
+		}
+	}
+}
+
+// TestTextMateOperators Every operator is matched whole: the alternatives list an operator before
+// the shorter ones it starts with, as the first alternative that matches wins.
+func TestTextMateOperators(t *testing.T) {
+	r := textMate(t).Repository["operators"]
+	alternatives := regexp.MustCompile(`(?:\\.|[^\\|])+`).FindAllString(r.Match, -1) // split at the unquoted bars
+	if len(alternatives) != len(Compiler.Operators) {
+		t.Fatalf("got operators %q, want %q", alternatives, Compiler.Operators)
+	}
+	index := make(map[string]int)
+	for i, alt := range alternatives {
+		index[alt] = i
+	}
+	for _, op := range Compiler.Operators {
+		i, ok := index[regexp.QuoteMeta(op)]
+		if !ok {
+			t.Errorf("operator %s is missing from %q", op, r.Match)
+			continue
+		}
+		for _, other := range Compiler.Operators {
+			if len(other) > len(op) && strings.HasPrefix(other, op) && index[regexp.QuoteMeta(other)] > i {
+				t.Errorf("operator %s comes before %s", op, other)
+			}
+		}
+		if got := regexp.MustCompile(r.Match).FindString(op); got != op {
+			t.Errorf("the operators match %q in %s", got, op)
+		}
+	}
+}
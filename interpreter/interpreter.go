@@ -0,0 +1,458 @@
+// Package interpreter executes a checked program by walking its syntax tree.
+package interpreter
+
+import (
+	"bufio"
+	Compiler "compiladores/Compiler/analyzer"
+	"compiladores/Compiler/ast"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Value Runtime value: int64 (integer), float64 (real), string, bool (boolean), rune (char) or *Record.
+type Value interface{}
+
+// Record Value of a register.
+type Record struct {
+	Type   *Compiler.Type
+	Fields map[string]Value
+}
+
+// clone Registers are copied on assignment and when passed as arguments.
+func (r *Record) clone() *Record {
+	c := &Record{Type: r.Type, Fields: make(map[string]Value, len(r.Fields))}
+	for name, v := range r.Fields {
+		c.Fields[name] = v
+	}
+	return c
+}
+
+// RuntimeError Describes an error that stopped the execution, such as a division by zero.
+type RuntimeError struct {
+	Pos     ast.Pos
+	Message string
+}
+
+func (e *RuntimeError) Error() string {
+	return fmt.Sprintf("runtime error at line %s: %s", e.Pos, e.Message)
+}
+
+// frame Variables and parameters of a single call (or of main).
+type frame map[*Compiler.Symbol]Value
+
+// Interpreter Holds the state of a single execution.
+type Interpreter struct {
+	program *ast.Program
+	info    *Compiler.Info
+	in      *bufio.Scanner
+	out     *bufio.Writer
+	globals frame
+	frame   frame
+}
+
+// New Constructor. The program must be free of syntax, semantic and type errors;
+// print writes to out and read consumes whitespace separated words from in.
+func New(program *ast.Program, info *Compiler.Info, in io.Reader, out io.Writer) *Interpreter {
+	scanner := bufio.NewScanner(in)
+	scanner.Split(bufio.ScanWords)
+	return &Interpreter{
+		program: program,
+		info:    info,
+		in:      scanner,
+		out:     bufio.NewWriter(out),
+		globals: make(frame),
+	}
+}
+
+// Run Initializes the global variables and constants, then executes main.
+func (it *Interpreter) Run() (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			e, ok := r.(*RuntimeError)
+			if !ok {
+				panic(r)
+			}
+			err = e
+		}
+		if flushErr := it.out.Flush(); err == nil {
+			err = flushErr
+		}
+	}()
+
+	for _, decl := range it.program.Vars {
+		it.declare(it.globals, decl)
+	}
+	for _, decl := range it.program.Consts {
+		t := it.info.TypeRefs[decl.Type]
+		for _, spec := range decl.Specs {
+			if sym := it.info.Defs[spec.Name]; sym != nil {
+				it.globals[sym] = convert(it.eval(spec.Value), t)
+			}
+		}
+	}
+
+	if it.program.Main != nil {
+		it.frame = make(frame)
+		it.block(it.program.Main.Body)
+	}
+	return nil
+}
+
+func (it *Interpreter) errorf(pos ast.Pos, format string, args ...interface{}) {
+	panic(&RuntimeError{pos, fmt.Sprintf(format, args...)})
+}
+
+// ====================================== VARIABLES ======================================
+
+// zero Initial value of a variable of type t.
+func (it *Interpreter) zero(t *Compiler.Type) Value {
+	switch t.Kind {
+	case Compiler.IntegerType:
+		return int64(0)
+	case Compiler.RealType:
+		return float64(0)
+	case Compiler.StringType:
+		return ""
+	case Compiler.BooleanType:
+		return false
+	case Compiler.CharType:
+		return rune(0)
+	case Compiler.RegisterType:
+		r := &Record{Type: t, Fields: make(map[string]Value)}
+		decl := t.Register.Decl.(*ast.RegisterDecl)
+		for _, field := range decl.Fields {
+			for _, name := range field.Names {
+				r.Fields[name.Name] = it.zero(it.info.TypeRefs[field.Type])
+			}
+		}
+		return r
+	}
+	return nil
+}
+
+// convert Converts v to a value stored in a location of type t: integers become real, registers are copied.
+func convert(v Value, t *Compiler.Type) Value {
+	switch v := v.(type) {
+	case int64:
+		if t.Kind == Compiler.RealType {
+			return float64(v)
+		}
+	case *Record:
+		return v.clone()
+	}
+	return v
+}
+
+func (it *Interpreter) declare(f frame, decl *ast.VarDecl) {
+	t := it.info.TypeRefs[decl.Type]
+	for _, name := range decl.Names {
+		if sym := it.info.Defs[name]; sym != nil {
+			f[sym] = it.zero(t)
+		}
+	}
+}
+
+// lookup Returns the frame holding sym: the current call for locals and parameters, the globals otherwise.
+func (it *Interpreter) lookup(sym *Compiler.Symbol) frame {
+	if sym.Scope.Kind == Compiler.GlobalScope {
+		return it.globals
+	}
+	return it.frame
+}
+
+func (it *Interpreter) load(x ast.Expr) Value {
+	switch x := x.(type) {
+	case *ast.Ident:
+		sym := it.info.Uses[x]
+		return it.lookup(sym)[sym]
+	case *ast.FieldExpr:
+		return it.load(x.X).(*Record).Fields[x.Field.Name]
+	}
+	it.errorf(x.Pos(), "cannot load %T", x)
+	return nil
+}
+
+func (it *Interpreter) store(x ast.Expr, v Value) {
+	v = convert(v, it.info.TypeOf(x))
+	switch x := x.(type) {
+	case *ast.Ident:
+		sym := it.info.Uses[x]
+		it.lookup(sym)[sym] = v
+	case *ast.FieldExpr:
+		it.load(x.X).(*Record).Fields[x.Field.Name] = v
+	default:
+		it.errorf(x.Pos(), "cannot store to %T", x)
+	}
+}
+
+// ====================================== COMMANDS ======================================
+
+func (it *Interpreter) block(block *ast.Block) {
+	for _, decl := range block.Vars {
+		it.declare(it.frame, decl)
+	}
+	it.stmts(block.Stmts)
+}
+
+func (it *Interpreter) stmts(list []ast.Stmt) {
+	for _, s := range list {
+		it.stmt(s)
+	}
+}
+
+func (it *Interpreter) stmt(s ast.Stmt) {
+	switch s := s.(type) {
+	case *ast.AssignStmt:
+		it.store(s.Target, it.eval(s.Value))
+	case *ast.IncDecStmt:
+		it.incDec(s.X, s.Op)
+	case *ast.CallStmt:
+		it.call(s.Call)
+	case *ast.IfStmt:
+		if it.eval(s.Cond).(bool) {
+			it.stmts(s.Then.List)
+		} else if s.Else != nil {
+			it.stmts(s.Else.List)
+		}
+	case *ast.WhileStmt:
+		for it.eval(s.Cond).(bool) {
+			it.stmts(s.Body.List)
+		}
+	case *ast.PrintStmt:
+		for _, x := range s.Args {
+			it.out.WriteString(Format(it.eval(x)))
+		}
+		it.out.WriteByte('\n')
+	case *ast.ReadStmt:
+		it.out.Flush() // prompts printed so far must be visible before blocking on input
+		for _, x := range s.Args {
+			it.store(x, it.read(x))
+		}
+	case *ast.BlockStmt:
+		it.stmts(s.List)
+	default:
+		it.errorf(s.Pos(), "unexpected statement %T", s)
+	}
+}
+
+// incDec Applies "x++" or "x--" and returns the previous value of x.
+func (it *Interpreter) incDec(x ast.Expr, op string) Value {
+	old := it.load(x).(int64)
+	if op == "++" {
+		it.store(x, old+1)
+	} else {
+		it.store(x, old-1)
+	}
+	return old
+}
+
+// read Reads the next word of the input as a value of the type of x.
+func (it *Interpreter) read(x ast.Expr) Value {
+	if !it.in.Scan() {
+		if err := it.in.Err(); err != nil {
+			it.errorf(x.Pos(), "read: %v", err)
+		}
+		it.errorf(x.Pos(), "read: unexpected end of input")
+	}
+	v, err := Parse(it.in.Text(), it.info.TypeOf(x))
+	if err != nil {
+		it.errorf(x.Pos(), "read: %v", err)
+	}
+	return v
+}
+
+// call Executes a procedure or function in a new frame, returning the function result.
+func (it *Interpreter) call(call *ast.CallExpr) Value {
+	sym := it.info.Uses[call.Fun]
+	args := make([]Value, len(call.Args))
+	for i, arg := range call.Args {
+		args[i] = it.eval(arg)
+	}
+
+	caller := it.frame
+	it.frame = make(frame)
+	defer func() { it.frame = caller }()
+
+	var params []*ast.Param
+	var body *ast.Block
+	var fn *ast.Function
+	switch decl := sym.Decl.(type) {
+	case *ast.Procedure:
+		params, body = decl.Params, decl.Body
+	case *ast.Function:
+		params, body, fn = decl.Params, decl.Body, decl
+	}
+	for i, param := range params {
+		it.frame[it.info.Defs[param.Name]] = convert(args[i], it.info.TypeRefs[param.Type])
+	}
+	it.block(body)
+	if fn == nil {
+		return nil
+	}
+	return convert(it.eval(fn.Return.Value), it.info.TypeRefs[fn.Result])
+}
+
+// ====================================== EXPRESSIONS ======================================
+
+func (it *Interpreter) eval(x ast.Expr) Value {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		return x.Literal
+	case *ast.Ident, *ast.FieldExpr:
+		return it.load(x)
+	case *ast.CallExpr:
+		return it.call(x)
+	case *ast.UnaryExpr:
+		return !it.eval(x.X).(bool)
+	case *ast.IncDecExpr:
+		return it.incDec(x.X, x.Op)
+	case *ast.BinaryExpr:
+		return it.binary(x)
+	}
+	it.errorf(x.Pos(), "unexpected expression %T", x)
+	return nil
+}
+
+func (it *Interpreter) binary(x *ast.BinaryExpr) Value {
+	switch x.Op { // short-circuit
+	case "&&":
+		return it.eval(x.X).(bool) && it.eval(x.Y).(bool)
+	case "||":
+		return it.eval(x.X).(bool) || it.eval(x.Y).(bool)
+	}
+
+	l, r := it.eval(x.X), it.eval(x.Y)
+	if a, ok := l.(int64); ok {
+		if b, ok := r.(int64); ok {
+			return it.integer(x, a, b)
+		}
+	}
+	if a, ok := toReal(l); ok {
+		if b, ok := toReal(r); ok {
+			return realOp(x.Op, a, b)
+		}
+	}
+
+	switch x.Op {
+	case "==":
+		return l == r
+	case "!=":
+		return l != r
+	}
+	a, b := l.(rune), r.(rune)
+	switch x.Op {
+	case "<":
+		return a < b
+	case ">":
+		return a > b
+	case "<=":
+		return a <= b
+	case ">=":
+		return a >= b
+	}
+	it.errorf(x.OpPos, "invalid operation %s", x.Op)
+	return nil
+}
+
+func (it *Interpreter) integer(x *ast.BinaryExpr, a, b int64) Value {
+	switch x.Op {
+	case "+":
+		return a + b
+	case "-":
+		return a - b
+	case "*":
+		return a * b
+	case "/":
+		if b == 0 {
+			it.errorf(x.OpPos, "integer division by zero")
+		}
+		return a / b
+	}
+	return compare(x.Op, a, b)
+}
+
+func realOp(op string, a, b float64) Value {
+	switch op {
+	case "+":
+		return a + b
+	case "-":
+		return a - b
+	case "*":
+		return a * b
+	case "/":
+		return a / b
+	}
+	return compare(op, a, b)
+}
+
+func compare[T int64 | float64](op string, a, b T) bool {
+	switch op {
+	case "<":
+		return a < b
+	case ">":
+		return a > b
+	case "<=":
+		return a <= b
+	case ">=":
+		return a >= b
+	case "==":
+		return a == b
+	}
+	return a != b
+}
+
+func toReal(v Value) (float64, bool) {
+	switch v := v.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	}
+	return 0, false
+}
+
+// ====================================== TEXT ======================================
+
+// Format Text written by print for a value. Reals are formatted as by C's "%g".
+func Format(v Value) string {
+	switch v := v.(type) {
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatFloat(v, 'g', 6, 64)
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case rune:
+		return string(v)
+	}
+	return fmt.Sprint(v)
+}
+
+// Parse Converts a word read from the input to a value of type t.
+func Parse(word string, t *Compiler.Type) (Value, error) {
+	switch t.Kind {
+	case Compiler.IntegerType:
+		v, err := strconv.ParseInt(word, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid integer %q", word)
+		}
+		return v, nil
+	case Compiler.RealType:
+		v, err := strconv.ParseFloat(word, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid real %q", word)
+		}
+		return v, nil
+	case Compiler.BooleanType:
+		if word != "true" && word != "false" {
+			return nil, fmt.Errorf("invalid boolean %q", word)
+		}
+		return word == "true", nil
+	case Compiler.CharType:
+		return []rune(word)[0], nil
+	}
+	return word, nil
+}
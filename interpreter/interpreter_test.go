@@ -0,0 +1,159 @@
+package interpreter
+
+import (
+	"bytes"
+	Compiler "compiladores/Compiler/analyzer"
+	"strings"
+	"testing"
+)
+
+// run Checks the program and executes it with input as the standard input, returning what it
+// printed and the error that stopped it, if any.
+func run(t *testing.T, src, input string) (string, error) {
+	t.Helper()
+	parser := Compiler.Syntax(Compiler.Lex("test", src))
+	program := parser.Parse()
+	errs := append(parser.LexicalErrors(), parser.Errors()...)
+	analyzer := Compiler.Semantic(program)
+	info := analyzer.Analyze()
+	checker := Compiler.TypeCheck(program, info)
+	checker.Check()
+	errs = append(append(errs, analyzer.Errors()...), checker.Errors()...)
+	if Compiler.HasErrors(errs) {
+		t.Fatalf("the program does not check: %v", errs)
+	}
+	var out bytes.Buffer
+	err := New(program, info, strings.NewReader(input), &out).Run()
+	return out.String(), err
+}
+
+func TestReadPrint(t *testing.T) {
+	src := `program P;
+var { integer i; real r; string s; char c; boolean b; }
+const { }
+main {
+	var { }
+	read(i, r, s, c, b);
+	print(i, " ", r, " ", s, " ", c, " ", b);
+	print("done");
+}
+`
+	out, err := run(t, src, "42 2.5\nword z true")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "42 2.5 word z true\ndone\n"; out != want {
+		t.Errorf("printed %q, want %q", out, want)
+	}
+}
+
+func TestReadInvalid(t *testing.T) {
+	src := `program P;
+var { integer i; }
+const { }
+main {
+	var { }
+	read(i);
+}
+`
+	if _, err := run(t, src, "abc"); err == nil {
+		t.Error("reading abc into an integer succeeded")
+	}
+}
+
+func TestRecursion(t *testing.T) {
+	src := `program P;
+var { }
+const { }
+function Fatorial (integer k) : integer {
+	var { integer r, t; }
+	r = 1;
+	if (k > 1) {
+		t = k - 1;
+		r = Fatorial(t);
+		r = r * k;
+	}
+	return r;
+}
+main {
+	var { integer f; }
+	f = Fatorial(10);
+	print(f);
+	f = Fatorial(20);
+	print(f);
+}
+`
+	out, err := run(t, src, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "3628800\n2432902008176640000\n"; out != want {
+		t.Errorf("printed %q, want %q", out, want)
+	}
+}
+
+func TestRegisterPassing(t *testing.T) {
+	src := `program P;
+var { }
+const { }
+register Ponto { integer x; string nome; }
+procedure Muda (Ponto p) {
+	var { }
+	p.x = 99;
+	print(p.nome, " ", p.x);
+}
+function Novo (Ponto p, integer v) : Ponto {
+	var { Ponto q; }
+	q = p;
+	q.x = v;
+	return q;
+}
+main {
+	var { Ponto a, b; }
+	a.x = 1;
+	a.nome = "A";
+	Muda(a);
+	print(a.nome, " ", a.x);
+	b = Novo(a, 7);
+	print(a.x, " ", b.x, " ", b.nome);
+	b = a;
+	b.x = 5;
+	print(a.x, " ", b.x);
+}
+`
+	out, err := run(t, src, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Registers are copied when passed, returned and assigned.
+	if want := "A 99\nA 1\n1 7 A\n1 5\n"; out != want {
+		t.Errorf("printed %q, want %q", out, want)
+	}
+}
+
+func TestDivisionByZero(t *testing.T) {
+	src := `program P;
+var { integer i; real r; }
+const { }
+main {
+	var { }
+	print("before");
+	r = 1 / 0.0;
+	print(r);
+	i = 0;
+	i = 10 / i;
+	print("after");
+}
+`
+	out, err := run(t, src, "")
+	e, ok := err.(*RuntimeError)
+	if !ok {
+		t.Fatalf("got error %v, want a runtime error", err)
+	}
+	if e.Pos.Line != 10 || e.Message != "integer division by zero" {
+		t.Errorf("got %q at line %d, want \"integer division by zero\" at line 10", e.Message, e.Pos.Line)
+	}
+	if want := "before\n+Inf\n"; out != want { // the output before the error is flushed
+		t.Errorf("printed %q, want %q", out, want)
+	}
+}
@@ -0,0 +1,287 @@
+package lsp
+
+import (
+	Compiler "compiladores/Compiler/analyzer"
+	"compiladores/Compiler/ast"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ====================================== NAVIGATION ======================================
+
+// symbolAt Returns the symbol named at offset, either by an identifier or by the register name of
+// a type, and the range of the name. The offset may be just after the name.
+func (d *document) symbolAt(offset int) (*Compiler.Symbol, textRange) {
+	var sym *Compiler.Symbol
+	var at textRange
+	covers := func(pos ast.Pos, name string) bool {
+		return name != "_" && pos.Offset <= offset && offset <= pos.Offset+len(name)
+	}
+	ast.Inspect(d.program, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.Ident:
+			if covers(n.NamePos, n.Name) {
+				sym, at = d.info.ObjectOf(n), d.nameRange(n.NamePos, n.Name)
+			}
+		case *ast.TypeRef:
+			if covers(n.NamePos, n.Name) {
+				sym, at = d.info.Registers[n], d.nameRange(n.NamePos, n.Name)
+			}
+		}
+		return sym == nil
+	})
+	return sym, at
+}
+
+// definition Location of the declaration of the symbol at offset, nil if there is none.
+func (d *document) definition(offset int) *location {
+	sym, _ := d.symbolAt(offset)
+	if sym == nil {
+		return nil
+	}
+	return &location{d.uri, d.nameRange(sym.Ident.NamePos, sym.Name)}
+}
+
+// hover Declaration of the symbol at offset and where it belongs, nil if there is none.
+func (d *document) hover(offset int) *hover {
+	sym, at := d.symbolAt(offset)
+	if sym == nil {
+		return nil
+	}
+	text := fmt.Sprintf("```\n%s\n```\n\n%s, declared at line %d", signature(sym), describe(sym), sym.Ident.NamePos.Line)
+	return &hover{markupContent{"markdown", text}, at}
+}
+
+// signature Declaration of a symbol as written in the language.
+func signature(sym *Compiler.Symbol) string {
+	switch sym.Kind {
+	case Compiler.ConstSymbol:
+		s := typeName(sym.Type) + " " + sym.Name
+		if spec, ok := sym.Decl.(*ast.ConstSpec); ok {
+			switch x := spec.Value.(type) {
+			case *ast.BasicLit:
+				s += " = " + x.Value
+			case *ast.Ident:
+				s += " = " + x.Name
+			}
+		}
+		return s
+	case Compiler.RegisterSymbol:
+		var b strings.Builder
+		b.WriteString("register " + sym.Name + " {")
+		if r, ok := sym.Decl.(*ast.RegisterDecl); ok {
+			for _, field := range r.Fields {
+				b.WriteString(" " + typeName(field.Type) + " ")
+				for i, name := range field.Names {
+					if i > 0 {
+						b.WriteString(", ")
+					}
+					b.WriteString(name.Name)
+				}
+				b.WriteString(";")
+			}
+		}
+		b.WriteString(" }")
+		return b.String()
+	case Compiler.ProcedureSymbol:
+		if proc, ok := sym.Decl.(*ast.Procedure); ok {
+			return "procedure " + sym.Name + " " + params(proc.Params)
+		}
+	case Compiler.FunctionSymbol:
+		if fn, ok := sym.Decl.(*ast.Function); ok {
+			return "function " + sym.Name + " " + params(fn.Params) + " : " + typeName(fn.Result)
+		}
+	}
+	return typeName(sym.Type) + " " + sym.Name
+}
+
+// params Parameter list of a procedure or function, parentheses included.
+func params(list []*ast.Param) string {
+	names := make([]string, len(list))
+	for i, param := range list {
+		names[i] = typeName(param.Type) + " " + param.Name.Name
+	}
+	return "(" + strings.Join(names, ", ") + ")"
+}
+
+func typeName(t *ast.TypeRef) string {
+	if t == nil {
+		return "_"
+	}
+	return t.Name
+}
+
+// describe Kind of a symbol and the scope it is declared in, e.g. "parameter of function Fatorial".
+func describe(sym *Compiler.Symbol) string {
+	scope := sym.Scope
+	switch {
+	case scope == nil:
+		return sym.Kind.String()
+	case scope.Kind == Compiler.GlobalScope:
+		return fmt.Sprintf("global %s of program %s", sym.Kind, scope.Name)
+	case scope.Kind == Compiler.RegisterScope:
+		return "field of register " + scope.Name
+	case scope.Kind == Compiler.ProcedureScope || scope.Kind == Compiler.FunctionScope:
+		return fmt.Sprintf("parameter of %s %s", scope.Kind, scope.Name)
+	case scope.Parent != nil && scope.Parent.Kind != Compiler.GlobalScope:
+		return fmt.Sprintf("local %s of %s %s", sym.Kind, scope.Parent.Kind, scope.Name)
+	}
+	return fmt.Sprintf("local %s of %s", sym.Kind, scope.Name) // main
+}
+
+// ====================================== COMPLETION ======================================
+
+// completionKinds Kind of completion item of each kind of symbol.
+var completionKinds = map[Compiler.SymbolKind]int{
+	Compiler.VarSymbol:       completionVariable,
+	Compiler.ConstSymbol:     completionConstant,
+	Compiler.ParamSymbol:     completionVariable,
+	Compiler.FieldSymbol:     completionField,
+	Compiler.RegisterSymbol:  completionStruct,
+	Compiler.ProcedureSymbol: completionFunction,
+	Compiler.FunctionSymbol:  completionFunction,
+}
+
+// completion Candidates for the word being typed at offset: after "x." the fields of the register
+// of x, otherwise the keywords and the symbols in scope, the inner declaration of a name hiding the
+// outer ones. The client filters them by the part of the word already typed.
+func (d *document) completion(offset int) []completionItem {
+	items := []completionItem{}
+	start := offset
+	for start > 0 {
+		r, size := utf8.DecodeLastRuneInString(d.text[:start])
+		if !isWordRune(r) {
+			break
+		}
+		start -= size
+	}
+	scope := d.scopeAt(offset)
+
+	if start > 0 && d.text[start-1] == '.' {
+		base := start - 1
+		for base > 0 {
+			r, size := utf8.DecodeLastRuneInString(d.text[:base])
+			if !isWordRune(r) {
+				break
+			}
+			base -= size
+		}
+		sym := scope.LookupParent(d.text[base : start-1])
+		if sym == nil || sym.Type == nil || d.info.Registers[sym.Type] == nil {
+			return items
+		}
+		for _, field := range d.info.Registers[sym.Type].Members.Symbols {
+			items = append(items, completionItem{field.Name, completionField, signature(field)})
+		}
+		return items
+	}
+
+	for _, word := range Compiler.Keywords {
+		items = append(items, completionItem{Label: word, Kind: completionKeyword})
+	}
+	seen := make(map[string]bool)
+	for ; scope != nil; scope = scope.Parent {
+		for _, sym := range scope.Symbols {
+			if !seen[sym.Name] {
+				seen[sym.Name] = true
+				items = append(items, completionItem{sym.Name, completionKinds[sym.Kind], signature(sym)})
+			}
+		}
+	}
+	return items
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// scopeAt Innermost scope at offset: the local scope of a body, the parameters of a procedure or
+// function header or the global scope.
+func (d *document) scopeAt(offset int) *Compiler.Scope {
+	scope := d.info.Global
+	enter := func(header ast.Pos, node ast.Node, body *ast.Block) {
+		if body == nil || offset <= header.Offset || offset > d.end(body) {
+			return
+		}
+		if s := d.info.Scopes[node]; s != nil {
+			scope = s
+		}
+		if s := d.info.Scopes[body]; s != nil && offset > body.Lbrace.Offset {
+			scope = s
+		}
+	}
+	for _, proc := range d.program.Procedures {
+		enter(proc.Procedure, proc, proc.Body)
+	}
+	for _, fn := range d.program.Functions {
+		enter(fn.Function, fn, fn.Body)
+	}
+	if m := d.program.Main; m != nil {
+		enter(m.Main, m, m.Body)
+	}
+	return scope
+}
+
+// end Offset of the closing brace of a body, or the end of the text when it is missing.
+func (d *document) end(body *ast.Block) int {
+	if !body.Rbrace.IsValid() || body.Rbrace.Offset < body.Lbrace.Offset {
+		return len(d.text)
+	}
+	return body.Rbrace.Offset
+}
+
+// ====================================== SYMBOLS ======================================
+
+// symbols Outline of the program: its registers with their fields, procedures, functions and main.
+func (d *document) symbols() []documentSymbol {
+	list := []documentSymbol{}
+	program := d.program
+	for _, r := range program.Registers {
+		if r.Name.Name == "_" {
+			continue
+		}
+		end := r.Name.NamePos.Offset + len(r.Name.Name)
+		if r.Rbrace.IsValid() {
+			end = r.Rbrace.Offset + 1
+		}
+		reg := documentSymbol{Name: r.Name.Name, Kind: symbolStruct,
+			Range: d.spanRange(r.Register.Offset, end), SelectionRange: d.nameRange(r.Name.NamePos, r.Name.Name)}
+		for _, field := range r.Fields {
+			for _, name := range field.Names {
+				if name.Name == "_" {
+					continue
+				}
+				at := d.nameRange(name.NamePos, name.Name)
+				reg.Children = append(reg.Children, documentSymbol{Name: name.Name, Detail: typeName(field.Type),
+					Kind: symbolField, Range: at, SelectionRange: at})
+			}
+		}
+		list = append(list, reg)
+	}
+	for _, proc := range program.Procedures {
+		if proc.Name.Name != "_" {
+			list = append(list, d.body(proc.Name, params(proc.Params), proc.Procedure, proc.Body))
+		}
+	}
+	for _, fn := range program.Functions {
+		if fn.Name.Name != "_" {
+			list = append(list, d.body(fn.Name, params(fn.Params)+" : "+typeName(fn.Result), fn.Function, fn.Body))
+		}
+	}
+	if m := program.Main; m != nil {
+		list = append(list, d.body(&ast.Ident{NamePos: m.Main, Name: "main"}, "", m.Main, m.Body))
+	}
+	return list
+}
+
+// body Symbol of a procedure, function or main, spanning from its keyword to the end of its body.
+func (d *document) body(name *ast.Ident, detail string, keyword ast.Pos, body *ast.Block) documentSymbol {
+	end := d.end(body)
+	if end < len(d.text) {
+		end++ // the closing brace
+	}
+	return documentSymbol{Name: name.Name, Detail: detail, Kind: symbolFunction,
+		Range: d.spanRange(keyword.Offset, end), SelectionRange: d.nameRange(name.NamePos, name.Name)}
+}
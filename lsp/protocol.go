@@ -0,0 +1,276 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// The subset of JSON-RPC 2.0 and of the Language Server Protocol 3.17
+// (https://microsoft.github.io/language-server-protocol/specifications/lsp/3.17/specification/)
+// the server speaks.
+
+// ====================================== JSON-RPC ======================================
+
+// message A request, a notification (no id) or a response read from the client.
+type message struct {
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method,omitempty"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// response Reply to a request. Exactly one of Result and Error is set.
+type response struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      json.RawMessage  `json:"id"`
+	Result  *json.RawMessage `json:"result,omitempty"`
+	Error   *responseError   `json:"error,omitempty"`
+}
+
+// notification Message sent to the client that expects no reply.
+type notification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+}
+
+type responseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *responseError) Error() string {
+	return e.Message
+}
+
+// Error codes.
+const (
+	codeParseError           = -32700
+	codeInvalidRequest       = -32600
+	codeMethodNotFound       = -32601
+	codeInvalidParams        = -32602
+	codeInternalError        = -32603
+	codeServerNotInitialized = -32002
+)
+
+// readMessage Reads a message framed by a Content-Length header.
+func readMessage(r *bufio.Reader) ([]byte, error) {
+	length := -1
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			if err == io.EOF && line != "" {
+				err = io.ErrUnexpectedEOF
+			}
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		i := strings.IndexByte(line, ':')
+		if i < 0 {
+			return nil, fmt.Errorf("malformed header %q", line)
+		}
+		if strings.EqualFold(strings.TrimSpace(line[:i]), "Content-Length") {
+			if length, err = strconv.Atoi(strings.TrimSpace(line[i+1:])); err != nil || length < 0 {
+				return nil, fmt.Errorf("malformed header %q", line)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("missing Content-Length header")
+	}
+	body := make([]byte, length)
+	if _, err := io.ReadFull(r, body); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+	return body, nil
+}
+
+// writeMessage Writes v as JSON framed by a Content-Length header.
+func writeMessage(w io.Writer, v interface{}) error {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(w, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return err
+	}
+	_, err = w.Write(body)
+	return err
+}
+
+// ====================================== LSP ======================================
+
+// position Zero based line and character, counted in UTF-16 code units as the protocol requires.
+type position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// textRange From Start up to End, excluded.
+type textRange struct {
+	Start position `json:"start"`
+	End   position `json:"end"`
+}
+
+type location struct {
+	URI   string    `json:"uri"`
+	Range textRange `json:"range"`
+}
+
+type textDocumentIdentifier struct {
+	URI string `json:"uri"`
+}
+
+type textDocumentPositionParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+	Position     position               `json:"position"`
+}
+
+type initializeResult struct {
+	Capabilities serverCapabilities `json:"capabilities"`
+	ServerInfo   serverInfo         `json:"serverInfo"`
+}
+
+type serverInfo struct {
+	Name string `json:"name"`
+}
+
+type serverCapabilities struct {
+	TextDocumentSync       textDocumentSyncOptions `json:"textDocumentSync"`
+	DefinitionProvider     bool                    `json:"definitionProvider"`
+	HoverProvider          bool                    `json:"hoverProvider"`
+	CompletionProvider     completionOptions       `json:"completionProvider"`
+	DocumentSymbolProvider bool                    `json:"documentSymbolProvider"`
+	SemanticTokensProvider semanticTokensOptions   `json:"semanticTokensProvider"`
+}
+
+// Kinds of text document synchronization.
+const (
+	syncFull = 1 // the whole text is sent on every change
+)
+
+type textDocumentSyncOptions struct {
+	OpenClose bool `json:"openClose"`
+	Change    int  `json:"change"`
+}
+
+type completionOptions struct {
+	TriggerCharacters []string `json:"triggerCharacters"`
+}
+
+type semanticTokensOptions struct {
+	Legend semanticTokensLegend `json:"legend"`
+	Full   bool                 `json:"full"`
+}
+
+type semanticTokensLegend struct {
+	TokenTypes     []string `json:"tokenTypes"`
+	TokenModifiers []string `json:"tokenModifiers"`
+}
+
+type didOpenTextDocumentParams struct {
+	TextDocument struct {
+		URI     string `json:"uri"`
+		Version int    `json:"version"`
+		Text    string `json:"text"`
+	} `json:"textDocument"`
+}
+
+type didChangeTextDocumentParams struct {
+	TextDocument struct {
+		URI     string `json:"uri"`
+		Version int    `json:"version"`
+	} `json:"textDocument"`
+	ContentChanges []struct {
+		Text string `json:"text"` // the whole document, as changes are not incremental
+	} `json:"contentChanges"`
+}
+
+type didCloseTextDocumentParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+// documentParams Parameters of the requests about a whole document.
+type documentParams struct {
+	TextDocument textDocumentIdentifier `json:"textDocument"`
+}
+
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Version     int          `json:"version"`
+	Diagnostics []diagnostic `json:"diagnostics"`
+}
+
+type diagnostic struct {
+	Range    textRange `json:"range"`
+	Severity int       `json:"severity"`
+	Code     string    `json:"code"`
+	Source   string    `json:"source"`
+	Message  string    `json:"message"`
+}
+
+// Diagnostic severities.
+const (
+	severityError       = 1
+	severityWarning     = 2
+	severityInformation = 3
+)
+
+type hover struct {
+	Contents markupContent `json:"contents"`
+	Range    textRange     `json:"range"`
+}
+
+type markupContent struct {
+	Kind  string `json:"kind"` // "plaintext" or "markdown"
+	Value string `json:"value"`
+}
+
+type completionItem struct {
+	Label  string `json:"label"`
+	Kind   int    `json:"kind"`
+	Detail string `json:"detail,omitempty"`
+}
+
+// Completion item kinds.
+const (
+	completionFunction = 3
+	completionField    = 5
+	completionVariable = 6
+	completionKeyword  = 14
+	completionConstant = 21
+	completionStruct   = 22
+)
+
+type documentSymbol struct {
+	Name           string           `json:"name"`
+	Detail         string           `json:"detail,omitempty"`
+	Kind           int              `json:"kind"`
+	Range          textRange        `json:"range"`
+	SelectionRange textRange        `json:"selectionRange"`
+	Children       []documentSymbol `json:"children,omitempty"`
+}
+
+// Symbol kinds.
+const (
+	symbolField    = 8
+	symbolFunction = 12
+	symbolStruct   = 23
+)
+
+// semanticTokens Five integers per token: the line, relative to the previous token, the start
+// character, relative to the previous token when on the same line, the length, the index of the
+// type in the legend and the set of modifiers, a bit per modifier of the legend.
+type semanticTokens struct {
+	Data []int `json:"data"`
+}
@@ -0,0 +1,149 @@
+package lsp
+
+import (
+	Compiler "compiladores/Compiler/analyzer"
+	"strings"
+)
+
+// Semantic token types and modifiers, in the order of the legend.
+const (
+	typeKeyword = iota
+	typeType
+	typeNumber
+	typeString
+	typeComment
+	typeOperator
+	typeVariable
+	typeParameter
+	typeProperty
+	typeStruct
+	typeFunction
+)
+
+const (
+	modifierDeclaration = 1 << iota
+	modifierReadonly
+)
+
+var legend = semanticTokensLegend{
+	TokenTypes: []string{"keyword", "type", "number", "string", "comment", "operator",
+		"variable", "parameter", "property", "struct", "function"},
+	TokenModifiers: []string{"declaration", "readonly"},
+}
+
+// classTypes Semantic token type of each token class but ClassNone.
+var classTypes = map[Compiler.TokenClass]int{
+	Compiler.ClassKeyword:  typeKeyword,
+	Compiler.ClassType:     typeType,
+	Compiler.ClassBoolean:  typeKeyword,
+	Compiler.ClassNumber:   typeNumber,
+	Compiler.ClassString:   typeString,
+	Compiler.ClassChar:     typeString,
+	Compiler.ClassComment:  typeComment,
+	Compiler.ClassOperator: typeOperator,
+}
+
+// symbolTypes Semantic token type of the identifiers of each kind of symbol.
+var symbolTypes = map[Compiler.SymbolKind]int{
+	Compiler.VarSymbol:       typeVariable,
+	Compiler.ConstSymbol:     typeVariable,
+	Compiler.ParamSymbol:     typeParameter,
+	Compiler.FieldSymbol:     typeProperty,
+	Compiler.RegisterSymbol:  typeStruct,
+	Compiler.ProcedureSymbol: typeFunction,
+	Compiler.FunctionSymbol:  typeFunction,
+}
+
+// semanticToken Type and modifiers of a token.
+type semanticToken struct {
+	typ       int
+	modifiers int
+}
+
+// semanticTokens Highlighting of the whole document: the tokens of the lexer by their class, and the
+// identifiers by the kind of symbol they declare or refer to. Identifiers the semantic analysis did
+// not resolve, delimiters and malformed tokens are left out. Tokens spanning several lines are split
+// at the line ends.
+func (d *document) semanticTokens() semanticTokens {
+	names := make(map[int]semanticToken) // by offset
+	if d.info != nil {
+		for id, sym := range d.info.Uses {
+			names[id.NamePos.Offset] = semanticToken{symbolTypes[sym.Kind], symbolModifiers(sym)}
+		}
+		for id, sym := range d.info.Defs {
+			names[id.NamePos.Offset] = semanticToken{symbolTypes[sym.Kind], symbolModifiers(sym) | modifierDeclaration}
+		}
+		for t := range d.info.Registers {
+			names[t.NamePos.Offset] = semanticToken{typeStruct, 0}
+		}
+	}
+
+	data := []int{}
+	c := &cursor{text: d.text}
+	prevLine, prevChar := 0, 0
+	for _, t := range d.tokens {
+		st, named := names[t.Offset]
+		if typ, ok := classTypes[t.Class()]; ok {
+			st = semanticToken{typ, 0}
+		} else if !named {
+			continue
+		}
+		c.advance(t.Offset)
+		lines := strings.Split(t.Value, "\n")
+		for i, text := range lines {
+			if length := utf16Count(strings.TrimSuffix(text, "\r")); length > 0 {
+				char := c.char
+				if c.line == prevLine {
+					char -= prevChar
+				}
+				data = append(data, c.line-prevLine, char, length, st.typ, st.modifiers)
+				prevLine, prevChar = c.line, c.char
+			}
+			if i < len(lines)-1 {
+				c.advance(c.offset + len(text) + 1)
+			}
+		}
+	}
+	return semanticTokens{data}
+}
+
+func symbolModifiers(sym *Compiler.Symbol) int {
+	if sym.Kind == Compiler.ConstSymbol {
+		return modifierReadonly
+	}
+	return 0
+}
+
+// cursor Converts increasing byte offsets of a text to protocol positions in a single pass.
+type cursor struct {
+	text   string
+	offset int
+	line   int
+	char   int // in UTF-16 code units
+}
+
+func (c *cursor) advance(offset int) {
+	if offset > len(c.text) {
+		offset = len(c.text)
+	}
+	if offset < c.offset {
+		return
+	}
+	for _, r := range c.text[c.offset:offset] {
+		if r == '\n' {
+			c.line++
+			c.char = 0
+		} else {
+			c.char += utf16Len(r)
+		}
+	}
+	c.offset = offset
+}
+
+func utf16Count(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16Len(r)
+	}
+	return n
+}
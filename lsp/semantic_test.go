@@ -0,0 +1,43 @@
+package lsp
+
+import "testing"
+
+func TestSemanticTokens(t *testing.T) {
+	text := "program P;\nvar { integer n; }\nconst { integer K = 2; }\n/# a\r\n b #/ main {\n\tvar { }\n\tn = K + 1; % 😀\n\tprint(\"😀\", n);\n}\n"
+	want := []int{
+		0, 0, 7, typeKeyword, 0, // program
+		1, 0, 3, typeKeyword, 0, // var
+		0, 6, 7, typeType, 0, // integer
+		0, 8, 1, typeVariable, modifierDeclaration, // n
+		1, 0, 5, typeKeyword, 0, // const
+		0, 8, 7, typeType, 0, // integer
+		0, 8, 1, typeVariable, modifierDeclaration | modifierReadonly, // K
+		0, 2, 1, typeOperator, 0, // =
+		0, 2, 1, typeNumber, 0, // 2
+		1, 0, 4, typeComment, 0, // /# a, without the CR
+		1, 0, 5, typeComment, 0, //  b #/
+		0, 6, 4, typeKeyword, 0, // main
+		1, 1, 3, typeKeyword, 0, // var
+		1, 1, 1, typeVariable, 0, // n
+		0, 2, 1, typeOperator, 0, // =
+		0, 2, 1, typeVariable, modifierReadonly, // K
+		0, 2, 1, typeOperator, 0, // +
+		0, 2, 1, typeNumber, 0, // 1
+		0, 3, 4, typeComment, 0, // % 😀, the emoji is two UTF-16 code units
+		1, 1, 5, typeKeyword, 0, // print
+		0, 6, 4, typeString, 0, // "😀"
+		0, 6, 1, typeVariable, 0, // n
+	}
+	got := analyze("file:///tmp/a.txt", 1, text).semanticTokens().Data
+	if len(got) != len(want) {
+		t.Fatalf("got %d integers %v, want %d", len(got), got, len(want))
+	}
+	for i := 0; i < len(want); i += 5 {
+		for j := i; j < i+5; j++ {
+			if got[j] != want[j] {
+				t.Errorf("token %d is %v, want %v", i/5, got[i:i+5], want[i:i+5])
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,327 @@
+// Package lsp serves the Language Server Protocol over a pair of streams, usually the standard input
+// and output of the compiler: diagnostics of the open documents, go to definition, hover, completion,
+// document symbols and semantic tokens.
+package lsp
+
+import (
+	"bufio"
+	Compiler "compiladores/Compiler/analyzer"
+	"compiladores/Compiler/ast"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// ErrNoShutdown Returned by Serve when the client asks to exit, or closes the stream, without shutting
+// the server down first.
+var ErrNoShutdown = errors.New("exit without shutdown")
+
+// server Holds the state of a session with a single client.
+type server struct {
+	out         io.Writer
+	initialized bool // the initialize request was answered
+	shutdown    bool // the shutdown request was answered, only exit is expected
+	documents   map[string]*document
+}
+
+// document An open text document with the result of its analysis.
+type document struct {
+	uri     string
+	version int
+	text    string
+	tokens  []Compiler.Token // comments included
+	program *ast.Program
+	info    *Compiler.Info
+	errors  []Compiler.Diagnostic
+}
+
+// Serve Answers the requests read from r, writing the responses and notifications to w, until the
+// client sends the exit notification. Requests are handled one at a time, in the order they arrive.
+func Serve(r io.Reader, w io.Writer) error {
+	s := &server{out: w, documents: make(map[string]*document)}
+	in := bufio.NewReader(r)
+	for {
+		body, err := readMessage(in)
+		if err == io.EOF {
+			return ErrNoShutdown
+		} else if err != nil {
+			return err
+		}
+		var msg message
+		if err := json.Unmarshal(body, &msg); err != nil {
+			if err := s.reply(nil, nil, &responseError{codeParseError, err.Error()}); err != nil {
+				return err
+			}
+			continue
+		}
+		if msg.Method == "exit" {
+			if !s.shutdown {
+				return ErrNoShutdown
+			}
+			return nil
+		}
+		if msg.Method == "" { // a response, the server sends no requests
+			continue
+		}
+
+		result, rpcErr := s.handle(msg)
+		if msg.ID == nil { // notifications are never answered
+			continue
+		}
+		if err := s.reply(msg.ID, result, rpcErr); err != nil {
+			return err
+		}
+	}
+}
+
+// handle Dispatches a request or a notification, recovering from failures so that a single
+// message cannot bring the server down.
+func (s *server) handle(msg message) (result interface{}, rpcErr *responseError) {
+	defer func() {
+		if r := recover(); r != nil {
+			result, rpcErr = nil, &responseError{codeInternalError, fmt.Sprintf("%s: %v", msg.Method, r)}
+		}
+	}()
+	switch {
+	case msg.Method == "initialize":
+		if s.initialized {
+			return nil, &responseError{codeInvalidRequest, "initialize sent twice"}
+		}
+		s.initialized = true
+		return initializeResult{
+			Capabilities: serverCapabilities{
+				TextDocumentSync:       textDocumentSyncOptions{OpenClose: true, Change: syncFull},
+				DefinitionProvider:     true,
+				HoverProvider:          true,
+				CompletionProvider:     completionOptions{TriggerCharacters: []string{"."}},
+				DocumentSymbolProvider: true,
+				SemanticTokensProvider: semanticTokensOptions{Legend: legend, Full: true},
+			},
+			ServerInfo: serverInfo{"compiler"},
+		}, nil
+	case !s.initialized:
+		return nil, &responseError{codeServerNotInitialized, "the server is not initialized"}
+	case s.shutdown:
+		return nil, &responseError{codeInvalidRequest, "the server is shutting down"}
+	}
+
+	switch msg.Method {
+	case "initialized":
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/didOpen":
+		var params didOpenTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		d := params.TextDocument
+		return nil, s.update(d.URI, d.Version, d.Text)
+	case "textDocument/didChange":
+		var params didChangeTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			d := params.TextDocument
+			return nil, s.update(d.URI, d.Version, params.ContentChanges[n-1].Text)
+		}
+	case "textDocument/didClose":
+		var params didCloseTextDocumentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		uri := params.TextDocument.URI
+		delete(s.documents, uri)
+		return nil, s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{URI: uri, Diagnostics: []diagnostic{}})
+	case "textDocument/definition", "textDocument/hover", "textDocument/completion":
+		var params textDocumentPositionParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		d := s.documents[params.TextDocument.URI]
+		if d == nil {
+			return nil, &responseError{codeInvalidParams, "unknown document " + params.TextDocument.URI}
+		}
+		offset := d.offset(params.Position)
+		switch msg.Method {
+		case "textDocument/definition":
+			return d.definition(offset), nil
+		case "textDocument/hover":
+			return d.hover(offset), nil
+		default:
+			return d.completion(offset), nil
+		}
+	case "textDocument/documentSymbol", "textDocument/semanticTokens/full":
+		var params documentParams
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+		d := s.documents[params.TextDocument.URI]
+		if d == nil {
+			return nil, &responseError{codeInvalidParams, "unknown document " + params.TextDocument.URI}
+		}
+		if msg.Method == "textDocument/documentSymbol" {
+			return d.symbols(), nil
+		}
+		return d.semanticTokens(), nil
+	default:
+		return nil, &responseError{codeMethodNotFound, "method not supported: " + msg.Method}
+	}
+	return nil, nil
+}
+
+func invalidParams(err error) *responseError {
+	return &responseError{codeInvalidParams, err.Error()}
+}
+
+// reply Answers the request with the given id.
+func (s *server) reply(id json.RawMessage, result interface{}, rpcErr *responseError) error {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	resp := response{JSONRPC: "2.0", ID: id, Error: rpcErr}
+	if rpcErr == nil {
+		body, err := json.Marshal(result)
+		if err != nil {
+			return err
+		}
+		raw := json.RawMessage(body)
+		resp.Result = &raw
+	}
+	return writeMessage(s.out, resp)
+}
+
+func (s *server) notify(method string, params interface{}) *responseError {
+	if err := writeMessage(s.out, notification{"2.0", method, params}); err != nil {
+		return &responseError{codeInternalError, err.Error()}
+	}
+	return nil
+}
+
+// ====================================== ANALYSIS ======================================
+
+// update Analyzes the new text of a document and publishes its diagnostics.
+func (s *server) update(uri string, version int, text string) *responseError {
+	d := analyze(uri, version, text)
+	s.documents[uri] = d
+
+	list := make([]diagnostic, 0, len(d.errors))
+	for _, e := range d.errors {
+		list = append(list, diagnostic{
+			Range:    d.spanRange(e.Span.Start, e.Span.End),
+			Severity: severities[e.Severity],
+			Code:     e.Code,
+			Source:   "compiler",
+			Message:  e.Message,
+		})
+	}
+	return s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{uri, version, list})
+}
+
+// severities Protocol severity of each severity.
+var severities = map[Compiler.Severity]int{
+	Compiler.SeverityError:   severityError,
+	Compiler.SeverityWarning: severityWarning,
+	Compiler.SeverityInfo:    severityInformation,
+}
+
+// analyze Runs every phase on the text of a document. As in the check command, the semantic errors
+// are only reported for programs free of lexical and syntax errors, but the semantic analysis runs
+// on the tree the parser recovered anyway, so that the navigation requests work while editing.
+func analyze(uri string, version int, text string) *document {
+	d := &document{uri: uri, version: version, text: text}
+	lexer := Compiler.Lex(fileName(uri), text)
+	d.tokens, _ = Compiler.Tokens(lexer)
+	lexer.Rewind(0) // the parser reads the same tokens
+	parser := Compiler.Syntax(lexer)
+	d.program = parser.Parse()
+	d.errors = parser.LexicalErrors()
+	if !Compiler.HasErrors(d.errors) { // malformed tokens always cause syntax errors as well
+		d.errors = append(d.errors, parser.Errors()...)
+	}
+
+	analyzer := Compiler.Semantic(d.program)
+	d.info = analyzer.Analyze()
+	errs := analyzer.Errors()
+	checker := Compiler.TypeCheck(d.program, d.info)
+	checker.Check()
+	errs = append(errs, checker.Errors()...)
+	if !Compiler.HasErrors(d.errors) {
+		d.errors = append(d.errors, errs...)
+	}
+	return d
+}
+
+// fileName Name of the source for diagnostics: the path of file URIs, the URI otherwise.
+func fileName(uri string) string {
+	if u, err := url.Parse(uri); err == nil && u.Scheme == "file" {
+		return filepath.FromSlash(u.Path)
+	}
+	return uri
+}
+
+// ====================================== POSITIONS ======================================
+
+// position Protocol position of a byte offset of the text.
+func (d *document) position(offset int) position {
+	if offset > len(d.text) {
+		offset = len(d.text)
+	}
+	var p position
+	for _, r := range d.text[:offset] {
+		if r == '\n' {
+			p.Line++
+			p.Character = 0
+		} else {
+			p.Character += utf16Len(r)
+		}
+	}
+	return p
+}
+
+// offset Byte offset of a protocol position. Positions past the end of a line are taken as the end
+// of the line, and positions past the last line as the end of the text.
+func (d *document) offset(p position) int {
+	i := 0
+	for line := 0; line < p.Line; line++ {
+		j := strings.IndexByte(d.text[i:], '\n')
+		if j < 0 {
+			return len(d.text)
+		}
+		i += j + 1
+	}
+	for col := 0; i < len(d.text) && col < p.Character; {
+		r, size := utf8.DecodeRuneInString(d.text[i:])
+		if r == '\n' || strings.HasPrefix(d.text[i:], "\r\n") {
+			break
+		}
+		col += utf16Len(r)
+		i += size
+	}
+	return i
+}
+
+func utf16Len(r rune) int {
+	if r >= 0x10000 {
+		return 2
+	}
+	return 1
+}
+
+// spanRange Range of the bytes of the text from start up to end.
+func (d *document) spanRange(start, end int) textRange {
+	if end < start {
+		end = start
+	}
+	return textRange{d.position(start), d.position(end)}
+}
+
+// nameRange Range of a name declared or used at pos.
+func (d *document) nameRange(pos ast.Pos, name string) textRange {
+	return d.spanRange(pos.Offset, pos.Offset+len(name))
+}
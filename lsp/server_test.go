@@ -0,0 +1,344 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"unicode/utf16"
+)
+
+// client Editor talking to a server run by Serve over in-memory pipes.
+type client struct {
+	t    *testing.T
+	in   *io.PipeWriter // read by the server
+	out  *bufio.Reader  // written by the server
+	done chan error     // the result of Serve
+	id   int
+}
+
+func newClient(t *testing.T) *client {
+	inR, inW := io.Pipe()
+	outR, outW := io.Pipe()
+	c := &client{t: t, in: inW, out: bufio.NewReader(outR), done: make(chan error, 1)}
+	go func() {
+		err := Serve(inR, outW)
+		outW.Close()
+		c.done <- err
+	}()
+	t.Cleanup(func() { inW.Close() })
+	return c
+}
+
+// send Writes a message to the server, a notification when id is nil.
+func (c *client) send(id interface{}, method string, params interface{}) {
+	c.t.Helper()
+	msg := map[string]interface{}{"jsonrpc": "2.0", "method": method}
+	if id != nil {
+		msg["id"] = id
+	}
+	if params != nil {
+		msg["params"] = params
+	}
+	if err := writeMessage(c.in, msg); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// receive Reads the next message written by the server.
+func (c *client) receive() map[string]json.RawMessage {
+	c.t.Helper()
+	body, err := readMessage(c.out)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	var msg map[string]json.RawMessage
+	if err := json.Unmarshal(body, &msg); err != nil {
+		c.t.Fatal(err)
+	}
+	return msg
+}
+
+// request Sends a request and decodes the result of its response into result, returning the error
+// of the response.
+func (c *client) request(method string, params, result interface{}) *responseError {
+	c.t.Helper()
+	c.id++
+	c.send(c.id, method, params)
+	msg := c.receive()
+	var id int
+	if err := json.Unmarshal(msg["id"], &id); err != nil || id != c.id {
+		c.t.Fatalf("got response %s to %s, want id %d", msg["id"], method, c.id)
+	}
+	if raw, ok := msg["error"]; ok {
+		var rpcErr responseError
+		if err := json.Unmarshal(raw, &rpcErr); err != nil {
+			c.t.Fatal(err)
+		}
+		return &rpcErr
+	}
+	if result != nil {
+		if err := json.Unmarshal(msg["result"], result); err != nil {
+			c.t.Fatal(err)
+		}
+	}
+	return nil
+}
+
+// diagnostics Reads the diagnostics the server publishes after a change of a document.
+func (c *client) diagnostics() publishDiagnosticsParams {
+	c.t.Helper()
+	msg := c.receive()
+	var method string
+	var params publishDiagnosticsParams
+	json.Unmarshal(msg["method"], &method)
+	if method != "textDocument/publishDiagnostics" {
+		c.t.Fatalf("got %v, want diagnostics", msg)
+	}
+	if err := json.Unmarshal(msg["params"], &params); err != nil {
+		c.t.Fatal(err)
+	}
+	return params
+}
+
+// initialize Sends the initialize request and the initialized notification.
+func (c *client) initialize() {
+	c.t.Helper()
+	if err := c.request("initialize", map[string]interface{}{}, nil); err != nil {
+		c.t.Fatal(err)
+	}
+	c.send(nil, "initialized", map[string]interface{}{})
+}
+
+// open Opens a document and returns the diagnostics the server publishes.
+func (c *client) open(uri, text string) publishDiagnosticsParams {
+	c.t.Helper()
+	c.send(nil, "textDocument/didOpen", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri, "languageId": "compiler", "version": 1, "text": text},
+	})
+	return c.diagnostics()
+}
+
+// exit Sends the exit notification and returns the result of Serve.
+func (c *client) exit() error {
+	c.send(nil, "exit", nil)
+	return <-c.done
+}
+
+// at Protocol position of the n-th occurrence, from 0, of substr in text, plus skip characters.
+func at(text, substr string, n, skip int) position {
+	i := -1
+	for ; n >= 0; n-- {
+		i += 1 + strings.Index(text[i+1:], substr)
+	}
+	lines := strings.Split(text[:i], "\n")
+	last := lines[len(lines)-1]
+	return position{len(lines) - 1, len(utf16.Encode([]rune(last))) + skip}
+}
+
+func TestLifecycle(t *testing.T) {
+	c := newClient(t)
+	if err := c.request("textDocument/hover", map[string]interface{}{}, nil); err == nil || err.Code != codeServerNotInitialized {
+		t.Errorf("hover before initialize returned error %v, want code %d", err, codeServerNotInitialized)
+	}
+	var result initializeResult
+	if err := c.request("initialize", map[string]interface{}{}, &result); err != nil {
+		t.Fatal(err)
+	}
+	if !result.Capabilities.HoverProvider || !result.Capabilities.DefinitionProvider || result.ServerInfo.Name != "compiler" {
+		t.Errorf("got capabilities %+v", result)
+	}
+	if err := c.request("initialize", map[string]interface{}{}, nil); err == nil || err.Code != codeInvalidRequest {
+		t.Errorf("second initialize returned error %v, want code %d", err, codeInvalidRequest)
+	}
+	if err := c.request("workspace/unknown", map[string]interface{}{}, nil); err == nil || err.Code != codeMethodNotFound {
+		t.Errorf("unknown method returned error %v, want code %d", err, codeMethodNotFound)
+	}
+	if err := c.request("shutdown", nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.request("textDocument/hover", map[string]interface{}{}, nil); err == nil || err.Code != codeInvalidRequest {
+		t.Errorf("hover after shutdown returned error %v, want code %d", err, codeInvalidRequest)
+	}
+	if err := c.exit(); err != nil {
+		t.Errorf("exit after shutdown returned %v, want nil", err)
+	}
+}
+
+func TestExitWithoutShutdown(t *testing.T) {
+	c := newClient(t)
+	c.initialize()
+	if err := c.exit(); err != ErrNoShutdown {
+		t.Errorf("exit without shutdown returned %v, want ErrNoShutdown", err)
+	}
+
+	c = newClient(t)
+	c.initialize()
+	c.in.Close()
+	if err := <-c.done; err != ErrNoShutdown {
+		t.Errorf("closing the stream returned %v, want ErrNoShutdown", err)
+	}
+}
+
+func TestDiagnostics(t *testing.T) {
+	const uri = "file:///tmp/a.txt"
+	text := "program P;\nvar { integer n; }\nconst { }\nmain {\n\tvar { }\n\tn = m;\n}\n"
+	c := newClient(t)
+	c.initialize()
+	got := c.open(uri, text)
+	want := diagnostic{textRange{at(text, "m;", 0, 0), at(text, "m;", 0, 1)}, severityError, "undeclared", "compiler", "undeclared name: m"}
+	if got.URI != uri || got.Version != 1 || len(got.Diagnostics) != 1 || got.Diagnostics[0] != want {
+		t.Errorf("got %+v, want version 1 of %s with %+v", got, uri, want)
+	}
+
+	broken := strings.Replace(text, "n = m;", "n = ;", 1)
+	c.send(nil, "textDocument/didChange", map[string]interface{}{
+		"textDocument":   map[string]interface{}{"uri": uri, "version": 2},
+		"contentChanges": []map[string]interface{}{{"text": text}, {"text": broken}},
+	})
+	got = c.diagnostics()
+	want = diagnostic{textRange{at(broken, ";", 4, 0), at(broken, ";", 4, 1)}, severityError, "unexpected-token", "compiler", `expected value, found ";"`}
+	if got.Version != 2 || len(got.Diagnostics) != 1 || got.Diagnostics[0] != want {
+		t.Errorf("got %+v, want version 2 with %+v", got, want)
+	}
+
+	c.send(nil, "textDocument/didClose", map[string]interface{}{"textDocument": map[string]interface{}{"uri": uri}})
+	if got = c.diagnostics(); got.URI != uri || len(got.Diagnostics) != 0 {
+		t.Errorf("got %+v after closing, want no diagnostics", got)
+	}
+	if err := c.request("textDocument/hover", textDocumentPositionParams{textDocumentIdentifier{uri}, position{}}, nil); err == nil || err.Code != codeInvalidParams {
+		t.Errorf("hover on a closed document returned error %v, want code %d", err, codeInvalidParams)
+	}
+}
+
+const navigation = `program P;
+var { integer n; }
+const { integer LIM = 10; }
+register Ponto { integer x; string nome; }
+function Dobro (integer k) : integer {
+	var { integer r; }
+	r = k * 2;
+	return r;
+}
+main {
+	var { Ponto a; }
+	n = Dobro(LIM);
+	a.x = n;
+	print(a.);
+}
+`
+
+func TestNavigation(t *testing.T) {
+	const uri = "file:///tmp/nav.txt"
+	c := newClient(t)
+	c.initialize()
+	c.open(uri, navigation)
+	params := func(p position) textDocumentPositionParams {
+		return textDocumentPositionParams{textDocumentIdentifier{uri}, p}
+	}
+
+	var loc *location
+	for _, p := range []position{at(navigation, "Dobro", 1, 0), at(navigation, "Dobro", 1, 5)} {
+		if err := c.request("textDocument/definition", params(p), &loc); err != nil {
+			t.Fatal(err)
+		}
+		want := location{uri, textRange{at(navigation, "Dobro", 0, 0), at(navigation, "Dobro", 0, 5)}}
+		if loc == nil || *loc != want {
+			t.Errorf("definition at %+v is %+v, want %+v", p, loc, want)
+		}
+	}
+	if err := c.request("textDocument/definition", params(at(navigation, "print", 0, 2)), &loc); err != nil || loc != nil {
+		t.Errorf("definition of a keyword is %+v, %v, want null", loc, err)
+	}
+
+	var h *hover
+	if err := c.request("textDocument/hover", params(at(navigation, "k *", 0, 0)), &h); err != nil {
+		t.Fatal(err)
+	}
+	want := "```\ninteger k\n```\n\nparameter of function Dobro, declared at line 5"
+	if h == nil || h.Contents.Kind != "markdown" || h.Contents.Value != want || h.Range != (textRange{at(navigation, "k *", 0, 0), at(navigation, "k *", 0, 1)}) {
+		t.Errorf("hover is %+v, want %q", h, want)
+	}
+	if err := c.request("textDocument/hover", params(at(navigation, "LIM", 1, 1)), &h); err != nil {
+		t.Fatal(err)
+	}
+	if want := "```\ninteger LIM = 10\n```\n\nglobal constant of program P, declared at line 3"; h == nil || h.Contents.Value != want {
+		t.Errorf("hover is %+v, want %q", h, want)
+	}
+
+	var items []completionItem
+	if err := c.request("textDocument/completion", params(at(navigation, "a.)", 0, 2)), &items); err != nil {
+		t.Fatal(err)
+	}
+	fields := []completionItem{{"x", completionField, "integer x"}, {"nome", completionField, "string nome"}}
+	if len(items) != len(fields) || items[0] != fields[0] || items[1] != fields[1] {
+		t.Errorf("completion after a. is %+v, want %+v", items, fields)
+	}
+	if err := c.request("textDocument/completion", params(at(navigation, "n = Dobro", 0, 0)), &items); err != nil {
+		t.Fatal(err)
+	}
+	labels := make(map[string]int)
+	for _, item := range items {
+		labels[item.Label] = item.Kind
+	}
+	for label, kind := range map[string]int{"a": completionVariable, "n": completionVariable, "LIM": completionConstant, "Ponto": completionStruct, "Dobro": completionFunction, "while": completionKeyword} {
+		if labels[label] != kind {
+			t.Errorf("completion in main has %s of kind %d, want %d", label, labels[label], kind)
+		}
+	}
+	for _, label := range []string{"k", "r", "x"} {
+		if _, ok := labels[label]; ok {
+			t.Errorf("completion in main offers %s, out of scope", label)
+		}
+	}
+}
+
+// TestUTF16 Positions count UTF-16 code units, two for characters outside the BMP, and lines end in
+// "\n" or "\r\n".
+func TestUTF16(t *testing.T) {
+	const uri = "file:///tmp/utf16.txt"
+	text := "program P;\r\nvar { integer n; }\r\nconst { }\r\nmain {\r\n\tvar { }\r\n\tprint(\"😀é\", n, m);\r\n}\r\n"
+	c := newClient(t)
+	c.initialize()
+	got := c.open(uri, text)
+	start := position{5, 17} // after a tab and print("😀é", n, counted in UTF-16
+	if len(got.Diagnostics) != 1 || got.Diagnostics[0].Range != (textRange{start, position{5, 18}}) {
+		t.Errorf("got %+v, want an error at %+v", got.Diagnostics, start)
+	}
+
+	var loc *location
+	want := location{uri, textRange{position{1, 14}, position{1, 15}}}
+	if err := c.request("textDocument/definition", textDocumentPositionParams{textDocumentIdentifier{uri}, position{5, 14}}, &loc); err != nil {
+		t.Fatal(err)
+	}
+	if loc == nil || *loc != want {
+		t.Errorf("definition of n is %+v, want %+v", loc, want)
+	}
+
+	d := analyze(uri, 1, text)
+	line := strings.Index(text, "\tprint")
+	tests := []struct {
+		p      position
+		offset int
+	}{
+		{position{0, 0}, 0},
+		{position{5, 0}, line},
+		{position{5, 8}, line + len("\tprint(\"")},
+		{position{5, 10}, line + len("\tprint(\"😀")},
+		{position{5, 11}, line + len("\tprint(\"😀é")},
+		{position{5, 100}, strings.Index(text[line:], "\r\n") + line}, // the end of the line, before the CR
+		{position{7, 0}, len(text)},
+		{position{9, 3}, len(text)},
+	}
+	for _, test := range tests {
+		if offset := d.offset(test.p); offset != test.offset {
+			t.Errorf("offset of %+v is %d, want %d", test.p, offset, test.offset)
+		}
+		if test.p.Line < 7 && test.p.Character < 100 {
+			if p := d.position(test.offset); p != test.p {
+				t.Errorf("position of %d is %+v, want %+v", test.offset, p, test.p)
+			}
+		}
+	}
+}
@@ -0,0 +1,373 @@
+package main
+
+import (
+	"bytes"
+	Compiler "compiladores/Compiler/analyzer"
+	"compiladores/Compiler/codegen"
+	"compiladores/Compiler/format"
+	"compiladores/Compiler/grammar"
+	"compiladores/Compiler/highlight"
+	"compiladores/Compiler/interpreter"
+	"compiladores/Compiler/lsp"
+	"compiladores/Compiler/vm"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+)
+
+// lexCommand Writes the tokens of each file.
+func lexCommand(args []string) int {
+	flags, stdin := newFlags("lex")
+	output := flags.String("o", "", "write the tokens to `file` instead of the standard output")
+	format := formatFlag(flags, tokenFormats)
+	comments := flags.Bool("comments", true, "list the comments as tokens; -comments=false leaves them out")
+	list, code := sources(flags, stdin, args, -1)
+	if list == nil {
+		return code
+	}
+	if code := checkFormat(*format, tokenFormats); code != exitOK {
+		return code
+	}
+
+	units := make([]*unit, len(list))
+	tokens := make([][]Compiler.Token, len(list))
+	for i, src := range list {
+		units[i] = &unit{source: src}
+		tokens[i], units[i].errors = Compiler.Tokens(Compiler.Lex(src.name, src.content))
+		if !*comments {
+			tokens[i] = withoutComments(tokens[i])
+		}
+		if Compiler.HasErrors(units[i].errors) {
+			units[i].code = exitLexical
+		}
+	}
+	if *format != formatText { // the errors are part of the output
+		if code := writeOutput(*output, func(w io.Writer) error { return writeTokens(w, *format, units, tokens) }); code != exitOK {
+			return code
+		}
+		return exitCode(units)
+	}
+
+	code = writeOutput(*output, func(w io.Writer) error {
+		for i, u := range units {
+			if len(units) > 1 {
+				fmt.Fprintf(w, "%s:\n", u.name)
+			}
+			if err := Compiler.WriteTokenTable(w, tokens[i], u.errors); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if errCode := report(units); code == exitOK {
+		code = errCode
+	}
+	return code
+}
+
+func withoutComments(tokens []Compiler.Token) []Compiler.Token {
+	var list []Compiler.Token
+	for _, t := range tokens {
+		if !t.IsComment() {
+			list = append(list, t)
+		}
+	}
+	return list
+}
+
+// parseCommand Reports lexical and syntax errors.
+func parseCommand(args []string) int {
+	flags, stdin := newFlags("parse")
+	format := formatFlag(flags, diagnosticFormats)
+	tree := flags.Bool("tree", false, "print the concrete syntax tree of each file, trivia included")
+	list, code := sources(flags, stdin, args, -1)
+	if list == nil {
+		return code
+	}
+	if code := checkFormat(*format, diagnosticFormats); code != exitOK {
+		return code
+	}
+	units := analyzeAll(list, false)
+	if *tree {
+		code := writeOutput("", func(w io.Writer) error {
+			for _, u := range units {
+				if len(units) > 1 {
+					fmt.Fprintf(w, "%s:\n", u.name)
+				}
+				if err := Compiler.WriteSyntaxTree(w, u.tree); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if code != exitOK {
+			return code
+		}
+	}
+	return writeReport(*format, units)
+}
+
+// checkCommand Reports every compile time error.
+func checkCommand(args []string) int {
+	flags, stdin := newFlags("check")
+	format := formatFlag(flags, diagnosticFormats)
+	list, code := sources(flags, stdin, args, -1)
+	if list == nil {
+		return code
+	}
+	if code := checkFormat(*format, diagnosticFormats); code != exitOK {
+		return code
+	}
+	return writeReport(*format, analyzeAll(list, true))
+}
+
+// writeReport Reports the errors of the units in the given format and returns the exit code.
+func writeReport(format string, units []*unit) int {
+	if format != formatText {
+		return writeDiagnostics(os.Stdout, format, units)
+	}
+	return report(units)
+}
+
+// runCommand Executes a program. With --stdin, read commands find the input already consumed.
+func runCommand(args []string) int {
+	flags, stdin := newFlags("run")
+	useVM := flags.Bool("vm", false, "compile to bytecode and execute it on the virtual machine")
+	list, code := sources(flags, stdin, args, 1)
+	if list == nil {
+		return code
+	}
+	u := analyze(list[0], true)
+	if code := report([]*unit{u}); code != exitOK {
+		return code
+	}
+
+	var err error
+	if *useVM {
+		err = vm.New(vm.Compile(u.program, u.info), os.Stdin, os.Stdout).Run()
+	} else {
+		err = interpreter.New(u.program, u.info, os.Stdin, os.Stdout).Run()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, u.name+":", err)
+		return exitRuntime
+	}
+	return exitOK
+}
+
+// buildCommand Translates a program to a native executable or to C, assembly or bytecode source.
+func buildCommand(args []string) int {
+	flags, stdin := newFlags("build")
+	output := flags.String("o", "", "write the result to `file`; defaults to a.out for native builds and to the standard output otherwise")
+	target := flags.String("target", "native", "`kind` of output: native, c, asm or bytecode")
+	list, code := sources(flags, stdin, args, 1)
+	if list == nil {
+		return code
+	}
+	switch *target {
+	case "native", "c", "asm", "bytecode":
+	default:
+		fmt.Fprintf(os.Stderr, "compiler: unknown target %q\n", *target)
+		return exitUsage
+	}
+	u := analyze(list[0], true)
+	if code := report([]*unit{u}); code != exitOK {
+		return code
+	}
+
+	switch *target {
+	case "c":
+		return writeOutput(*output, func(w io.Writer) error { return codegen.GenerateC(w, u.program, u.info) })
+	case "asm":
+		return writeOutput(*output, func(w io.Writer) error { return codegen.GenerateAsm(w, u.program, u.info) })
+	case "bytecode":
+		return writeOutput(*output, func(w io.Writer) error {
+			vm.Disassemble(w, vm.Compile(u.program, u.info))
+			return nil
+		})
+	}
+	if *output == "" {
+		*output = "a.out"
+	}
+	if err := codegen.Build(u.program, u.info, *output); err != nil {
+		fmt.Fprintln(os.Stderr, "compiler:", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// fmtCommand Writes a file in canonical form or, with -w, puts each file in canonical form in place.
+// With --check or --diff the files are compared with their canonical form instead, and the command
+// fails when they differ.
+func fmtCommand(args []string) int {
+	flags, stdin := newFlags("fmt")
+	output := flags.String("o", "", "write the result to `file` instead of the standard output")
+	check := flags.Bool("check", false, "list the files that are not in canonical form")
+	diff := flags.Bool("diff", false, "print the changes that would put the files in canonical form")
+	write := flags.Bool("w", false, "write the canonical form of each file back to the file")
+	list, code := sources(flags, stdin, args, -1)
+	if list == nil {
+		return code
+	}
+	switch {
+	case *write && (*stdin || *output != "" || *check || *diff):
+		fmt.Fprintln(os.Stderr, "compiler: fmt -w cannot be used with -o, --check, --diff or --stdin")
+		return exitUsage
+	case !*write && !*check && !*diff && len(list) > 1:
+		fmt.Fprintln(os.Stderr, "compiler: fmt prints a single file, use -w to format several in place")
+		return exitUsage
+	}
+	units := analyzeAll(list, false)
+	if code := report(units); code != exitOK {
+		return code
+	}
+
+	if *write {
+		for _, u := range units {
+			if err := writeFormatted(u); err != nil {
+				fmt.Fprintln(os.Stderr, "compiler:", err)
+				return exitFailure
+			}
+		}
+		return exitOK
+	}
+	if !*check && !*diff {
+		return writeOutput(*output, func(w io.Writer) error { return format.Fprint(w, units[0].tree) })
+	}
+	code = exitOK
+	writeCode := writeOutput(*output, func(w io.Writer) error {
+		for _, u := range units {
+			formatted := format.Source(u.tree)
+			if string(formatted) == u.content {
+				continue
+			}
+			code = exitFailure
+			if *check {
+				if _, err := fmt.Fprintln(w, u.name); err != nil {
+					return err
+				}
+			}
+			if *diff {
+				if _, err := w.Write(format.Diff(u.name, u.name+" (formatted)", []byte(u.content), formatted)); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if writeCode != exitOK {
+		return writeCode
+	}
+	return code
+}
+
+// writeFormatted Replaces the file of a unit by its canonical form, unless it already is.
+func writeFormatted(u *unit) error {
+	formatted := format.Source(u.tree)
+	if string(formatted) == u.content {
+		return nil
+	}
+	info, err := os.Stat(u.name)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(u.name, formatted, info.Mode().Perm())
+}
+
+// lspCommand Serves the Language Server Protocol over the standard input and output until the editor
+// asks the server to exit.
+func lspCommand(args []string) int {
+	flags := commandFlags("lsp")
+	flags.Bool("stdio", true, "talk to the editor over the standard input and output, the only transport")
+	if err := flags.Parse(args); err == flag.ErrHelp {
+		return exitOK
+	} else if err != nil {
+		return exitUsage
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return exitUsage
+	}
+	if err := lsp.Serve(os.Stdin, os.Stdout); err != nil {
+		if err != lsp.ErrNoShutdown {
+			fmt.Fprintln(os.Stderr, "compiler:", err)
+		}
+		return exitFailure
+	}
+	return exitOK
+}
+
+// highlightCommand Writes the TextMate grammar of the language.
+func highlightCommand(args []string) int {
+	flags := commandFlags("highlight")
+	output := flags.String("o", "", "write the grammar to `file` instead of the standard output")
+	if err := flags.Parse(args); err == flag.ErrHelp {
+		return exitOK
+	} else if err != nil {
+		return exitUsage
+	}
+	if flags.NArg() > 0 {
+		flags.Usage()
+		return exitUsage
+	}
+	return writeOutput(*output, highlight.TextMate)
+}
+
+// grammarCommand Reads a grammar in the GOLD format, reports what keeps it from being LL(1) and writes
+// the FIRST and FOLLOW sets of its nonterminals, its parse table or a recursive-descent parser of it.
+// The output is written even when the grammar is not LL(1), the first of the conflicting productions
+// being chosen, but the command fails.
+func grammarCommand(args []string) int {
+	flags, stdin := newFlags("grammar")
+	output := flags.String("o", "", "write the result to `file` instead of the standard output")
+	format := formatFlag(flags, grammarFormats)
+	pkg := flags.String("package", "parser", "`name` of the package of the generated parser")
+	list, code := sources(flags, stdin, args, 1)
+	if list == nil {
+		return code
+	}
+	if code := checkFormat(*format, grammarFormats); code != exitOK {
+		return code
+	}
+
+	src := list[0]
+	g, errs := grammar.Parse(src.name, src.content)
+	if g == nil {
+		for _, d := range errs {
+			d.Render(os.Stderr, src.content)
+		}
+		return exitFailure
+	}
+	a := grammar.Analyze(g)
+	problems := a.Problems()
+	for _, d := range problems {
+		d.Render(os.Stderr, src.content)
+	}
+
+	var b bytes.Buffer // nothing is written when the parser cannot be generated
+	var err error
+	switch *format {
+	case formatText:
+		err = grammar.WriteReport(&b, a)
+	case formatJSON:
+		err = grammar.WriteJSON(&b, a)
+	case formatGo:
+		err = grammar.WriteGo(&b, a, *pkg)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "compiler:", err)
+		return exitFailure
+	}
+	if code := writeOutput(*output, func(w io.Writer) error {
+		_, err := w.Write(b.Bytes())
+		return err
+	}); code != exitOK {
+		return code
+	}
+	if Compiler.HasErrors(problems) {
+		return exitFailure
+	}
+	return exitOK
+}
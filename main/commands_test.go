@@ -0,0 +1,138 @@
+package main
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const (
+	formatted   = "program P;\n\nvar {\n\tinteger i;\n}\n\nconst { }\n\nmain {\n\tvar { }\n\ti = 1;\n}\n"
+	unformatted = "program P;\n\nvar {\n\tinteger i;\n}\n\nconst { }\n\nmain {\n\tvar { }\n\ti=1;\n}\n"
+)
+
+// writeFiles Writes the files in a temporary directory and returns their paths.
+func writeFiles(t *testing.T, contents ...string) []string {
+	t.Helper()
+	dir := t.TempDir()
+	var paths []string
+	for i, content := range contents {
+		path := filepath.Join(dir, string(rune('a'+i))+".txt")
+		if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+// fmtOutput Runs the fmt command with the flags on the files, writing to a temporary file, and
+// returns the exit code and what was written.
+func fmtOutput(t *testing.T, flags []string, files []string) (int, string) {
+	t.Helper()
+	output := filepath.Join(t.TempDir(), "out")
+	code := fmtCommand(append(append(flags, "-o", output), files...))
+	content, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return code, string(content)
+}
+
+func TestFmt(t *testing.T) {
+	files := writeFiles(t, unformatted, formatted)
+	code, out := fmtOutput(t, nil, files[:1])
+	if code != exitOK || out != formatted {
+		t.Errorf("fmt exited with %d and wrote %q, want %d and %q", code, out, exitOK, formatted)
+	}
+}
+
+func TestFmtWrite(t *testing.T) {
+	files := writeFiles(t, unformatted, formatted, unformatted)
+	if code := fmtCommand(append([]string{"-w"}, files...)); code != exitOK {
+		t.Fatalf("fmt -w exited with %d, want %d", code, exitOK)
+	}
+	for _, file := range files {
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(content) != formatted {
+			t.Errorf("fmt -w left %s as %q, want %q", file, content, formatted)
+		}
+	}
+}
+
+func TestFmtUsage(t *testing.T) {
+	files := writeFiles(t, formatted, formatted)
+	for _, args := range [][]string{
+		files, // the output of several files would run together
+		{"-w", "-o", files[0] + ".out", files[0]},
+		{"-w", "--check", files[0]},
+		{"-w", "--diff", files[0]},
+	} {
+		if code := fmtCommand(args); code != exitUsage {
+			t.Errorf("fmt %v exited with %d, want %d", args, code, exitUsage)
+		}
+	}
+}
+
+func TestFmtCheck(t *testing.T) {
+	files := writeFiles(t, unformatted, formatted)
+	tests := []struct {
+		files []string
+		code  int
+		out   string
+	}{
+		{files[1:], exitOK, ""},
+		{files[:1], exitFailure, files[0] + "\n"},
+		{files, exitFailure, files[0] + "\n"},
+	}
+	for _, test := range tests {
+		code, out := fmtOutput(t, []string{"--check"}, test.files)
+		if code != test.code || out != test.out {
+			t.Errorf("fmt --check %v exited with %d and wrote %q, want %d and %q", test.files, code, out, test.code, test.out)
+		}
+	}
+}
+
+func TestFmtDiff(t *testing.T) {
+	files := writeFiles(t, unformatted, formatted)
+	code, out := fmtOutput(t, []string{"--diff"}, files[1:])
+	if code != exitOK || out != "" {
+		t.Errorf("fmt --diff of a formatted file exited with %d and wrote %q", code, out)
+	}
+
+	code, out = fmtOutput(t, []string{"--diff"}, files)
+	want := strings.Join([]string{
+		"--- " + files[0],
+		"+++ " + files[0] + " (formatted)",
+		"@@ -8,5 +8,5 @@",
+		" ",
+		" main {",
+		" \tvar { }",
+		"-\ti=1;",
+		"+\ti = 1;",
+		" }",
+		"",
+	}, "\n")
+	if code != exitFailure || out != want {
+		t.Errorf("fmt --diff exited with %d and wrote\n%s\nwant %d and\n%s", code, out, exitFailure, want)
+	}
+
+	code, out = fmtOutput(t, []string{"--check", "--diff"}, files[:1])
+	if code != exitFailure || out != files[0]+"\n"+want {
+		t.Errorf("fmt --check --diff exited with %d and wrote\n%s", code, out)
+	}
+}
+
+func TestFmtErrors(t *testing.T) {
+	files := writeFiles(t, "program P;\nvar {")
+	if code := fmtCommand([]string{"--check", files[0]}); code != exitSyntax {
+		t.Errorf("fmt --check of a broken file exited with %d, want %d", code, exitSyntax)
+	}
+	if code := fmtCommand([]string{"--check", files[0] + ".missing"}); code != exitFailure {
+		t.Errorf("fmt --check of a missing file exited with %d, want %d", code, exitFailure)
+	}
+}
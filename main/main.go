@@ -2,49 +2,258 @@ package main
 
 import (
 	Compiler "compiladores/Compiler/analyzer"
+	"compiladores/Compiler/ast"
+	"flag"
+	"fmt"
+	"io"
 	"io/ioutil"
-	"log"
 	"os"
-	"path/filepath"
-	"regexp"
+	"sync"
 )
 
-func Run() {
+// Exit codes. When several sources fail, the highest code is returned.
+const (
+	exitOK       = 0
+	exitFailure  = 1 // a file could not be read or written, the build failed, a file is not formatted or a grammar is not LL(1)
+	exitUsage    = 2
+	exitLexical  = 3
+	exitSyntax   = 4
+	exitSemantic = 5 // semantic or type errors
+	exitRuntime  = 6
+)
+
+// command A subcommand of the compiler.
+type command struct {
+	name  string
+	args  string
+	short string
+	run   func(args []string) int
+}
+
+var commands []*command
+
+func init() {
+	commands = []*command{
+		{"lex", "[-o output] [-format text|json|jsonl] [-comments=false] [--stdin] files...", "print the tokens of each file", lexCommand},
+		{"parse", "[-format text|json|jsonl|sarif] [-tree] [--stdin] files...", "report lexical and syntax errors", parseCommand},
+		{"check", "[-format text|json|jsonl|sarif] [--stdin] files...", "report lexical, syntax, semantic and type errors", checkCommand},
+		{"run", "[-vm] [--stdin] file", "execute a program with the interpreter or the bytecode VM", runCommand},
+		{"build", "[-o output] [-target native|c|asm|bytecode] [--stdin] file", "compile a program to an executable, C, assembly or bytecode", buildCommand},
+		{"fmt", "[-o output] [-w] [--check] [--diff] [--stdin] files...", "print or rewrite files in canonical form, or check that they are", fmtCommand},
+		{"lsp", "[--stdio]", "serve the Language Server Protocol to an editor", lspCommand},
+		{"highlight", "[-o output]", "write a TextMate grammar for editors to highlight programs with", highlightCommand},
+		{"grammar", "[-o output] [-format text|json|go] [-package name] [--stdin] file", "analyze a GOLD grammar and generate its parse table or parser", grammarCommand},
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: compiler <command> [arguments]")
+	fmt.Fprintln(os.Stderr, "\ncommands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-9s %s\n", cmd.name, cmd.short)
+	}
+	fmt.Fprintln(os.Stderr, "\nexit status: 0 success, 1 i/o or build failure, unformatted files or grammar not LL(1), 2 usage,")
+	fmt.Fprintln(os.Stderr, "3 lexical errors, 4 syntax errors, 5 semantic or type errors, 6 runtime error")
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(exitUsage)
+	}
+	for _, cmd := range commands {
+		if cmd.name == os.Args[1] {
+			os.Exit(cmd.run(os.Args[2:]))
+		}
+	}
+	if arg := os.Args[1]; arg == "help" || arg == "-h" || arg == "-help" || arg == "--help" {
+		usage()
+		os.Exit(exitOK)
+	}
+	fmt.Fprintf(os.Stderr, "compiler: unknown command %q\n", os.Args[1])
+	usage()
+	os.Exit(exitUsage)
+}
+
+// ====================================== ARGUMENTS ======================================
+
+// source A program to be compiled.
+type source struct {
+	name    string
+	content string
+}
+
+// newFlags Flag set of a command, with the --stdin flag every command reading sources accepts.
+func newFlags(cmd string) (*flag.FlagSet, *bool) {
+	flags := commandFlags(cmd)
+	stdin := flags.Bool("stdin", false, "read the source from the standard input")
+	return flags, stdin
+}
+
+// commandFlags Empty flag set of a command, whose usage lists the arguments of the command.
+func commandFlags(cmd string) *flag.FlagSet {
+	flags := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	flags.Usage = func() {
+		for _, c := range commands {
+			if c.name == cmd {
+				fmt.Fprintf(os.Stderr, "usage: compiler %s %s\n", c.name, c.args)
+			}
+		}
+		flags.PrintDefaults()
+	}
+	return flags
+}
+
+// sources Parses the arguments of a command, whose flags may follow the files, and reads the
+// sources. A negative count accepts any number of files, otherwise exactly count are required.
+// The list is nil when the command must stop with the returned exit code.
+func sources(flags *flag.FlagSet, stdin *bool, args []string, count int) (list []source, code int) {
 	var files []string
+	for {
+		if err := flags.Parse(args); err == flag.ErrHelp {
+			return nil, exitOK
+		} else if err != nil {
+			return nil, exitUsage
+		}
+		if flags.NArg() == 0 {
+			break
+		}
+		files = append(files, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
 
-	err := filepath.Walk("./", func(path string, info os.FileInfo, err error) error {
-		files = append(files, path)
-		return nil
-	})
-	if err != nil {
-		panic(err)
+	if *stdin {
+		if len(files) > 0 {
+			fmt.Fprintln(os.Stderr, "compiler: --stdin does not take file arguments")
+			return nil, exitUsage
+		}
+		content, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "compiler:", err)
+			return nil, exitFailure
+		}
+		return []source{{"<stdin>", string(content)}}, exitOK
+	}
+	if len(files) == 0 || count > 0 && len(files) != count {
+		flags.Usage()
+		return nil, exitUsage
 	}
 	for _, file := range files {
-		matchFile, _ := regexp.MatchString("input(\\d+)?(\\.txt)", file)
-		if matchFile {
-			// busca por números na string
-			re := regexp.MustCompile(`[-]?\d[\d,]*[\.]?[\d{2}]*`)
-			fileNumbers := re.FindAllString(file, -1)
-
-			// cria um novo nome de arquivo "output" com base na string de input
-			var outputFileName = "output.txt"
-			if len(fileNumbers) >= 1 {
-				outputFileName = "output" + fileNumbers[0] + ".txt"
-			}
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "compiler:", err)
+			return nil, exitFailure
+		}
+		list = append(list, source{file, string(content)})
+	}
+	return list, exitOK
+}
 
-			// remove o arquivo de output anterior, caso exista
-			err := os.Remove(outputFileName)
-			content, err := ioutil.ReadFile(file)
-			if err != nil {
-				log.Fatal(err)
-			}
+// writeOutput Calls write with the output of a command: the file path or, when path is empty
+// or "-", the standard output.
+func writeOutput(path string, write func(w io.Writer) error) int {
+	if path == "" || path == "-" {
+		if err := write(os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, "compiler:", err)
+			return exitFailure
+		}
+		return exitOK
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "compiler:", err)
+		return exitFailure
+	}
+	err = write(f)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "compiler:", err)
+		return exitFailure
+	}
+	return exitOK
+}
+
+// ====================================== ANALYSIS ======================================
 
-			Compiler.Lex("", outputFileName, string(content))
+// unit Result of the analysis of a single source.
+type unit struct {
+	source
+	program *ast.Program
+	tree    *Compiler.SyntaxNode // concrete syntax tree, trivia included
+	info    *Compiler.Info       // nil unless the program is free of lexical and syntax errors
+	errors  []Compiler.Diagnostic
+	code    int // exit code of the first phase that failed
+}
+
+// analyze Runs the lexical and syntax analysis and, when check is set, the semantic and type
+// analysis of a source. Each phase runs only when the previous one succeeded.
+func analyze(src source, check bool) *unit {
+	u := &unit{source: src}
+	parser := Compiler.Syntax(Compiler.Lex(src.name, src.content))
+	u.program = parser.Parse()
+	u.tree = parser.Tree()
+	u.errors = append(u.errors, parser.LexicalErrors()...)
+	if Compiler.HasErrors(u.errors) { // malformed tokens always cause syntax errors as well
+		u.code = exitLexical
+		return u
+	}
+	u.errors = append(u.errors, parser.Errors()...)
+	if Compiler.HasErrors(u.errors) { // the tree is incomplete, semantic errors would be bogus
+		u.code = exitSyntax
+		return u
+	}
+	if !check {
+		return u
+	}
+
+	analyzer := Compiler.Semantic(u.program)
+	u.info = analyzer.Analyze()
+	u.errors = append(u.errors, analyzer.Errors()...)
+
+	checker := Compiler.TypeCheck(u.program, u.info)
+	checker.Check()
+	u.errors = append(u.errors, checker.Errors()...)
+	if Compiler.HasErrors(u.errors) {
+		u.code = exitSemantic
+	}
+	return u
+}
+
+// analyzeAll Analyzes the sources concurrently, each owns its lexer and parser.
+func analyzeAll(list []source, check bool) []*unit {
+	units := make([]*unit, len(list))
+	var wg sync.WaitGroup
+	for i := range list {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			units[i] = analyze(list[i], check)
+		}(i)
+	}
+	wg.Wait()
+	return units
+}
+
+// report Writes the errors of every unit, in order, with the source line they point at, and
+// returns the exit code.
+func report(units []*unit) int {
+	for _, u := range units {
+		for _, d := range u.errors {
+			d.Render(os.Stderr, u.content)
 		}
 	}
+	return exitCode(units)
 }
 
-func main() {
-	Run()
-	//Compiler.Syntax()
+// exitCode The highest exit code of the units.
+func exitCode(units []*unit) int {
+	code := exitOK
+	for _, u := range units {
+		if u.code > code {
+			code = u.code
+		}
+	}
+	return code
 }
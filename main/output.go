@@ -0,0 +1,158 @@
+package main
+
+import (
+	Compiler "compiladores/Compiler/analyzer"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// Formats of the results of the lex, parse, check and grammar commands.
+const (
+	formatText  = "text"  // token table and rendered diagnostics
+	formatJSON  = "json"  // a single array with an object per source
+	formatJSONL = "jsonl" // JSON Lines, a record per token and per diagnostic
+	formatSARIF = "sarif" // a SARIF 2.1.0 log of the diagnostics
+	formatGo    = "go"    // a recursive-descent parser of a grammar
+)
+
+var (
+	tokenFormats      = []string{formatText, formatJSON, formatJSONL}
+	diagnosticFormats = []string{formatText, formatJSON, formatJSONL, formatSARIF}
+	grammarFormats    = []string{formatText, formatJSON, formatGo}
+)
+
+// formatFlag Adds the -format flag, accepting one of formats, to the flag set of a command.
+func formatFlag(flags *flag.FlagSet, formats []string) *string {
+	list := strings.Join(formats[:len(formats)-1], ", ") + " or " + formats[len(formats)-1]
+	return flags.String("format", formatText, "`format` of the results: "+list)
+}
+
+// checkFormat Reports a format not in formats, returning the exit code to stop with.
+func checkFormat(format string, formats []string) int {
+	for _, f := range formats {
+		if f == format {
+			return exitOK
+		}
+	}
+	fmt.Fprintf(os.Stderr, "compiler: unknown format %q\n", format)
+	return exitUsage
+}
+
+// fileTokens JSON object of a source in the output of lex.
+type fileTokens struct {
+	File        string                `json:"file"`
+	Tokens      []Compiler.Token      `json:"tokens"`
+	Diagnostics []Compiler.Diagnostic `json:"diagnostics"`
+}
+
+// fileDiagnostics JSON object of a source in the output of parse and check.
+type fileDiagnostics struct {
+	File        string                `json:"file"`
+	Diagnostics []Compiler.Diagnostic `json:"diagnostics"`
+}
+
+// tokenRecord JSON Lines record of a token.
+type tokenRecord struct {
+	Kind string `json:"kind"` // always "token"
+	File string `json:"file"`
+	Compiler.Token
+}
+
+// diagnosticRecord JSON Lines record of a diagnostic.
+type diagnosticRecord struct {
+	Kind string `json:"kind"` // always "diagnostic"
+	Compiler.Diagnostic
+}
+
+// writeTokens Writes the tokens and the lexical errors of each source in the given format,
+// which must not be text. tokens holds the tokens of each unit.
+func writeTokens(w io.Writer, format string, units []*unit, tokens [][]Compiler.Token) error {
+	if format == formatJSONL {
+		e := newEncoder(w)
+		for i, u := range units {
+			for _, t := range tokens[i] {
+				if err := e.Encode(tokenRecord{"token", u.name, t}); err != nil {
+					return err
+				}
+			}
+			if err := writeRecords(e, u.errors); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	list := make([]fileTokens, len(units))
+	for i, u := range units {
+		list[i] = fileTokens{u.name, nonNil(tokens[i]), diagnostics(u)}
+	}
+	return writeIndented(w, list)
+}
+
+// writeDiagnostics Writes the errors of each source in the given format, which must not be
+// text, and returns the exit code.
+func writeDiagnostics(w io.Writer, format string, units []*unit) int {
+	var err error
+	if format == formatSARIF {
+		err = writeSARIF(w, units)
+	} else if format == formatJSONL {
+		e := newEncoder(w)
+		for _, u := range units {
+			if err = writeRecords(e, u.errors); err != nil {
+				break
+			}
+		}
+	} else {
+		list := make([]fileDiagnostics, len(units))
+		for i, u := range units {
+			list[i] = fileDiagnostics{u.name, diagnostics(u)}
+		}
+		err = writeIndented(w, list)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "compiler:", err)
+		return exitFailure
+	}
+	return exitCode(units)
+}
+
+func writeRecords(e *json.Encoder, list []Compiler.Diagnostic) error {
+	for _, d := range list {
+		if err := e.Encode(diagnosticRecord{"diagnostic", d}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// newEncoder JSON encoder leaving <, > and & in strings as they are, as lexemes often contain them.
+func newEncoder(w io.Writer) *json.Encoder {
+	e := json.NewEncoder(w)
+	e.SetEscapeHTML(false)
+	return e
+}
+
+func writeIndented(w io.Writer, v interface{}) error {
+	e := newEncoder(w)
+	e.SetIndent("", "  ")
+	return e.Encode(v)
+}
+
+// diagnostics The errors of a unit, as an empty array rather than null when there are none.
+func diagnostics(u *unit) []Compiler.Diagnostic {
+	if u.errors == nil {
+		return []Compiler.Diagnostic{}
+	}
+	return u.errors
+}
+
+func nonNil(tokens []Compiler.Token) []Compiler.Token {
+	if tokens == nil {
+		return []Compiler.Token{}
+	}
+	return tokens
+}
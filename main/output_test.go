@@ -0,0 +1,220 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	Compiler "compiladores/Compiler/analyzer"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+// The sources of the output tests: one with a type error and one with a lexical error after a
+// non-ASCII character, whose column counts characters and whose span counts bytes. The lexical
+// error stops the analysis, the syntax error it causes is not reported.
+const (
+	typeError    = "program P;\nvar { integer i; }\nconst { }\nmain {\n\tvar { }\n\ti = \"x\";\n}\n"
+	lexicalError = "program Pé;\nvar { }\nconst { }\nmain {\n\tvar { }\n\tprint(\"é\" @);\n}\n"
+)
+
+// diagnostic Diagnostic as the JSON output spells it.
+type diagnostic struct {
+	Kind     string `json:"kind"`
+	Severity string `json:"severity"`
+	Code     string `json:"code"`
+	Message  string `json:"message"`
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Span     struct {
+		Start int `json:"start"`
+		End   int `json:"end"`
+	} `json:"span"`
+}
+
+// outputUnits Analyzes the sources of the output tests.
+func outputUnits() []*unit {
+	return analyzeAll([]source{{"a.txt", typeError}, {"b.txt", lexicalError}}, true)
+}
+
+// checkDiagnostic Checks the fields of a decoded diagnostic against the first one of code found at
+// text in src.
+func checkDiagnostic(t *testing.T, d diagnostic, file, src, code, text string, line, column int) {
+	t.Helper()
+	start := strings.Index(src, text)
+	if d.File != file || d.Severity != "error" || d.Code != code || d.Message == "" ||
+		d.Line != line || d.Column != column || d.Span.Start != start || d.Span.End != start+len(text) {
+		t.Errorf("got %+v, want error[%s] in %s at %d:%d, bytes %d-%d", d, code, file, line, column, start, start+len(text))
+	}
+}
+
+// lines Calls decode with each line of a JSON Lines output.
+func lines(t *testing.T, out []byte, decode func(line []byte) error) {
+	t.Helper()
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		if err := decode(scanner.Bytes()); err != nil {
+			t.Fatalf("line %q: %v", scanner.Text(), err)
+		}
+	}
+}
+
+func TestDiagnosticsJSON(t *testing.T) {
+	var out bytes.Buffer
+	if code := writeDiagnostics(&out, formatJSON, outputUnits()); code != exitSemantic {
+		t.Errorf("exit code %d, want %d", code, exitSemantic)
+	}
+	var files []struct {
+		File        string       `json:"file"`
+		Diagnostics []diagnostic `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &files); err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 2 || files[0].File != "a.txt" || files[1].File != "b.txt" ||
+		len(files[0].Diagnostics) != 1 || len(files[1].Diagnostics) != 1 {
+		t.Fatalf("got %+v, want one error in each file", files)
+	}
+	checkDiagnostic(t, files[0].Diagnostics[0], "a.txt", typeError, Compiler.CodeTypeMismatch, "\"x\"", 6, 6)
+	checkDiagnostic(t, files[1].Diagnostics[0], "b.txt", lexicalError, Compiler.CodeInvalidChar, "@", 6, 12)
+}
+
+func TestDiagnosticsJSONNoErrors(t *testing.T) {
+	var out bytes.Buffer
+	units := analyzeAll([]source{{"ok.txt", "program P;\nvar { }\nconst { }\nmain {\n\tvar { }\n}\n"}}, true)
+	if code := writeDiagnostics(&out, formatJSON, units); code != exitOK {
+		t.Errorf("exit code %d, want %d", code, exitOK)
+	}
+	if want := "[\n  {\n    \"file\": \"ok.txt\",\n    \"diagnostics\": []\n  }\n]\n"; out.String() != want {
+		t.Errorf("wrote %q, want %q", out.String(), want)
+	}
+}
+
+func TestDiagnosticsJSONL(t *testing.T) {
+	var out bytes.Buffer
+	if code := writeDiagnostics(&out, formatJSONL, outputUnits()); code != exitSemantic {
+		t.Errorf("exit code %d, want %d", code, exitSemantic)
+	}
+	var records []diagnostic
+	lines(t, out.Bytes(), func(line []byte) error {
+		var d diagnostic
+		err := json.Unmarshal(line, &d)
+		records = append(records, d)
+		return err
+	})
+	if len(records) != 2 {
+		t.Fatalf("got %d records, want 2", len(records))
+	}
+	for _, d := range records {
+		if d.Kind != "diagnostic" {
+			t.Errorf("record %+v is not of kind diagnostic", d)
+		}
+	}
+	checkDiagnostic(t, records[0], "a.txt", typeError, Compiler.CodeTypeMismatch, "\"x\"", 6, 6)
+	checkDiagnostic(t, records[1], "b.txt", lexicalError, Compiler.CodeInvalidChar, "@", 6, 12)
+}
+
+// token Token as the JSON output spells it.
+type token struct {
+	Kind    string      `json:"kind"`
+	File    string      `json:"file"`
+	Type    string      `json:"type"`
+	Value   string      `json:"value"`
+	Line    int         `json:"line"`
+	Column  int         `json:"column"`
+	Offset  int         `json:"offset"`
+	Literal interface{} `json:"literal"`
+}
+
+// lexUnits Tokens of a source, as the lex command lists them.
+func lexUnits(name, src string) ([]*unit, [][]Compiler.Token) {
+	u := &unit{source: source{name, src}}
+	tokens, errs := Compiler.Tokens(Compiler.Lex(name, src))
+	u.errors = errs
+	return []*unit{u}, [][]Compiler.Token{tokens}
+}
+
+const lexSource = "x = 'é' <= 12 @ % é\n"
+
+// wantTokens The tokens of lexSource.
+var wantTokens = []token{
+	{Type: "tokenIdentifier", Value: "x", Line: 1, Column: 1, Offset: 0},
+	{Type: "tokenAssign", Value: "=", Line: 1, Column: 3, Offset: 2},
+	{Type: "tokenChar", Value: "'é'", Line: 1, Column: 5, Offset: 4, Literal: "é"},
+	{Type: "tokenLessEqual", Value: "<=", Line: 1, Column: 9, Offset: 9},
+	{Type: "tokenNumber", Value: "12", Line: 1, Column: 12, Offset: 12, Literal: 12.0},
+	{Type: "tokenInvalidChar", Value: "@", Line: 1, Column: 15, Offset: 15},
+	{Type: "tokenLineComment", Value: "% é", Line: 1, Column: 17, Offset: 17},
+	{Type: "tokenEOF", Value: "", Line: 2, Column: 1, Offset: 22},
+}
+
+// checkTokens Checks decoded tokens, which have neither kind nor file, against wantTokens.
+func checkTokens(t *testing.T, got []token) {
+	t.Helper()
+	if len(got) != len(wantTokens) {
+		t.Fatalf("got %d tokens %+v, want %d", len(got), got, len(wantTokens))
+	}
+	for i, want := range wantTokens {
+		if got[i] != want {
+			t.Errorf("token %d is %+v, want %+v", i, got[i], want)
+		}
+	}
+}
+
+func TestTokensJSON(t *testing.T) {
+	var out bytes.Buffer
+	units, tokens := lexUnits("a.txt", lexSource)
+	if err := writeTokens(&out, formatJSON, units, tokens); err != nil {
+		t.Fatal(err)
+	}
+	var files []struct {
+		File        string       `json:"file"`
+		Tokens      []token      `json:"tokens"`
+		Diagnostics []diagnostic `json:"diagnostics"`
+	}
+	if err := json.Unmarshal(out.Bytes(), &files); err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].File != "a.txt" || len(files[0].Diagnostics) != 1 {
+		t.Fatalf("got %+v, want a.txt with one diagnostic", files)
+	}
+	checkTokens(t, files[0].Tokens)
+	checkDiagnostic(t, files[0].Diagnostics[0], "a.txt", lexSource, Compiler.CodeInvalidChar, "@", 1, 15)
+}
+
+func TestTokensJSONL(t *testing.T) {
+	var out bytes.Buffer
+	units, tokens := lexUnits("a.txt", lexSource)
+	if err := writeTokens(&out, formatJSONL, units, tokens); err != nil {
+		t.Fatal(err)
+	}
+	var got []token
+	var diagnostics []diagnostic
+	lines(t, out.Bytes(), func(line []byte) error {
+		var record struct {
+			Kind string `json:"kind"`
+		}
+		if err := json.Unmarshal(line, &record); err != nil {
+			return err
+		}
+		if record.Kind == "diagnostic" {
+			var d diagnostic
+			err := json.Unmarshal(line, &d)
+			diagnostics = append(diagnostics, d)
+			return err
+		}
+		var tok token
+		err := json.Unmarshal(line, &tok)
+		if tok.Kind != "token" || tok.File != "a.txt" {
+			t.Errorf("record %s is not a token of a.txt", line)
+		}
+		tok.Kind, tok.File = "", ""
+		got = append(got, tok)
+		return err
+	})
+	checkTokens(t, got)
+	if len(diagnostics) != 1 {
+		t.Fatalf("got diagnostics %+v, want 1", diagnostics)
+	}
+	checkDiagnostic(t, diagnostics[0], "a.txt", lexSource, Compiler.CodeInvalidChar, "@", 1, 15)
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	Compiler "compiladores/Compiler/analyzer"
+	"io"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// The subset of SARIF 2.1.0 (https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html)
+// written by the parse and check commands.
+
+const (
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	sarifVersion = "2.1.0"
+)
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool       sarifTool       `json:"tool"`
+	Artifacts  []sarifArtifact `json:"artifacts"`
+	Results    []sarifResult   `json:"results"`
+	ColumnKind string          `json:"columnKind"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name  string      `json:"name"`
+	Rules []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	Help                 sarifMessage       `json:"help"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifArtifact struct {
+	Location sarifArtifactLocation `json:"location"`
+}
+
+type sarifArtifactLocation struct {
+	URI   string `json:"uri"`
+	Index int    `json:"index"`
+}
+
+type sarifResult struct {
+	RuleID    string          `json:"ruleId"`
+	RuleIndex int             `json:"ruleIndex"`
+	Level     string          `json:"level"`
+	Message   sarifMessage    `json:"message"`
+	Locations []sarifLocation `json:"locations"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           sarifRegion           `json:"region"`
+}
+
+// sarifRegion Lines and columns start at 1, columns count characters and the end column is the
+// one after the last character.
+type sarifRegion struct {
+	StartLine   int          `json:"startLine"`
+	StartColumn int          `json:"startColumn"`
+	EndLine     int          `json:"endLine"`
+	EndColumn   int          `json:"endColumn"`
+	ByteOffset  int          `json:"byteOffset"`
+	ByteLength  int          `json:"byteLength"`
+	Snippet     sarifMessage `json:"snippet"`
+}
+
+// sarifLevels SARIF level of each severity.
+var sarifLevels = map[Compiler.Severity]string{
+	Compiler.SeverityError:   "error",
+	Compiler.SeverityWarning: "warning",
+	Compiler.SeverityInfo:    "note",
+}
+
+// writeSARIF Writes the errors of the units as a SARIF log with a single run, whose rules are
+// the kinds of problem the compiler reports.
+func writeSARIF(w io.Writer, units []*unit) error {
+	run := sarifRun{
+		Tool:       sarifTool{sarifDriver{Name: "compiler"}},
+		Artifacts:  []sarifArtifact{},
+		Results:    []sarifResult{},
+		ColumnKind: "unicodeCodePoints",
+	}
+	rules := map[string]int{}
+	for i, rule := range Compiler.Rules {
+		rules[rule.Code] = i
+		run.Tool.Driver.Rules = append(run.Tool.Driver.Rules, sarifRule{
+			ID:                   rule.Code,
+			ShortDescription:     sarifMessage{rule.Description},
+			Help:                 sarifMessage{rule.Help},
+			DefaultConfiguration: sarifConfiguration{sarifLevels[rule.Severity]},
+		})
+	}
+
+	for i, u := range units {
+		artifact := sarifArtifactLocation{sarifURI(u.name), i}
+		run.Artifacts = append(run.Artifacts, sarifArtifact{artifact})
+		for _, d := range u.errors {
+			run.Results = append(run.Results, sarifResult{
+				RuleID:    d.Code,
+				RuleIndex: rules[d.Code],
+				Level:     sarifLevels[d.Severity],
+				Message:   sarifMessage{d.Message},
+				Locations: []sarifLocation{{sarifPhysicalLocation{artifact, region(d, u.content)}}},
+			})
+		}
+	}
+	return writeIndented(w, sarifLog{sarifSchema, sarifVersion, []sarifRun{run}})
+}
+
+// sarifURI Relative reference of a file: a path relative to the working directory or a file URI.
+func sarifURI(name string) string {
+	u := &url.URL{Path: filepath.ToSlash(name)}
+	if filepath.IsAbs(name) {
+		u.Scheme = "file"
+		if !strings.HasPrefix(u.Path, "/") { // a drive letter
+			u.Path = "/" + u.Path
+		}
+	}
+	return u.String()
+}
+
+// region Region of src covered by the span of a diagnostic.
+func region(d Compiler.Diagnostic, src string) sarifRegion {
+	start, end := d.Span.Start, d.Span.End
+	if start > len(src) {
+		start = len(src)
+	}
+	if end > len(src) {
+		end = len(src)
+	}
+	if end < start {
+		end = start
+	}
+	r := sarifRegion{
+		StartLine:   d.Line,
+		StartColumn: d.Column,
+		EndLine:     d.Line,
+		EndColumn:   d.Column,
+		ByteOffset:  start,
+		ByteLength:  end - start,
+		Snippet:     sarifMessage{src[start:end]},
+	}
+	text := src[start:end]
+	if i := strings.LastIndexByte(text, '\n'); i >= 0 {
+		r.EndLine += strings.Count(text, "\n")
+		r.EndColumn = 1 + utf8.RuneCountInString(text[i+1:])
+	} else {
+		r.EndColumn += utf8.RuneCountInString(text)
+	}
+	return r
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"bytes"
+	Compiler "compiladores/Compiler/analyzer"
+	"encoding/json"
+	"testing"
+)
+
+// unterminated Source with an unterminated comment, whose region spans several lines.
+const unterminated = "program P;\n/# é\nab"
+
+// sarifOutput SARIF log as the specification spells it.
+type sarifOutput struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []struct {
+		Tool struct {
+			Driver struct {
+				Name  string `json:"name"`
+				Rules []struct {
+					ID               string `json:"id"`
+					ShortDescription struct {
+						Text string `json:"text"`
+					} `json:"shortDescription"`
+					Help struct {
+						Text string `json:"text"`
+					} `json:"help"`
+					DefaultConfiguration struct {
+						Level string `json:"level"`
+					} `json:"defaultConfiguration"`
+				} `json:"rules"`
+			} `json:"driver"`
+		} `json:"tool"`
+		Artifacts []struct {
+			Location struct {
+				URI   string `json:"uri"`
+				Index int    `json:"index"`
+			} `json:"location"`
+		} `json:"artifacts"`
+		Results []struct {
+			RuleID    string `json:"ruleId"`
+			RuleIndex int    `json:"ruleIndex"`
+			Level     string `json:"level"`
+			Message   struct {
+				Text string `json:"text"`
+			} `json:"message"`
+			Locations []struct {
+				PhysicalLocation struct {
+					ArtifactLocation struct {
+						URI   string `json:"uri"`
+						Index int    `json:"index"`
+					} `json:"artifactLocation"`
+					Region sarifRegion `json:"region"`
+				} `json:"physicalLocation"`
+			} `json:"locations"`
+		} `json:"results"`
+		ColumnKind string `json:"columnKind"`
+	} `json:"runs"`
+}
+
+func TestSARIF(t *testing.T) {
+	var out bytes.Buffer
+	units := analyzeAll([]source{{"a.txt", typeError}, {"dir/b é.txt", lexicalError}, {"c.txt", unterminated}}, true)
+	if code := writeDiagnostics(&out, formatSARIF, units); code != exitSemantic {
+		t.Errorf("exit code %d, want %d", code, exitSemantic)
+	}
+	var l sarifOutput
+	if err := json.Unmarshal(out.Bytes(), &l); err != nil {
+		t.Fatal(err)
+	}
+	if l.Schema != "https://json.schemastore.org/sarif-2.1.0.json" || l.Version != "2.1.0" || len(l.Runs) != 1 {
+		t.Fatalf("got $schema %q, version %q and %d runs, want SARIF 2.1.0 with a run", l.Schema, l.Version, len(l.Runs))
+	}
+	run := l.Runs[0]
+	if run.Tool.Driver.Name != "compiler" || run.ColumnKind != "unicodeCodePoints" {
+		t.Errorf("got tool %q and column kind %q", run.Tool.Driver.Name, run.ColumnKind)
+	}
+
+	rules := run.Tool.Driver.Rules
+	if len(rules) != len(Compiler.Rules) {
+		t.Fatalf("got %d rules, want %d", len(rules), len(Compiler.Rules))
+	}
+	levels := map[Compiler.Severity]string{Compiler.SeverityError: "error", Compiler.SeverityWarning: "warning", Compiler.SeverityInfo: "note"}
+	for i, rule := range Compiler.Rules {
+		got := rules[i]
+		if got.ID != rule.Code || got.DefaultConfiguration.Level != levels[rule.Severity] ||
+			got.ShortDescription.Text == "" || got.Help.Text == "" {
+			t.Errorf("rule %d is %+v, want %s of level %s", i, got, rule.Code, levels[rule.Severity])
+		}
+	}
+
+	for i, uri := range []string{"a.txt", "dir/b%20%C3%A9.txt", "c.txt"} {
+		if a := run.Artifacts[i].Location; a.URI != uri || a.Index != i {
+			t.Errorf("artifact %d is %+v, want %s", i, a, uri)
+		}
+	}
+
+	want := []struct {
+		code     string
+		artifact int
+		region   sarifRegion
+	}{
+		{Compiler.CodeTypeMismatch, 0, sarifRegion{6, 6, 6, 9, 61, 3, sarifMessage{`"x"`}}},
+		{Compiler.CodeInvalidChar, 1, sarifRegion{6, 12, 6, 13, 59, 1, sarifMessage{"@"}}},
+		{Compiler.CodeMalformedComment, 2, sarifRegion{2, 1, 3, 3, 11, 8, sarifMessage{"/# é\nab"}}},
+	}
+	if len(run.Results) != len(want) {
+		t.Fatalf("got %d results, want %d", len(run.Results), len(want))
+	}
+	for i, w := range want {
+		r := run.Results[i]
+		if r.RuleID != w.code || rules[r.RuleIndex].ID != w.code || r.Level != "error" || r.Message.Text == "" || len(r.Locations) != 1 {
+			t.Errorf("result %d is %+v, want %s", i, r, w.code)
+			continue
+		}
+		location := r.Locations[0].PhysicalLocation
+		if location.ArtifactLocation.Index != w.artifact || location.ArtifactLocation.URI != run.Artifacts[w.artifact].Location.URI {
+			t.Errorf("result %d is in %+v, want artifact %d", i, location.ArtifactLocation, w.artifact)
+		}
+		if location.Region != w.region {
+			t.Errorf("result %d has region %+v, want %+v", i, location.Region, w.region)
+		}
+	}
+}
+
+func TestSARIFURI(t *testing.T) {
+	tests := []struct {
+		name, uri string
+	}{
+		{"a.txt", "a.txt"},
+		{"dir/a b.txt", "dir/a%20b.txt"},
+		{"/home/x/a#1.txt", "file:///home/x/a%231.txt"},
+	}
+	for _, test := range tests {
+		if uri := sarifURI(test.name); uri != test.uri {
+			t.Errorf("sarifURI(%q) = %q, want %q", test.name, uri, test.uri)
+		}
+	}
+}
@@ -0,0 +1,381 @@
+package vm
+
+import (
+	Compiler "compiladores/Compiler/analyzer"
+	"compiladores/Compiler/ast"
+	"math"
+)
+
+// compiler Holds the state of the translation of a single program.
+type compiler struct {
+	info    *Compiler.Info
+	prog    *Program
+	fn      *Function
+	line    int
+	locals  map[*Compiler.Symbol]int32
+	globals map[*Compiler.Symbol]int32
+	funcs   map[*Compiler.Symbol]int32
+	records map[*Compiler.Symbol]int32
+	fields  map[*Compiler.Symbol]int32
+	strs    map[string]int32
+	reals   map[float64]int32
+}
+
+// Compile Translates a program free of syntax, semantic and type errors to bytecode.
+func Compile(program *ast.Program, info *Compiler.Info) *Program {
+	c := &compiler{
+		info:    info,
+		prog:    &Program{Name: program.Name.Name},
+		globals: make(map[*Compiler.Symbol]int32),
+		funcs:   make(map[*Compiler.Symbol]int32),
+		records: make(map[*Compiler.Symbol]int32),
+		fields:  make(map[*Compiler.Symbol]int32),
+		strs:    make(map[string]int32),
+		reals:   make(map[float64]int32),
+	}
+
+	for _, r := range program.Registers {
+		sym := info.Defs[r.Name]
+		if sym == nil {
+			continue
+		}
+		rt := &RecordType{Name: sym.Name}
+		for i, field := range sym.Members.Symbols {
+			c.fields[field] = int32(i)
+			rt.Fields = append(rt.Fields, field.Name)
+		}
+		c.records[sym] = int32(len(c.prog.Records))
+		c.prog.Records = append(c.prog.Records, rt)
+	}
+
+	for _, sym := range info.Global.Symbols {
+		switch sym.Kind {
+		case Compiler.VarSymbol, Compiler.ConstSymbol:
+			c.globals[sym] = int32(len(c.prog.Globals))
+			c.prog.Globals = append(c.prog.Globals, sym.Name)
+		case Compiler.ProcedureSymbol, Compiler.FunctionSymbol:
+			c.funcs[sym] = int32(len(c.prog.Functions))
+			c.prog.Functions = append(c.prog.Functions, &Function{Name: sym.Name})
+		}
+	}
+
+	for _, proc := range program.Procedures {
+		if sym := info.Defs[proc.Name]; sym != nil {
+			c.function(c.prog.Functions[c.funcs[sym]], proc.Params, proc.Body)
+			c.emit(RET, 0)
+		}
+	}
+	for _, fn := range program.Functions {
+		if sym := info.Defs[fn.Name]; sym != nil {
+			c.function(c.prog.Functions[c.funcs[sym]], fn.Params, fn.Body)
+			c.line = fn.Return.Pos().Line
+			c.value(fn.Return.Value, info.TypeRefs[fn.Result])
+			c.emit(RETV, 0)
+		}
+	}
+
+	main := &Function{Name: "main"}
+	c.prog.Main = len(c.prog.Functions)
+	c.prog.Functions = append(c.prog.Functions, main)
+	c.fn = main
+	c.locals = make(map[*Compiler.Symbol]int32)
+	c.line = program.Pos().Line
+	for _, decl := range program.Vars {
+		for _, name := range decl.Names {
+			if sym := info.Defs[name]; sym != nil {
+				c.zero(info.TypeRefs[decl.Type], STOREG, c.globals[sym])
+			}
+		}
+	}
+	for _, decl := range program.Consts {
+		for _, spec := range decl.Specs {
+			if sym := info.Defs[spec.Name]; sym != nil {
+				c.line = spec.Pos().Line
+				c.value(spec.Value, info.TypeRefs[decl.Type])
+				c.emit(STOREG, c.globals[sym])
+			}
+		}
+	}
+	if program.Main != nil {
+		c.block(program.Main.Body)
+	}
+	c.emit(HALT, 0)
+	return c.prog
+}
+
+func (c *compiler) emit(op Opcode, a int32) int {
+	c.fn.Code = append(c.fn.Code, Instr{op, a})
+	c.fn.Lines = append(c.fn.Lines, c.line)
+	return len(c.fn.Code) - 1
+}
+
+// patch Sets the target of the jump at pc to the next instruction.
+func (c *compiler) patch(pc int) {
+	c.fn.Code[pc].A = int32(len(c.fn.Code))
+}
+
+func (c *compiler) function(fn *Function, params []*ast.Param, body *ast.Block) {
+	c.fn = fn
+	c.locals = make(map[*Compiler.Symbol]int32)
+	c.line = body.Pos().Line
+	for _, param := range params {
+		c.local(c.info.Defs[param.Name])
+	}
+	fn.NumParams = len(c.locals)
+	c.block(body)
+}
+
+func (c *compiler) local(sym *Compiler.Symbol) int32 {
+	slot := int32(c.fn.NumLocals)
+	c.locals[sym] = slot
+	c.fn.NumLocals++
+	return slot
+}
+
+func (c *compiler) block(block *ast.Block) {
+	for _, decl := range block.Vars {
+		for _, name := range decl.Names {
+			if sym := c.info.Defs[name]; sym != nil {
+				c.zero(c.info.TypeRefs[decl.Type], STOREL, c.local(sym))
+			}
+		}
+	}
+	c.stmts(block.Stmts)
+}
+
+// zero Primitive slots start zeroed, registers must be allocated.
+func (c *compiler) zero(t *Compiler.Type, store Opcode, slot int32) {
+	if t.Kind == Compiler.RegisterType {
+		c.emit(NEWREC, c.records[t.Register])
+		c.emit(store, slot)
+	}
+}
+
+// ====================================== COMMANDS ======================================
+
+func (c *compiler) stmts(list []ast.Stmt) {
+	for _, s := range list {
+		c.line = s.Pos().Line
+		c.stmt(s)
+	}
+}
+
+func (c *compiler) stmt(s ast.Stmt) {
+	switch s := s.(type) {
+	case *ast.AssignStmt:
+		c.store(s.Target, func(t *Compiler.Type) { c.value(s.Value, t) })
+	case *ast.IncDecStmt:
+		c.store(s.X, func(*Compiler.Type) { c.incDec(s.X, s.Op) })
+	case *ast.CallStmt:
+		c.call(s.Call)
+	case *ast.IfStmt:
+		c.expr(s.Cond)
+		jfalse := c.emit(JFALSE, 0)
+		c.stmts(s.Then.List)
+		if s.Else != nil {
+			jmp := c.emit(JMP, 0)
+			c.patch(jfalse)
+			c.stmts(s.Else.List)
+			c.patch(jmp)
+		} else {
+			c.patch(jfalse)
+		}
+	case *ast.WhileStmt:
+		top := len(c.fn.Code)
+		c.expr(s.Cond)
+		jfalse := c.emit(JFALSE, 0)
+		c.stmts(s.Body.List)
+		c.emit(JMP, int32(top))
+		c.patch(jfalse)
+	case *ast.PrintStmt:
+		for _, x := range s.Args {
+			c.expr(x)
+			c.emit(PRINT, int32(kindOf(c.info.TypeOf(x))))
+		}
+		c.emit(PRINTLN, 0)
+	case *ast.ReadStmt:
+		for _, x := range s.Args {
+			c.store(x, func(t *Compiler.Type) { c.emit(READ, int32(kindOf(t))) })
+		}
+	case *ast.BlockStmt:
+		c.stmts(s.List)
+	}
+}
+
+// incDec Leaves x±1 on the stack.
+func (c *compiler) incDec(x ast.Expr, op string) {
+	c.expr(x)
+	c.emit(PUSHI, 1)
+	if op == "++" {
+		c.emit(ADDI, 0)
+	} else {
+		c.emit(SUBI, 0)
+	}
+}
+
+// store Stores the value pushed by value (given the type of the target) into target.
+func (c *compiler) store(target ast.Expr, value func(t *Compiler.Type)) {
+	t := c.info.TypeOf(target)
+	switch x := target.(type) {
+	case *ast.Ident:
+		value(t)
+		sym := c.info.Uses[x]
+		if slot, ok := c.locals[sym]; ok {
+			c.emit(STOREL, slot)
+		} else {
+			c.emit(STOREG, c.globals[sym])
+		}
+	case *ast.FieldExpr:
+		c.expr(x.X)
+		value(t)
+		c.emit(SETF, c.fields[c.info.Uses[x.Field]])
+	}
+}
+
+// ====================================== EXPRESSIONS ======================================
+
+// value Pushes x converted to a location of type t.
+func (c *compiler) value(x ast.Expr, t *Compiler.Type) {
+	c.expr(x)
+	switch u := c.info.TypeOf(x); {
+	case u.Kind == Compiler.IntegerType && t.Kind == Compiler.RealType:
+		c.emit(ITOF, 0)
+	case u.Kind == Compiler.RegisterType:
+		c.emit(COPYREC, 0)
+	}
+}
+
+func (c *compiler) expr(x ast.Expr) {
+	switch x := x.(type) {
+	case *ast.BasicLit:
+		c.literal(x)
+	case *ast.Ident:
+		sym := c.info.Uses[x]
+		if slot, ok := c.locals[sym]; ok {
+			c.emit(LOADL, slot)
+		} else {
+			c.emit(LOADG, c.globals[sym])
+		}
+	case *ast.FieldExpr:
+		c.expr(x.X)
+		c.emit(GETF, c.fields[c.info.Uses[x.Field]])
+	case *ast.CallExpr:
+		c.call(x)
+	case *ast.UnaryExpr:
+		c.expr(x.X)
+		c.emit(NOT, 0)
+	case *ast.IncDecExpr:
+		c.expr(x.X) // the previous value is the result
+		c.store(x.X, func(*Compiler.Type) { c.incDec(x.X, x.Op) })
+	case *ast.BinaryExpr:
+		c.binary(x)
+	}
+}
+
+func (c *compiler) literal(lit *ast.BasicLit) {
+	switch v := lit.Literal.(type) {
+	case int64:
+		if v >= math.MinInt32 && v <= math.MaxInt32 {
+			c.emit(PUSHI, int32(v))
+		} else {
+			c.emit(CONST, c.constant(Value{I: v}, KindInteger))
+		}
+	case float64:
+		idx, ok := c.reals[v]
+		if !ok {
+			idx = c.constant(Value{F: v}, KindReal)
+			c.reals[v] = idx
+		}
+		c.emit(CONST, idx)
+	case string:
+		idx, ok := c.strs[v]
+		if !ok {
+			idx = c.constant(Value{S: v}, KindString)
+			c.strs[v] = idx
+		}
+		c.emit(CONST, idx)
+	case rune:
+		c.emit(PUSHI, v)
+	case bool:
+		if v {
+			c.emit(PUSHI, 1)
+		} else {
+			c.emit(PUSHI, 0)
+		}
+	}
+}
+
+func (c *compiler) constant(v Value, kind Kind) int32 {
+	c.prog.Consts = append(c.prog.Consts, v)
+	c.prog.ConstKinds = append(c.prog.ConstKinds, kind)
+	return int32(len(c.prog.Consts) - 1)
+}
+
+var (
+	intOps  = map[string]Opcode{"+": ADDI, "-": SUBI, "*": MULI, "/": DIVI, "==": EQI, "!=": NEI, "<": LTI, ">": GTI, "<=": LEI, ">=": GEI}
+	realOps = map[string]Opcode{"+": ADDF, "-": SUBF, "*": MULF, "/": DIVF, "==": EQF, "!=": NEF, "<": LTF, ">": GTF, "<=": LEF, ">=": GEF}
+)
+
+func (c *compiler) binary(x *ast.BinaryExpr) {
+	switch x.Op {
+	case "&&", "||":
+		c.expr(x.X)
+		op := ANDJMP
+		if x.Op == "||" {
+			op = ORJMP
+		}
+		jmp := c.emit(op, 0)
+		c.expr(x.Y)
+		c.patch(jmp)
+		return
+	}
+
+	t, u := c.info.TypeOf(x.X), c.info.TypeOf(x.Y)
+	switch {
+	case t.Kind == Compiler.StringType:
+		c.expr(x.X)
+		c.expr(x.Y)
+		if x.Op == "==" {
+			c.emit(EQS, 0)
+		} else {
+			c.emit(NES, 0)
+		}
+	case t.Kind == Compiler.RealType || u.Kind == Compiler.RealType:
+		c.value(x.X, Compiler.Typ[Compiler.RealType])
+		c.value(x.Y, Compiler.Typ[Compiler.RealType])
+		c.emit(realOps[x.Op], 0)
+	default:
+		c.expr(x.X)
+		c.expr(x.Y)
+		c.emit(intOps[x.Op], 0)
+	}
+}
+
+func (c *compiler) call(call *ast.CallExpr) {
+	sym := c.info.Uses[call.Fun]
+	var params []*ast.Param
+	switch decl := sym.Decl.(type) {
+	case *ast.Procedure:
+		params = decl.Params
+	case *ast.Function:
+		params = decl.Params
+	}
+	for i, arg := range call.Args {
+		c.value(arg, c.info.TypeRefs[params[i].Type])
+	}
+	c.emit(CALL, c.funcs[sym])
+}
+
+func kindOf(t *Compiler.Type) Kind {
+	switch t.Kind {
+	case Compiler.RealType:
+		return KindReal
+	case Compiler.StringType:
+		return KindString
+	case Compiler.BooleanType:
+		return KindBoolean
+	case Compiler.CharType:
+		return KindChar
+	}
+	return KindInteger
+}
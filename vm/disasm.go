@@ -0,0 +1,63 @@
+package vm
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Disassemble Writes a readable listing of the program: its registers, globals and constants,
+// then the code of every function with the offset and source line of each instruction.
+func Disassemble(w io.Writer, prog *Program) {
+	fmt.Fprintf(w, "program %s\n", prog.Name)
+	for i, rt := range prog.Records {
+		fmt.Fprintf(w, "register %d %s %v\n", i, rt.Name, rt.Fields)
+	}
+	for i, name := range prog.Globals {
+		fmt.Fprintf(w, "global %d %s\n", i, name)
+	}
+	for i, v := range prog.Consts {
+		fmt.Fprintf(w, "const %d %s %s\n", i, prog.ConstKinds[i], constString(v, prog.ConstKinds[i]))
+	}
+	for i, fn := range prog.Functions {
+		fmt.Fprintf(w, "\nfunction %d %s params=%d locals=%d\n", i, fn.Name, fn.NumParams, fn.NumLocals)
+		for pc, in := range fn.Code {
+			fmt.Fprintf(w, "%04d %4d  ", pc, fn.Lines[pc])
+			if !in.Op.hasOperand() {
+				fmt.Fprint(w, in.Op)
+			} else {
+				fmt.Fprintf(w, "%-8s %d", in.Op, in.A)
+				if comment := operandComment(prog, in); comment != "" {
+					fmt.Fprintf(w, "\t; %s", comment)
+				}
+			}
+			fmt.Fprintln(w)
+		}
+	}
+}
+
+func operandComment(prog *Program, in Instr) string {
+	switch in.Op {
+	case CONST:
+		return constString(prog.Consts[in.A], prog.ConstKinds[in.A])
+	case LOADG, STOREG:
+		return prog.Globals[in.A]
+	case NEWREC:
+		return prog.Records[in.A].Name
+	case CALL:
+		return prog.Functions[in.A].Name
+	case PRINT, READ:
+		return Kind(in.A).String()
+	}
+	return ""
+}
+
+func constString(v Value, kind Kind) string {
+	switch kind {
+	case KindReal:
+		return strconv.FormatFloat(v.F, 'g', -1, 64)
+	case KindString:
+		return strconv.Quote(v.S)
+	}
+	return strconv.FormatInt(v.I, 10)
+}
@@ -0,0 +1,156 @@
+// Package vm implements a stack-based bytecode backend: an instruction set,
+// a compiler from the syntax tree to bytecode, a virtual machine and a disassembler.
+package vm
+
+import "fmt"
+
+// Opcode Operation of an instruction. Unless stated otherwise, operands are popped from
+// the stack and the result is pushed back.
+type Opcode uint8
+
+const (
+	HALT    Opcode = iota // stops the machine
+	PUSHI                 // push the integer A
+	CONST                 // push Consts[A]
+	POP                   // discard the top of the stack
+	DUP                   // duplicate the top of the stack
+	LOADL                 // push local A of the current frame
+	STOREL                // pop into local A of the current frame
+	LOADG                 // push global A
+	STOREG                // pop into global A
+	NEWREC                // push a new register of type Records[A], fields set to their zero value
+	COPYREC               // replace the register on top by a copy of it
+	GETF                  // pop a register, push its field A
+	SETF                  // pop a value and a register, set field A of the register to the value
+
+	ADDI // integer arithmetic
+	SUBI
+	MULI
+	DIVI
+	ADDF // real arithmetic
+	SUBF
+	MULF
+	DIVF
+	ITOF // convert the integer on top to real
+	NOT  // boolean negation
+
+	EQI // integer, boolean and char comparisons
+	NEI
+	LTI
+	GTI
+	LEI
+	GEI
+	EQF // real comparisons
+	NEF
+	LTF
+	GTF
+	LEF
+	GEF
+	EQS // string comparisons
+	NES
+
+	JMP     // jump to A
+	JFALSE  // pop a boolean, jump to A if false
+	ANDJMP  // if the boolean on top is false jump to A keeping it, otherwise pop it (&&)
+	ORJMP   // if the boolean on top is true jump to A keeping it, otherwise pop it (||)
+	CALL    // call Functions[A], whose arguments are on top of the stack
+	RET     // return from a procedure
+	RETV    // return from a function, the result is on top of the stack
+	PRINT   // pop a value of kind A and write it
+	PRINTLN // write a line break
+	READ    // read a value of kind A and push it
+)
+
+var opcodes = [...]string{
+	HALT: "HALT", PUSHI: "PUSHI", CONST: "CONST", POP: "POP", DUP: "DUP",
+	LOADL: "LOADL", STOREL: "STOREL", LOADG: "LOADG", STOREG: "STOREG",
+	NEWREC: "NEWREC", COPYREC: "COPYREC", GETF: "GETF", SETF: "SETF",
+	ADDI: "ADDI", SUBI: "SUBI", MULI: "MULI", DIVI: "DIVI",
+	ADDF: "ADDF", SUBF: "SUBF", MULF: "MULF", DIVF: "DIVF", ITOF: "ITOF", NOT: "NOT",
+	EQI: "EQI", NEI: "NEI", LTI: "LTI", GTI: "GTI", LEI: "LEI", GEI: "GEI",
+	EQF: "EQF", NEF: "NEF", LTF: "LTF", GTF: "GTF", LEF: "LEF", GEF: "GEF",
+	EQS: "EQS", NES: "NES",
+	JMP: "JMP", JFALSE: "JFALSE", ANDJMP: "ANDJMP", ORJMP: "ORJMP",
+	CALL: "CALL", RET: "RET", RETV: "RETV",
+	PRINT: "PRINT", PRINTLN: "PRINTLN", READ: "READ",
+}
+
+func (op Opcode) String() string {
+	if int(op) < len(opcodes) && opcodes[op] != "" {
+		return opcodes[op]
+	}
+	return fmt.Sprintf("Opcode(%d)", op)
+}
+
+// hasOperand Reports whether A is meaningful for op.
+func (op Opcode) hasOperand() bool {
+	switch op {
+	case PUSHI, CONST, LOADL, STOREL, LOADG, STOREG, NEWREC, GETF, SETF,
+		JMP, JFALSE, ANDJMP, ORJMP, CALL, PRINT, READ:
+		return true
+	}
+	return false
+}
+
+// Kind Kind of the value handled by PRINT and READ.
+type Kind int32
+
+const (
+	KindInteger Kind = iota
+	KindReal
+	KindString
+	KindBoolean
+	KindChar
+)
+
+var kinds = [...]string{"integer", "real", "string", "boolean", "char"}
+
+func (k Kind) String() string {
+	return kinds[k]
+}
+
+// Instr A single instruction.
+type Instr struct {
+	Op Opcode
+	A  int32
+}
+
+// Value Slot of the stack, of a local or of a global. Booleans (0 or 1) and chars are held in I.
+type Value struct {
+	I int64
+	F float64
+	S string
+	R *Record
+}
+
+// Record Value of a register, its fields in declaration order.
+type Record struct {
+	Fields []Value
+}
+
+// RecordType Layout of a register. Fields are of primitive types, whose zero value is the zero Value.
+type RecordType struct {
+	Name   string
+	Fields []string
+}
+
+// Function Compiled procedure, function or main.
+type Function struct {
+	Name      string
+	NumParams int
+	NumLocals int // parameters included
+	Code      []Instr
+	Lines     []int // source line of every instruction
+}
+
+// Program Compiled program. Execution starts at Functions[Main], whose code begins by
+// initializing the globals.
+type Program struct {
+	Name       string
+	Functions  []*Function
+	Main       int
+	Records    []*RecordType
+	Consts     []Value
+	ConstKinds []Kind
+	Globals    []string // names of the globals, indexed by slot
+}
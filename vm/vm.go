@@ -0,0 +1,273 @@
+package vm
+
+import (
+	"bufio"
+	Compiler "compiladores/Compiler/analyzer"
+	"compiladores/Compiler/interpreter"
+	"fmt"
+	"io"
+)
+
+// RuntimeError Describes an error that stopped the execution, such as a division by zero.
+type RuntimeError struct {
+	Line    int
+	Message string
+}
+
+func (e *RuntimeError) Error() string {
+	return fmt.Sprintf("runtime error at line %d: %s", e.Line, e.Message)
+}
+
+// frame Saved state of a caller.
+type frame struct {
+	fn *Function
+	pc int
+	bp int
+}
+
+// VM Holds the state of a single execution.
+type VM struct {
+	prog    *Program
+	in      *bufio.Scanner
+	out     *bufio.Writer
+	globals []Value
+	stack   []Value
+	calls   []frame
+}
+
+// New Constructor. print writes to out and read consumes whitespace separated words from in.
+func New(prog *Program, in io.Reader, out io.Writer) *VM {
+	scanner := bufio.NewScanner(in)
+	scanner.Split(bufio.ScanWords)
+	return &VM{
+		prog:    prog,
+		in:      scanner,
+		out:     bufio.NewWriter(out),
+		globals: make([]Value, len(prog.Globals)),
+		stack:   make([]Value, 0, 1024),
+	}
+}
+
+// Run Executes the program from the beginning of main.
+func (vm *VM) Run() (err error) {
+	fn := vm.prog.Functions[vm.prog.Main]
+	pc, bp := 0, 0
+	defer func() {
+		if flushErr := vm.out.Flush(); err == nil {
+			err = flushErr
+		}
+	}()
+	fail := func(format string, args ...interface{}) error {
+		return &RuntimeError{fn.Lines[pc-1], fmt.Sprintf(format, args...)}
+	}
+
+	stack := append(vm.stack[:0], make([]Value, fn.NumLocals)...)
+	pop := func() Value {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		return v
+	}
+	push := func(v Value) {
+		stack = append(stack, v)
+	}
+	boolean := func(b bool) Value {
+		if b {
+			return Value{I: 1}
+		}
+		return Value{}
+	}
+
+	for {
+		in := fn.Code[pc]
+		pc++
+		switch in.Op {
+		case HALT:
+			return nil
+		case PUSHI:
+			push(Value{I: int64(in.A)})
+		case CONST:
+			push(vm.prog.Consts[in.A])
+		case POP:
+			pop()
+		case DUP:
+			push(stack[len(stack)-1])
+		case LOADL:
+			push(stack[bp+int(in.A)])
+		case STOREL:
+			stack[bp+int(in.A)] = pop()
+		case LOADG:
+			push(vm.globals[in.A])
+		case STOREG:
+			vm.globals[in.A] = pop()
+
+		case NEWREC:
+			push(Value{R: &Record{Fields: make([]Value, len(vm.prog.Records[in.A].Fields))}})
+		case COPYREC:
+			top := &stack[len(stack)-1]
+			top.R = &Record{Fields: append([]Value(nil), top.R.Fields...)}
+		case GETF:
+			push(pop().R.Fields[in.A])
+		case SETF:
+			v := pop()
+			pop().R.Fields[in.A] = v
+
+		case ADDI, SUBI, MULI, DIVI, EQI, NEI, LTI, GTI, LEI, GEI:
+			b, a := pop().I, pop().I
+			switch in.Op {
+			case ADDI:
+				push(Value{I: a + b})
+			case SUBI:
+				push(Value{I: a - b})
+			case MULI:
+				push(Value{I: a * b})
+			case DIVI:
+				if b == 0 {
+					return fail("integer division by zero")
+				}
+				push(Value{I: a / b})
+			case EQI:
+				push(boolean(a == b))
+			case NEI:
+				push(boolean(a != b))
+			case LTI:
+				push(boolean(a < b))
+			case GTI:
+				push(boolean(a > b))
+			case LEI:
+				push(boolean(a <= b))
+			case GEI:
+				push(boolean(a >= b))
+			}
+		case ADDF, SUBF, MULF, DIVF, EQF, NEF, LTF, GTF, LEF, GEF:
+			b, a := pop().F, pop().F
+			switch in.Op {
+			case ADDF:
+				push(Value{F: a + b})
+			case SUBF:
+				push(Value{F: a - b})
+			case MULF:
+				push(Value{F: a * b})
+			case DIVF:
+				push(Value{F: a / b})
+			case EQF:
+				push(boolean(a == b))
+			case NEF:
+				push(boolean(a != b))
+			case LTF:
+				push(boolean(a < b))
+			case GTF:
+				push(boolean(a > b))
+			case LEF:
+				push(boolean(a <= b))
+			case GEF:
+				push(boolean(a >= b))
+			}
+		case EQS, NES:
+			b, a := pop().S, pop().S
+			push(boolean((a == b) == (in.Op == EQS)))
+		case ITOF:
+			top := &stack[len(stack)-1]
+			*top = Value{F: float64(top.I)}
+		case NOT:
+			top := &stack[len(stack)-1]
+			top.I ^= 1
+
+		case JMP:
+			pc = int(in.A)
+		case JFALSE:
+			if pop().I == 0 {
+				pc = int(in.A)
+			}
+		case ANDJMP, ORJMP:
+			if (stack[len(stack)-1].I != 0) == (in.Op == ORJMP) {
+				pc = int(in.A)
+			} else {
+				pop()
+			}
+		case CALL:
+			callee := vm.prog.Functions[in.A]
+			vm.calls = append(vm.calls, frame{fn, pc, bp})
+			bp = len(stack) - callee.NumParams
+			stack = append(stack, make([]Value, callee.NumLocals-callee.NumParams)...)
+			fn, pc = callee, 0
+		case RET, RETV:
+			var result Value
+			if in.Op == RETV {
+				result = pop()
+			}
+			stack = stack[:bp]
+			if in.Op == RETV {
+				push(result)
+			}
+			caller := vm.calls[len(vm.calls)-1]
+			vm.calls = vm.calls[:len(vm.calls)-1]
+			fn, pc, bp = caller.fn, caller.pc, caller.bp
+
+		case PRINT:
+			vm.out.WriteString(interpreter.Format(external(pop(), Kind(in.A))))
+		case PRINTLN:
+			vm.out.WriteByte('\n')
+		case READ:
+			if err := vm.out.Flush(); err != nil {
+				return err
+			}
+			if !vm.in.Scan() {
+				if err := vm.in.Err(); err != nil {
+					return fail("read: %v", err)
+				}
+				return fail("read: unexpected end of input")
+			}
+			v, err := interpreter.Parse(vm.in.Text(), Compiler.Typ[types[in.A]])
+			if err != nil {
+				return fail("read: %v", err)
+			}
+			push(internal(v))
+
+		default:
+			return fail("invalid opcode %v", in.Op)
+		}
+	}
+}
+
+var types = [...]Compiler.TypeKind{
+	KindInteger: Compiler.IntegerType,
+	KindReal:    Compiler.RealType,
+	KindString:  Compiler.StringType,
+	KindBoolean: Compiler.BooleanType,
+	KindChar:    Compiler.CharType,
+}
+
+// external Converts a primitive value to its interpreter representation.
+func external(v Value, kind Kind) interpreter.Value {
+	switch kind {
+	case KindReal:
+		return v.F
+	case KindString:
+		return v.S
+	case KindBoolean:
+		return v.I != 0
+	case KindChar:
+		return rune(v.I)
+	}
+	return v.I
+}
+
+// internal Converts a primitive value from its interpreter representation.
+func internal(v interpreter.Value) Value {
+	switch v := v.(type) {
+	case int64:
+		return Value{I: v}
+	case float64:
+		return Value{F: v}
+	case string:
+		return Value{S: v}
+	case bool:
+		if v {
+			return Value{I: 1}
+		}
+		return Value{}
+	case rune:
+		return Value{I: int64(v)}
+	}
+	return Value{}
+}
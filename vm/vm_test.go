@@ -0,0 +1,121 @@
+package vm
+
+import (
+	"bytes"
+	Compiler "compiladores/Compiler/analyzer"
+	"compiladores/Compiler/ast"
+	"compiladores/Compiler/interpreter"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files of the tests")
+
+// programs The sample programs run by both engines, with the input they read.
+var programs = []struct {
+	file  string
+	input string
+}{
+	{"../files/sample.txt", "5 2.5 x"},
+	{"../files/operators.txt", "true"},
+	{"../files/registers.txt", "q word name false 1.5"},
+	{"../files/numbers.txt", ""},
+	{"../files/comments.txt", ""},
+}
+
+// check Parses and checks the program, failing the test on any error.
+func check(t *testing.T, name, src string) (*ast.Program, *Compiler.Info) {
+	t.Helper()
+	parser := Compiler.Syntax(Compiler.Lex(name, src))
+	program := parser.Parse()
+	errs := append(parser.LexicalErrors(), parser.Errors()...)
+	analyzer := Compiler.Semantic(program)
+	info := analyzer.Analyze()
+	checker := Compiler.TypeCheck(program, info)
+	checker.Check()
+	errs = append(append(errs, analyzer.Errors()...), checker.Errors()...)
+	if Compiler.HasErrors(errs) {
+		t.Fatalf("%s does not check: %v", name, errs)
+	}
+	return program, info
+}
+
+// TestAgainstInterpreter Runs each sample program on the VM and on the interpreter, which must
+// print the same and stop with the same runtime error, if any.
+func TestAgainstInterpreter(t *testing.T) {
+	for _, p := range programs {
+		src, err := os.ReadFile(p.file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		program, info := check(t, p.file, string(src))
+
+		var want bytes.Buffer
+		wantErr := interpreter.New(program, info, strings.NewReader(p.input), &want).Run()
+		var got bytes.Buffer
+		gotErr := New(Compile(program, info), strings.NewReader(p.input), &got).Run()
+
+		if got.String() != want.String() {
+			t.Errorf("%s: the VM printed\n%s\nthe interpreter\n%s", p.file, got.String(), want.String())
+		}
+		if (gotErr == nil) != (wantErr == nil) || gotErr != nil && gotErr.Error() != wantErr.Error() {
+			t.Errorf("%s: the VM stopped with %v, the interpreter with %v", p.file, gotErr, wantErr)
+		}
+	}
+}
+
+func TestDivisionByZero(t *testing.T) {
+	src := "program P;\nvar { integer i; }\nconst { }\nmain {\n\tvar { }\n\ti = 0;\n\ti = 1 / i;\n}\n"
+	program, info := check(t, "test", src)
+	err := New(Compile(program, info), strings.NewReader(""), &bytes.Buffer{}).Run()
+	if e, ok := err.(*RuntimeError); !ok || e.Line != 7 {
+		t.Errorf("got %v, want a runtime error at line 7", err)
+	}
+}
+
+// TestDisassemble Compares the listing of a small program with testdata/disassemble.golden; run
+// the tests with -update to rewrite it after changing the compiler.
+func TestDisassemble(t *testing.T) {
+	src := `program Listagem;
+var { integer total; }
+const { real MEIO = 0.5; }
+register Ponto { integer x; string nome; }
+function Dobro (integer k) : integer {
+	var { integer r; }
+	r = k * 2;
+	return r;
+}
+main {
+	var { Ponto p; real m; }
+	total = Dobro(21);
+	p.nome = "p";
+	m = total * MEIO;
+	while (total > 0) {
+		total--;
+	}
+	if (m >= 10.5 && total == 0) {
+		print(p.nome, " ", m);
+	}
+	read(p.x);
+}
+`
+	program, info := check(t, "test", src)
+	var b bytes.Buffer
+	Disassemble(&b, Compile(program, info))
+	golden := filepath.Join("testdata", "disassemble.golden")
+	if *update {
+		if err := os.WriteFile(golden, b.Bytes(), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.String() != string(want) {
+		t.Errorf("the listing differs from %s:\n%s", golden, b.String())
+	}
+}